@@ -0,0 +1,174 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"pullreview/internal/bitbucket"
+	"pullreview/internal/report"
+	"pullreview/internal/review"
+)
+
+var (
+	backfillSince  string
+	backfillUntil  string
+	backfillFormat string
+	backfillOutput string
+)
+
+// newBackfillCmd creates the backfill subcommand, which reviews already-merged PRs and
+// writes the aggregated findings to a report without posting anything to Bitbucket.
+func newBackfillCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "backfill [PR_ID...]",
+		Short: "Review merged PRs and write the findings to a CSV/JSON report (never posts)",
+		Long: "backfill runs the AI review on a list of PRs (given as arguments) or on all merged PRs " +
+			"last updated within a date range (--since/--until), and aggregates the findings into a report for " +
+			"retrospective analysis. Nothing is posted to Bitbucket.",
+		RunE: runBackfill,
+	}
+	cmd.Flags().StringVar(&backfillSince, "since", "", "List merged PRs last updated on or after this date (YYYY-MM-DD)")
+	cmd.Flags().StringVar(&backfillUntil, "until", "", "List merged PRs last updated before this date (YYYY-MM-DD)")
+	cmd.Flags().StringVar(&backfillFormat, "format", "json", "Report format: csv or json")
+	cmd.Flags().StringVarP(&backfillOutput, "output", "o", "", "Write the report to this file")
+	_ = cmd.MarkFlagRequired("output")
+	return cmd
+}
+
+func runBackfill(cmd *cobra.Command, args []string) error {
+	if backfillFormat != "csv" && backfillFormat != "json" {
+		return fmt.Errorf("unsupported report format %q (use csv or json)", backfillFormat)
+	}
+	if len(args) == 0 && backfillSince == "" && backfillUntil == "" {
+		return errors.New("provide PR IDs as arguments or a date range with --since/--until")
+	}
+	if err := review.ValidateOutsideDiffPolicy(outsideDiff); err != nil {
+		return err
+	}
+	since, err := parseDateFlag("since", backfillSince)
+	if err != nil {
+		return err
+	}
+	until, err := parseDateFlag("until", backfillUntil)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	if err := applyReviewFlags(cmd, cfg); err != nil {
+		return err
+	}
+	if err := requireBitbucket(cfg, "backfill"); err != nil {
+		return err
+	}
+	ctx := cmd.Context()
+	bbClient, err := newAuthenticatedClient(ctx, cfg)
+	if err != nil {
+		return err
+	}
+
+	prIDs := args
+	titles := make(map[string]string)
+	commits := make(map[string]string) // Source commits, to read each PR's .pullreviewignore
+	if len(prIDs) == 0 {
+		listCtx, cancel := withHostTimeout(ctx)
+		prs, err := bbClient.ListPullRequests(listCtx, "MERGED", since, until)
+		cancel()
+		if err != nil {
+			return fmt.Errorf("failed to list merged PRs: %w", err)
+		}
+		for _, pr := range prs {
+			prIDs = append(prIDs, pr.ID)
+			titles[pr.ID] = pr.Title
+			commits[pr.ID] = pr.SourceCommit
+		}
+		fmt.Fprintf(os.Stderr, "🔎 Found %d merged PR(s) in range\n", len(prIDs))
+	}
+
+	llmClient := newLLMClient(cfg)
+	promptTemplate, err := loadPromptTemplate(cfg)
+	if err != nil {
+		return err
+	}
+
+	rep := &report.Report{}
+	for _, id := range prIDs {
+		fmt.Fprintf(os.Stderr, "📄 Reviewing PR #%s...\n", id)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if _, listed := commits[id]; !listed {
+			prCtx, cancel := withHostTimeout(ctx)
+			pr, err := bbClient.GetPullRequest(prCtx, id)
+			cancel()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "   ❌ Failed to fetch PR #%s: %v\n", id, err)
+				rep.AddFailure(id, err)
+				continue
+			}
+			titles[id] = pr.Title
+			commits[id] = pr.SourceCommit
+		}
+		ignore, err := prIgnoreRules(ctx, bbClient, commits[id])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "   ❌ Failed to read the ignore rules of PR #%s: %v\n", id, err)
+			rep.AddFailure(id, err)
+			continue
+		}
+		diffCtx, cancel := withHostTimeout(ctx)
+		diff, err := bbClient.GetPRDiff(diffCtx, id)
+		cancel()
+		if err != nil && !errors.Is(err, bitbucket.ErrDiffTruncated) {
+			fmt.Fprintf(os.Stderr, "   ❌ Failed to fetch diff for PR #%s: %v\n", id, err)
+			rep.AddFailure(id, err)
+			continue
+		}
+		r, err := reviewDiff(ctx, llmClient, cfg, promptTemplate, id, diff, review.PRInfo{Title: titles[id]}, ignore, nil)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "   ❌ Failed to review PR #%s: %v\n", id, err)
+			rep.AddFailure(id, err)
+			continue
+		}
+		if r == nil {
+			fmt.Fprintf(os.Stderr, "   ⏭️  Skipped PR #%s: diff over oversized_diff_bytes\n", id)
+			rep.AddSkipped(id, titles[id])
+			continue
+		}
+		rep.AddReview(id, titles[id], r.Summary, r.Matched, r.Unmatched)
+		fmt.Fprintf(os.Stderr, "   ✅ %d finding(s)\n", len(r.Matched)+len(r.Unmatched))
+	}
+
+	f, err := os.Create(backfillOutput)
+	if err != nil {
+		return fmt.Errorf("failed to create report file %q: %w", backfillOutput, err)
+	}
+	if err := rep.Write(f, backfillFormat); err != nil {
+		f.Close()
+		return err
+	}
+	// Some filesystems only report write errors on Close
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write report file %q: %w", backfillOutput, err)
+	}
+	fmt.Printf("✅ Wrote %s report for %d PR(s) to %s\n", backfillFormat, len(prIDs), backfillOutput)
+	return nil
+}
+
+// parseDateFlag parses an optional YYYY-MM-DD flag value; an empty value yields the zero time.
+func parseDateFlag(name, value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid --%s date %q (expected YYYY-MM-DD): %w", name, value, err)
+	}
+	return t, nil
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"pullreview/internal/config"
+	"pullreview/internal/llm"
+)
+
+// llmCheckTimeout bounds the optional LLM test call made by `config validate --llm`.
+const llmCheckTimeout = 60 * time.Second
+
+var validateLLM bool
+
+// newConfigCmd creates the config subcommand, which groups configuration helpers.
+func newConfigCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Inspect the pullreview configuration",
+	}
+	validate := &cobra.Command{
+		Use:   "validate",
+		Short: "Check the configuration, Bitbucket credentials, and prompt file",
+		Long: "validate loads the configuration the same way a review does, checks the required " +
+			"settings, the prompt file, and the Bitbucket credentials, and prints a pass/fail " +
+			"checklist. With --llm it also sends a tiny test prompt to the LLM provider.",
+		Args: cobra.NoArgs,
+		RunE: runConfigValidate,
+		// Failed checks are not a usage error
+		SilenceUsage: true,
+	}
+	validate.Flags().BoolVar(&validateLLM, "llm", false, "Also make a small test call to the LLM provider (uses API quota)")
+	cmd.AddCommand(validate)
+	return cmd
+}
+
+func runConfigValidate(cmd *cobra.Command, args []string) error {
+	checks := validateConfig(cmd)
+	checks.Write(os.Stdout)
+	return checks.Err()
+}
+
+// validateConfig runs the configuration checks in order, skipping those that depend on a
+// failed one.
+func validateConfig(cmd *cobra.Command) *config.Checklist {
+	ctx := cmd.Context()
+	checks := &config.Checklist{}
+
+	cfg, err := loadConfig()
+	found := "no config file found, using environment variables and flags"
+	if cfgFile != "" {
+		found = cfgFile
+	}
+	checks.Record("Config file", found, nil)
+	if !checks.Record("Required settings", "", err) {
+		for _, name := range []string{"Review flags", "Prompt file", "Bitbucket credentials", "LLM test call"} {
+			checks.Skip(name, "configuration could not be loaded")
+		}
+		return checks
+	}
+	checks.Record("Review flags", "", applyReviewFlags(cmd, cfg))
+
+	_, err = loadPromptTemplate(cfg)
+	checks.Record("Prompt file", cfg.PromptFile, err)
+
+	switch cfg.Provider {
+	case config.ProviderGitHub:
+		_, err := newGitHubClient(cfg)
+		checks.Record("GitHub settings", cfg.GitHub.Owner+"/"+cfg.GitHub.Repo, err)
+	case config.ProviderGitLab:
+		_, err := newGitLabClient(cfg)
+		checks.Record("GitLab settings", cfg.GitLab.Project, err)
+	default:
+		checks.Record(checkBitbucket(ctx, cfg))
+	}
+
+	if !validateLLM {
+		checks.Skip("LLM test call", "use --llm to send a test prompt")
+		return checks
+	}
+	llmClient := newLLMClient(cfg)
+	checks.Record(checkLLM(ctx, "LLM test call", llmClient))
+	for i, fb := range llmClient.Fallbacks {
+		checks.Record(checkLLM(ctx, fmt.Sprintf("LLM fallback %d test call", i+1), fb))
+	}
+	return checks
+}
+
+// checkBitbucket authenticates with Bitbucket and describes the account on success.
+func checkBitbucket(ctx context.Context, cfg *config.Config) (name, detail string, err error) {
+	name = "Bitbucket credentials"
+	bbClient, err := newBitbucketClient(cfg)
+	if err != nil {
+		return name, "", err
+	}
+	authCtx, cancel := withHostTimeout(ctx)
+	defer cancel()
+	if err := bbClient.Authenticate(authCtx); err != nil {
+		return name, "", err
+	}
+	detail = "workspace " + cfg.Bitbucket.Workspace
+	if account := bbClient.Account(); account != nil {
+		detail = fmt.Sprintf("authenticated as %s, %s", account.DisplayName, detail)
+	}
+	return name, detail, nil
+}
+
+// checkLLM sends a tiny prompt to one LLM provider, bypassing the response cache and any
+// fallbacks so each provider is checked on its own.
+func checkLLM(ctx context.Context, name string, llmClient *llm.Client) (string, string, error) {
+	probe := *llmClient
+	probe.Cache = nil
+	probe.Fallbacks = nil
+	probe.Timeout = llmCheckTimeout
+	resp, err := probe.SendReview(ctx, "Reply with the single word OK.")
+	if err != nil {
+		return name, "", err
+	}
+	if strings.TrimSpace(resp.Content) == "" {
+		return name, "", errors.New("the provider returned an empty response")
+	}
+	return name, fmt.Sprintf("%s model %s answered", resp.Provider, resp.Model), nil
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"pullreview/internal/config"
+	"pullreview/internal/report"
+	"pullreview/internal/review"
+	"pullreview/internal/utils"
+)
+
+// Values of --diff-source.
+const (
+	diffSourceBitbucket = "bitbucket"
+	diffSourceGit       = "git"
+)
+
+// localReview reports whether the diff is reviewed locally (--diff-source=git or --diff-file)
+// rather than fetched from a Bitbucket PR.
+func localReview() bool {
+	return diffSource == diffSourceGit || diffFile != ""
+}
+
+// validateDiffSource checks --diff-source and --diff-file and rejects the flags that need a
+// Bitbucket PR when the diff is reviewed locally.
+func validateDiffSource() error {
+	switch diffSource {
+	case diffSourceBitbucket, diffSourceGit:
+	default:
+		return fmt.Errorf("invalid --diff-source %q (valid: %s, %s)", diffSource, diffSourceBitbucket, diffSourceGit)
+	}
+	if !localReview() {
+		return nil
+	}
+	flag := "--diff-file"
+	if diffSource == diffSourceGit {
+		if diffFile != "" {
+			return errors.New("use either --diff-source=git or --diff-file, not both")
+		}
+		flag = "--diff-source=git"
+	}
+	switch {
+	case len(prIDs) > 0, allOpen:
+		return fmt.Errorf("%s reviews a local diff; it cannot be combined with --pr or --all-open", flag)
+	case postToBB, interactive:
+		return fmt.Errorf("%s only prints the review; it cannot be combined with --post or --interactive", flag)
+	case sinceCommit != "":
+		return fmt.Errorf("--since is not supported with %s", flag)
+	}
+	return nil
+}
+
+// readLocalDiff returns the diff to review locally and a description of where it came from:
+// the --diff-file (or stdin for "-"), or else the commits on HEAD since --base. An empty diff
+// means there is nothing to review.
+func readLocalDiff() (diff, source string, err error) {
+	switch diffFile {
+	case "":
+		repoPath, err := os.Getwd()
+		if err != nil {
+			return "", "", fmt.Errorf("could not determine working directory: %w", err)
+		}
+		diff, err := utils.GetGitDiff(repoPath, gitBase)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to get local diff: %w", err)
+		}
+		return diff, gitBase + "...HEAD", nil
+	case "-":
+		diff, err := review.ReadDiff(os.Stdin)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to read diff from stdin: %w", err)
+		}
+		return diff, "stdin", nil
+	}
+	f, err := os.Open(diffFile)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to open diff file: %w", err)
+	}
+	defer f.Close()
+	diff, err = review.ReadDiff(f)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read diff file %q: %w", diffFile, err)
+	}
+	return diff, diffFile, nil
+}
+
+// runLocalReview reviews a diff from --diff-file, stdin, or the local git repository (see
+// readLocalDiff) and prints the result. Nothing is fetched from or posted to Bitbucket.
+func runLocalReview(ctx context.Context, cfg *config.Config, failThreshold string) error {
+	timings := &report.Timings{}
+	stopFetch := timings.Start(report.PhaseFetchDiff)
+	diff, source, err := readLocalDiff()
+	stopFetch()
+	if err != nil {
+		return err
+	}
+	if diff == "" {
+		fmt.Printf("ℹ️  No commits on HEAD since %s; nothing to review.\n", gitBase)
+		return nil
+	}
+	fmt.Printf("✅ Read diff from %s (length: %d bytes)\n", source, len(diff))
+	if verbose {
+		fmt.Println("------ BEGIN LOCAL DIFF ------")
+		fmt.Println(diff)
+		fmt.Println("------- END LOCAL DIFF -------")
+	}
+
+	llmClient := newLLMClient(cfg)
+	enableStreaming(llmClient)
+	promptTemplate, err := loadPromptTemplate(cfg)
+	if err != nil {
+		return err
+	}
+	r, err := reviewDiff(ctx, llmClient, cfg, promptTemplate, "local", diff, review.PRInfo{}, ignoreRules, timings)
+	if err != nil || r == nil {
+		return err
+	}
+	res := r.Result()
+	printReview(r, res)
+	if summaryOnly {
+		res = res.SummaryOnly(foldInline)
+		if foldInline {
+			fmt.Println("------ Summary with Folded Comments ------")
+			fmt.Println(res.Summary)
+		}
+	}
+	timings.WriteSummary(os.Stdout)
+
+	if reportFile != "" {
+		result := report.NewReviewResult("", r.Summary, r.Matched, r.Unmatched, report.Posting{})
+		result.Timings = timings.Phases
+		if err := writeReviewReport(reportFile, []report.ReviewResult{result}); err != nil {
+			return err
+		}
+	}
+	if issues := review.CountIssues(r.Matched, failThreshold) + review.CountIssues(r.Unmatched, failThreshold); failOnIssues != "" && issues > 0 {
+		return fmt.Errorf("%w: %d comment(s) (--fail-on-issues=%s)", errIssuesFound, issues, failOnIssues)
+	}
+	return nil
+}
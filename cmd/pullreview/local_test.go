@@ -0,0 +1,60 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDiffFileWithoutBitbucketConfig(t *testing.T) {
+	for _, k := range []string{"BITBUCKET_EMAIL", "BITBUCKET_API_TOKEN", "BITBUCKET_WORKSPACE", "BITBUCKET_REPO_SLUG",
+		"PULLREVIEW_PROVIDER", "LLM_PROVIDER", "LLM_API_KEY", "LLM_ENDPOINT", "PULLREVIEW_PROMPT_FILE"} {
+		t.Setenv(k, "")
+	}
+
+	var prompt string
+	llmServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Messages []struct {
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		body, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(body, &req)
+		if len(req.Messages) > 0 {
+			prompt = req.Messages[len(req.Messages)-1].Content
+		}
+		_, _ = w.Write([]byte(`{"message": {"role": "assistant", "content": "******************** SECTION: SUMMARY ********************\nLooks fine.\n"}}`))
+	}))
+	defer llmServer.Close()
+
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+		return path
+	}
+	promptFile := write("prompt.md", "Review this:\n(DIFF_CONTENT_HERE)")
+	cfg := write("pullreview.yaml", "llm:\n  provider: ollama\n  endpoint: "+llmServer.URL+"\nprompt_file: "+promptFile+"\n")
+	diff := write("change.diff", "diff --git a/main.go b/main.go\n--- a/main.go\n+++ b/main.go\n@@ -1,1 +1,2 @@\n package main\n+var x = 1\n")
+	reportPath := filepath.Join(dir, "report.json")
+
+	cmd := newRootCmd()
+	cmd.SetArgs([]string{"--config", cfg, "--diff-file", diff, "--report-file", reportPath})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("--diff-file review without Bitbucket config failed: %v", err)
+	}
+	if !strings.Contains(prompt, "+var x = 1") {
+		t.Errorf("expected the diff in the LLM prompt, got %q", prompt)
+	}
+	if data, err := os.ReadFile(reportPath); err != nil || !strings.Contains(string(data), "Looks fine.") {
+		t.Errorf("expected the review summary in the report, got %q (err %v)", data, err)
+	}
+}
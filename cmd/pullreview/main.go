@@ -1,45 +1,94 @@
 package main
 
 import (
-	"encoding/json"
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"pullreview/internal/bitbucket"
 	"pullreview/internal/config"
 	"pullreview/internal/llm"
+	"pullreview/internal/logging"
+	"pullreview/internal/report"
 	"pullreview/internal/review"
 	"pullreview/internal/utils"
 )
 
 var (
-	cfgFile     string
-	prID        string
-	bbEmail     string
-	bbAPIToken  string
-	repoSlug    string
-	showVersion bool
-	verbose     bool
-	postToBB    bool
-	skipInline  bool
-	version     = "0.1.0"
+	cfgFile         string
+	prIDs           []string
+	allOpen         bool
+	reportFile      string
+	failOnIssues    string
+	bbEmail         string
+	bbAPIToken      string
+	repoSlug        string
+	showVersion     bool
+	verbose         bool
+	postToBB        bool
+	skipInline      bool
+	diffSource      string
+	gitBase         string
+	diffFile        string
+	summaryOnly     bool
+	foldInline      bool
+	interactive     bool
+	categories      []string
+	includePaths    []string
+	sinceCommit     string
+	logFormat       string
+	excludePaths    []string
+	ignoreRules     *review.IgnoreRules // from the local .pullreviewignore (see localIgnoreFile), loaded by applyReviewFlags
+	minSeverity     string
+	maxInline       int
+	lineTolerance   int
+	outsideDiff     string
+	postConcurrency int
+	useLock         bool
+	skipApproved    bool
+	lockTTL         time.Duration
+	streamLLM       bool
+	noCache         bool
+	version         = "0.1.0"
 )
 
+// errIssuesFound is returned when --fail-on-issues is set and the review found issues; the
+// process then exits with code 2 instead of 1.
+var errIssuesFound = errors.New("review found issues")
+
+// hostTimeout bounds each code host (Bitbucket, GitHub or GitLab) API call so a hung request
+// cannot block a run forever.
+const hostTimeout = 60 * time.Second
+
 func main() {
-	// Try to find config file next to the binary (optional)
-	defaultConfig := ""
-	if exePath, err := os.Executable(); err == nil {
-		exeDir := filepath.Dir(exePath)
-		configPath := filepath.Join(exeDir, "pullreview.yaml")
-		if _, err := os.Stat(configPath); err == nil {
-			defaultConfig = configPath
+	rootCmd := newRootCmd()
+
+	cobra.OnInitialize(initConfig)
+
+	// Cancel in-flight requests on Ctrl-C
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := rootCmd.ExecuteContext(ctx); err != nil {
+		stop()
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		if errors.Is(err, errIssuesFound) {
+			os.Exit(2)
 		}
+		os.Exit(1)
 	}
+}
 
+// newRootCmd builds the pullreview command with its flags and subcommands.
+func newRootCmd() *cobra.Command {
 	rootCmd := &cobra.Command{
 		Use:   "pullreview",
 		Short: "Automated code review for Bitbucket Cloud PRs using LLMs",
@@ -47,22 +96,45 @@ func main() {
 		RunE:  runPullReview,
 	}
 
-	rootCmd.Flags().StringVarP(&cfgFile, "config", "c", defaultConfig, "Path to config file (optional, auto-detected or use env vars)")
-	rootCmd.Flags().StringVar(&prID, "pr", "", "Bitbucket Pull Request ID (overrides branch inference)")
-	rootCmd.Flags().StringVar(&bbEmail, "email", "", "Bitbucket account email (overrides config/env)")
-	rootCmd.Flags().StringVar(&bbAPIToken, "token", "", "Bitbucket API token (overrides config/env)")
-	rootCmd.Flags().StringVar(&repoSlug, "repo", "", "Bitbucket repository slug (overrides config/env)")
+	rootCmd.PersistentFlags().StringVarP(&cfgFile, "config", "c", "", "Path to config file (default: ./pullreview.yaml, then $XDG_CONFIG_HOME/pullreview/config.yaml, then next to the executable)")
+	rootCmd.PersistentFlags().StringVar(&bbEmail, "email", "", "Bitbucket account email (overrides config/env)")
+	rootCmd.PersistentFlags().StringVar(&bbAPIToken, "token", "", "Bitbucket API token (overrides config/env)")
+	rootCmd.PersistentFlags().StringVar(&repoSlug, "repo", "", "Bitbucket repository slug (overrides config/env)")
+	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Enable verbose output")
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", logging.FormatConsole, "Format of LLM progress and debug messages: console, or json for one JSON object per line on stderr")
+	rootCmd.PersistentFlags().StringSliceVar(&categories, "category", nil, "Only keep findings in these categories (e.g. security,bug); repeatable")
+	rootCmd.PersistentFlags().StringSliceVar(&includePaths, "include", nil, "Only review files matching these globs (e.g. 'internal/**'); repeatable")
+	rootCmd.PersistentFlags().StringSliceVar(&excludePaths, "exclude", nil, "Skip files matching these globs (e.g. 'vendor/**,*.pb.go'); repeatable")
+	rootCmd.PersistentFlags().StringVar(&minSeverity, "min-severity", "", "Only keep findings at or above this severity: critical, high, medium, low, or info (overrides config/env)")
+	rootCmd.PersistentFlags().IntVar(&maxInline, "max-inline-comments", 0, "Post at most this many inline comments, most severe first; the rest go into the summary (overrides config/env)")
+	rootCmd.PersistentFlags().IntVar(&lineTolerance, "line-tolerance", 0, "Snap inline comments up to this many lines to the nearest added line instead of moving them to the summary (overrides config/env)")
+	rootCmd.PersistentFlags().BoolVar(&noCache, "no-cache", false, "Always call the LLM, ignoring llm.cache_dir")
+	rootCmd.PersistentFlags().StringVar(&outsideDiff, "outside-diff", review.OutsideDiffSummary, "Handling of comments on files not in the diff: drop, summary, or verify (post as file-level if the file exists in the repo)")
+	rootCmd.Flags().StringSliceVar(&prIDs, "pr", nil, "Bitbucket Pull Request ID (overrides branch inference); repeatable to review several PRs")
+	rootCmd.Flags().StringVar(&sinceCommit, "since", "", "Only review changes pushed after this commit, or after the last reviewed commit with 'last' (Bitbucket Cloud)")
+	rootCmd.Flags().StringVar(&diffSource, "diff-source", diffSourceBitbucket, "Where to get the diff: bitbucket (the PR diff), or git to review 'git diff <base>...HEAD' locally without a PR; git never posts")
+	rootCmd.Flags().StringVar(&diffFile, "diff-file", "", "Review the unified diff in this file ('-' for stdin) instead of a Bitbucket PR; never posts")
+	rootCmd.Flags().StringVar(&gitBase, "base", "main", "Base branch or commit for --diff-source=git")
+	rootCmd.Flags().BoolVar(&allOpen, "all-open", false, "Review every open PR in the repository, one after another")
+	rootCmd.Flags().StringVar(&failOnIssues, "fail-on-issues", "", "Exit with code 2 if the review finds issues; optionally only those at or above a severity (e.g. --fail-on-issues=high)")
+	rootCmd.Flags().Lookup("fail-on-issues").NoOptDefVal = "any"
+	rootCmd.Flags().StringVar(&reportFile, "report-file", "", "Also write the review results (comments, summary, posting outcome) as JSON to this file")
 	rootCmd.Flags().BoolVar(&showVersion, "version", false, "Show version and exit")
-	rootCmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Enable verbose output")
 	rootCmd.Flags().BoolVar(&postToBB, "post", false, "Post comments to Bitbucket (default: false, just print comments)")
 	rootCmd.Flags().BoolVar(&skipInline, "skip-inline", false, "Skip interactive prompt (non-interactive mode)")
+	rootCmd.Flags().BoolVar(&summaryOnly, "summary-only", false, "Post only the summary comment, no inline or file-level comments")
+	rootCmd.Flags().BoolVar(&foldInline, "fold-inline", false, "With --summary-only, add the inline and file-level findings to the summary as bullet points")
+	rootCmd.Flags().BoolVar(&interactive, "interactive", false, "Ask before posting each comment and the summary, and post only the approved ones")
+	rootCmd.Flags().BoolVar(&skipApproved, "skip-approved", false, "Skip the review if the PR already has an approval")
+	rootCmd.Flags().BoolVar(&useLock, "lock", false, "Hold a per-PR lock (a marked PR comment) for the run so concurrent runs on the same PR bail out")
+	rootCmd.Flags().DurationVar(&lockTTL, "lock-ttl", bitbucket.DefaultLockTTL, "Age after which another run's lock is considered abandoned")
+	rootCmd.Flags().BoolVar(&streamLLM, "stream", false, "Stream the LLM response to the terminal as it is generated (OpenAI-compatible providers)")
+	rootCmd.Flags().IntVar(&postConcurrency, "post-concurrency", bitbucket.DefaultPostConcurrency, "Number of inline comments to post in parallel")
 
-	cobra.OnInitialize(initConfig)
-
-	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
-	}
+	rootCmd.AddCommand(newBackfillCmd())
+	rootCmd.AddCommand(newServeCmd())
+	rootCmd.AddCommand(newConfigCmd())
+	return rootCmd
 }
 
 func initConfig() {
@@ -79,96 +151,304 @@ func runPullReview(cmd *cobra.Command, args []string) error {
 
 	}
 
-	// Load configuration with overrides from CLI flags
+	if err := review.ValidateOutsideDiffPolicy(outsideDiff); err != nil {
+		return err
+	}
+	failThreshold := failOnIssues
+	if failThreshold == "any" {
+		failThreshold = ""
+	}
+	if err := review.ValidateSeverity(failThreshold); err != nil {
+		return fmt.Errorf("invalid --fail-on-issues threshold: %w", err)
+	}
+	if foldInline && !summaryOnly {
+		return errors.New("--fold-inline requires --summary-only")
+	}
+	if interactive && skipInline {
+		return errors.New("--interactive cannot be combined with --skip-inline")
+	}
 
-	cfg, err := config.LoadConfigWithOverrides(cfgFile, bbEmail, bbAPIToken, repoSlug)
+	if err := validateDiffSource(); err != nil {
+		return err
+	}
 
+	// Load configuration with overrides from CLI flags
+	cfg, err := loadConfig()
 	if err != nil {
+		return err
+	}
+	if err := applyReviewFlags(cmd, cfg); err != nil {
+		return err
+	}
+	if localReview() {
+		return runLocalReview(cmd.Context(), cfg, failThreshold)
+	}
 
-		return fmt.Errorf("failed to load config: %w", err)
-
+	ctx := cmd.Context()
+	if cfg.Provider != config.ProviderBitbucket {
+		return runProviderReview(ctx, cfg, failThreshold)
 	}
 
 	// Initialize Bitbucket client and attempt authentication
+	bbClient, err := newAuthenticatedClient(ctx, cfg)
+	if err != nil {
+		return err
+	}
 
-	bbClient := bitbucket.NewClient(
-		cfg.Bitbucket.Email,
-		cfg.Bitbucket.APIToken,
-		cfg.Bitbucket.Workspace,
-		cfg.Bitbucket.RepoSlug,
-		cfg.Bitbucket.BaseURL,
-	)
-
-	if err := bbClient.Authenticate(); err != nil {
-
-		fmt.Fprintf(os.Stderr, "❌ Bitbucket login failed: %v\n", err)
-
-		if cfg.Bitbucket.APIToken == "" {
+	ids, titles, err := resolvePRIDs(ctx, bbClient)
+	if err != nil {
+		return err
+	}
 
-			fmt.Fprintln(os.Stderr, "  - Missing Bitbucket API token (set in config, env, or CLI flag)")
+	llmClient := newLLMClient(cfg)
+	enableStreaming(llmClient)
+	promptTemplate, err := loadPromptTemplate(cfg)
+	if err != nil {
+		return err
+	}
+	return reviewAll(ctx, ids, titles, failThreshold, report.NewHistory(cfg.HistoryFile), func(id string, timings *report.Timings) (*review.Review, report.Posting, error) {
+		return reviewPR(ctx, cfg, bbClient, llmClient, promptTemplate, id, timings)
+	})
+}
 
+// reviewAll reviews each PR in turn with reviewFn, records each run in history, writes the
+// --report-file, and applies --fail-on-issues. With several PRs it carries on past failures
+// and ends with a roll-up; reviewFn returns a nil review for a skipped PR.
+func reviewAll(ctx context.Context, ids []string, titles map[string]string, failThreshold string, history *report.History,
+	reviewFn func(id string, timings *report.Timings) (*review.Review, report.Posting, error)) error {
+	// In batch mode each PR is reviewed in turn, carrying on past failures, followed by a roll-up
+	batch := len(ids) > 1
+	rep := &report.Report{}
+	var results []report.ReviewResult
+	var entries []report.HistoryEntry
+	var reviewErr error
+	issues := 0
+	for i, id := range ids {
+		if ctx.Err() != nil {
+			reviewErr = ctx.Err()
+			break
 		}
-
-		if cfg.Bitbucket.Workspace == "" {
-
-			fmt.Fprintln(os.Stderr, "  - Missing Bitbucket workspace (set in config, env, or CLI flag)")
-
+		if batch {
+			fmt.Printf("\n===== PR #%s (%d of %d) =====\n", id, i+1, len(ids))
+		}
+		timings := &report.Timings{}
+		r, posting, err := reviewFn(id, timings)
+		if err == nil {
+			timings.WriteSummary(os.Stdout)
 		}
+		switch {
+		case err != nil:
+			if !batch {
+				reviewErr = err
+			} else {
+				fmt.Fprintf(os.Stderr, "❌ Failed to review PR #%s: %v\n", id, err)
+			}
+			rep.AddFailure(id, err)
+		case r == nil:
+			rep.AddSkipped(id, titles[id])
+		default:
+			rep.AddReview(id, titles[id], r.Summary, r.Matched, r.Unmatched)
+			issues += review.CountIssues(r.Matched, failThreshold) + review.CountIssues(r.Unmatched, failThreshold)
+		}
+		results = append(results, newReviewResult(id, r, posting, err, timings))
+		entries = append(entries, report.NewHistoryEntry(results[len(results)-1], time.Now()))
+	}
 
-		return fmt.Errorf("could not authenticate with Bitbucket")
+	if err := history.Append(entries...); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to record review history: %v\n", err)
+	}
 
+	if reportFile != "" {
+		if err := writeReviewReport(reportFile, results); err != nil {
+			return err
+		}
+	}
+	if reviewErr != nil {
+		return reviewErr
 	}
+	if batch {
+		fmt.Println("\n------ Batch Summary ------")
+		if err := rep.WriteSummary(os.Stdout); err != nil {
+			return err
+		}
+		if failed := rep.Totals().PRsFailed; failed > 0 {
+			return fmt.Errorf("%d of %d PR(s) could not be reviewed", failed, len(ids))
+		}
+	}
+	if failOnIssues != "" && issues > 0 {
+		return fmt.Errorf("%w: %d comment(s) (--fail-on-issues=%s)", errIssuesFound, issues, failOnIssues)
+	}
+	return nil
+}
 
-	fmt.Printf("✅ Successfully authenticated with Bitbucket (workspace: %s)\n", cfg.Bitbucket.Workspace)
+// writeReviewReport writes the JSON review results to path.
+func writeReviewReport(path string, results []report.ReviewResult) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create report file %q: %w", path, err)
+	}
+	if err := report.WriteReviewResults(f, results); err != nil {
+		f.Close()
+		return err
+	}
+	// Some filesystems only report write errors on Close
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write report file %q: %w", path, err)
+	}
+	fmt.Printf("📝 Wrote review results to %s\n", path)
+	return nil
+}
 
-	// Determine PR ID: use CLI flag if provided, else infer from git branch
-	finalPRID := prID
-	if finalPRID == "" {
-		// Try to infer from git branch
-		repoPath, err := os.Getwd()
+// resolvePRIDs returns the PRs to review: every open PR with --all-open, the --pr IDs if given,
+// or else the open PR for the current git branch. Titles are known only for --all-open.
+func resolvePRIDs(ctx context.Context, bbClient *bitbucket.Client) ([]string, map[string]string, error) {
+	titles := make(map[string]string)
+	switch {
+	case allOpen && len(prIDs) > 0:
+		return nil, nil, errors.New("use either --pr or --all-open, not both")
+	case allOpen:
+		callCtx, cancel := withHostTimeout(ctx)
+		prs, err := bbClient.ListOpenPullRequests(callCtx)
+		cancel()
 		if err != nil {
-			return fmt.Errorf("could not determine working directory: %w", err)
+			printBitbucketHint(err)
+			return nil, nil, fmt.Errorf("failed to list open PRs: %w", err)
 		}
-		branch, err := utils.GetCurrentGitBranch(repoPath)
-		if err != nil {
-			return fmt.Errorf("could not infer git branch: %w", err)
+		if len(prs) == 0 {
+			return nil, nil, errors.New("no open PRs found")
 		}
-		fmt.Printf("🔎 Inferred branch: %s\n", branch)
-		finalPRID, err = bbClient.GetPRIDByBranch(branch)
-		if err != nil {
-			return fmt.Errorf("could not find open PR for branch %q: %w", branch, err)
+		var ids []string
+		for _, pr := range prs {
+			ids = append(ids, pr.ID)
+			titles[pr.ID] = pr.Title
+		}
+		fmt.Printf("🔎 Found %d open PR(s)\n", len(ids))
+		return ids, titles, nil
+	case len(prIDs) > 0:
+		for _, id := range prIDs {
+			fmt.Printf("ℹ️ Using provided PR ID: %s\n", id)
+		}
+		return prIDs, titles, nil
+	}
+
+	// Infer from git branch
+	repoPath, err := os.Getwd()
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not determine working directory: %w", err)
+	}
+	branch, err := utils.GetCurrentGitBranch(repoPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not infer git branch: %w", err)
+	}
+	fmt.Printf("🔎 Inferred branch: %s\n", branch)
+	callCtx, cancel := withHostTimeout(ctx)
+	id, err := bbClient.GetPRIDByBranch(callCtx, branch)
+	cancel()
+	if err != nil {
+		printBitbucketHint(err)
+		return nil, nil, fmt.Errorf("could not find open PR for branch %q: %w", branch, err)
+	}
+	fmt.Printf("🔎 Inferred PR ID: %s\n", id)
+	return []string{id}, titles, nil
+}
 
+// newReviewResult builds the result of one PR review as returned by reviewPR: a failure, a skip
+// (nil review), or the review and what was posted.
+func newReviewResult(id string, r *review.Review, posting report.Posting, err error, timings *report.Timings) report.ReviewResult {
+	switch {
+	case err != nil:
+		return report.ReviewResult{PRID: id, Error: err.Error()}
+	case r == nil:
+		return report.ReviewResult{PRID: id, Skipped: true}
+	}
+	result := report.NewReviewResult(id, r.Summary, r.Matched, r.Unmatched, posting)
+	result.Timings = timings.Phases
+	result.Tokens = r.Tokens
+	return result
+}
+
+// reviewPR reviews a single PR: it fetches the diff, asks the LLM for a review, prints it and,
+// if confirmed, posts it. It returns the review, or nil if the PR was skipped, and what was posted.
+func reviewPR(ctx context.Context, cfg *config.Config, bbClient *bitbucket.Client, llmClient *llm.Client, promptTemplate, finalPRID string, timings *report.Timings) (*review.Review, report.Posting, error) {
+	if useLock {
+		release, err := acquirePRLock(ctx, bbClient, finalPRID)
+		if err != nil {
+			return nil, report.Posting{}, err
 		}
-		fmt.Printf("🔎 Inferred PR ID: %s\n", finalPRID)
-	} else {
-		fmt.Printf("ℹ️ Using provided PR ID: %s\n", finalPRID)
+		defer release()
 	}
 
 	// Fetch PR metadata
-	prMetaBytes, err := bbClient.GetPRMetadata(finalPRID)
+	stopFetch := timings.Start(report.PhaseFetchPR)
+	callCtx, cancel := withHostTimeout(ctx)
+	pr, err := bbClient.GetPullRequest(callCtx, finalPRID)
+	cancel()
 	if err != nil {
-		return fmt.Errorf("failed to fetch PR metadata: %w", err)
+		printBitbucketHint(err)
+		return nil, report.Posting{}, fmt.Errorf("failed to fetch PR metadata: %w", err)
 	}
 	fmt.Printf("✅ Fetched PR metadata for PR #%s\n", finalPRID)
+	fmt.Printf("🔖 PR Title: %s\n", pr.Title)
+	fmt.Printf("📝 PR Description: %s\n", pr.Description)
 
-	// Parse and print PR title and description
-	type prMetaStruct struct {
-		Title       string `json:"title"`
-		Description string `json:"description"`
+	if approvers := bitbucket.Approvers(pr.Participants); len(approvers) > 0 {
+		var names []string
+		for _, p := range approvers {
+			names = append(names, p.User.DisplayName)
+		}
+		fmt.Printf("👍 Approved by: %s\n", strings.Join(names, ", "))
+		if skipApproved {
+			stopFetch()
+			fmt.Println("ℹ️  PR is already approved; skipping review (--skip-approved).")
+			return nil, report.Posting{}, nil
+		}
 	}
-	var prMeta prMetaStruct
-	if err := json.Unmarshal(prMetaBytes, &prMeta); err != nil {
-		fmt.Fprintf(os.Stderr, "Warning: could not parse PR metadata JSON: %v\n", err)
+
+	ignore, err := prIgnoreRules(ctx, bbClient, pr.SourceCommit)
+	if err != nil {
+		return nil, report.Posting{}, err
+	}
+
+	// Fetch the changed-file list first; this is cheap even for very large PRs, and lets a PR
+	// that is clearly over oversized_diff_bytes be skipped without downloading its diff
+	callCtx, cancel = withHostTimeout(ctx)
+	diffstat, err := bbClient.GetPRDiffstat(callCtx, finalPRID)
+	cancel()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not fetch PR diffstat: %v\n", err)
 	} else {
-		fmt.Printf("🔖 PR Title: %s\n", prMeta.Title)
-		fmt.Printf("📝 PR Description: %s\n", prMeta.Description)
+		linesAdded, linesRemoved, reviewed := 0, 0, 0
+		pf := pathFilter(ignore)
+		for _, entry := range diffstat {
+			linesAdded += entry.LinesAdded
+			linesRemoved += entry.LinesRemoved
+			if pf.Match(entry.Path()) {
+				reviewed += entry.LinesAdded + entry.LinesRemoved
+			}
+		}
+		fmt.Printf("📊 PR changes %d file(s) (+%d/-%d lines)\n", len(diffstat), linesAdded, linesRemoved)
+		// With --since only part of the PR is reviewed, which the diffstat cannot tell
+		if estimate, skip := review.SkipByEstimate(reviewed, cfg.OversizedDiffBytes, cfg.OversizedDiff); skip && sinceCommit == "" {
+			stopFetch()
+			fmt.Printf("⏭️  Skipping review: the diff is an estimated %d bytes, over oversized_diff_bytes (%d).\n", estimate, cfg.OversizedDiffBytes)
+			fmt.Printf("   Narrow it with --include/--exclude or %s, raise oversized_diff_bytes, or set oversized_diff to chunk or truncate.\n", review.IgnoreFileName)
+			return nil, report.Posting{}, nil
+		}
 	}
 
+	stopFetch()
+
 	// Fetch PR diff
-	diff, err := bbClient.GetPRDiff(finalPRID)
-	if err != nil {
-		return fmt.Errorf("failed to fetch PR diff: %w", err)
+	stopFetch = timings.Start(report.PhaseFetchDiff)
+	callCtx, cancel = withHostTimeout(ctx)
+	diff, err := bbClient.GetPRDiff(callCtx, finalPRID)
+	cancel()
+	if errors.Is(err, bitbucket.ErrDiffTruncated) {
+		fmt.Fprintf(os.Stderr, "⚠️  %v; reviewing the partial diff only\n", err)
+	} else if err != nil {
+		return nil, report.Posting{}, fmt.Errorf("failed to fetch PR diff: %w", err)
 	}
+	stopFetch()
 	fmt.Printf("✅ Fetched PR diff for PR #%s (length: %d bytes)\n", finalPRID, len(diff))
 
 	if verbose {
@@ -177,146 +457,704 @@ func runPullReview(cmd *cobra.Command, args []string) error {
 		fmt.Println("------- END PR DIFF -------")
 	}
 
-	// Initialize LLM client
-	llm.SetVerbose(verbose)
-	llmClient := llm.NewClient(cfg.LLM.Provider, cfg.LLM.APIKey, cfg.LLM.Endpoint)
-	llmClient.Model = cfg.LLM.Model
+	// Earlier comments are reconciled against the full PR diff, since the review may cover only
+	// part of it (--since, --include/--exclude, or oversized_diff: truncate)
+	prFiles, _ := review.ParseUnifiedDiff(diff)
 
-	// Resolve prompt file path relative to config file location if not absolute
-	promptPath := cfg.PromptFile
-	if !filepath.IsAbs(promptPath) && cfgFile != "" {
-		cfgDir := filepath.Dir(cfgFile)
-		promptPath = filepath.Join(cfgDir, promptPath)
+	// In incremental mode, review only the PR hunks touched since the base commit
+	if sinceCommit != "" {
+		stopFetch = timings.Start(report.PhaseFetchDiff)
+		narrowed, skip, err := incrementalDiff(ctx, bbClient, finalPRID, pr.SourceCommit, diff)
+		stopFetch()
+		if err != nil {
+			return nil, report.Posting{}, err
+		}
+		if skip {
+			return nil, report.Posting{}, nil
+		}
+		diff = narrowed
 	}
 
-	// Load prompt template
-	promptBytes, err := os.ReadFile(promptPath)
+	r, err := reviewDiff(ctx, llmClient, cfg, promptTemplate, finalPRID, diff, review.PRInfo{Title: pr.Title, Description: pr.Description}, ignore, timings)
+	if err != nil || r == nil {
+		return nil, report.Posting{}, err
+	}
+	res := r.Result()
+	printReview(r, res)
+	if summaryOnly {
+		res = res.SummaryOnly(foldInline)
+		if foldInline && len(r.Matched) > 0 {
+			fmt.Printf("📝 Folded %d comment(s) into the summary (--fold-inline)\n", len(r.Matched))
+		}
+	}
+
+	res, shouldPost, err := approveReview("Bitbucket", res)
 	if err != nil {
-		return fmt.Errorf("failed to read prompt file %q: %w", promptPath, err)
+		return nil, report.Posting{}, err
+	}
+	if !shouldPost {
+		fmt.Println("ℹ️  Review not posted to Bitbucket.")
+		return r, report.Posting{}, nil
 	}
-	promptTemplate := string(promptBytes)
 
-	// Validate prompt is not empty
-	if strings.TrimSpace(promptTemplate) == "" {
-		return fmt.Errorf("prompt file %q is empty - cannot proceed without a valid prompt template", promptPath)
+	// Bitbucket posting output section
+	fmt.Println("\n📤 Posting review to Bitbucket...")
+	defer timings.Start(report.PhasePost)()
+
+	// Reconcile with comments posted by earlier runs, then post inline and file-level comments
+	// (only matched). In summary-only mode earlier comments are left as they are.
+	posted := loadPostedComments(ctx, bbClient, finalPRID)
+	inlineCount := 0
+	if !summaryOnly {
+		if len(prFiles) == 0 {
+			prFiles = r.Files
+		}
+		comments := r.Matched
+		if interactive {
+			comments = res.Comments()
+		}
+		// Bitbucket has no suggestion support; show suggested code as a labelled block
+		toPost := make([]review.Comment, len(comments))
+		for i, cmt := range comments {
+			cmt.Text = cmt.PlainSuggestionText()
+			toPost[i] = cmt
+		}
+		steps := review.Reconcile(toPost, prFiles, pr.SourceCommit, posted)
+		inlineCount = applyReconcileSteps(ctx, bbClient, finalPRID, steps)
 	}
 
-	// Inject diff into prompt
-	finalPrompt := strings.Replace(promptTemplate, "(DIFF_CONTENT_HERE)", diff, 1)
+	// Post summary comment (with unmatched comments as bullet points), replacing the one an
+	// earlier run posted. Its marker records the reviewed commit for --since last, so it is
+	// posted even when there is nothing to summarize, unless the user declined it.
+	summary := res.Summary
+	if summary == "" && !interactive {
+		summary = fmt.Sprintf("No further findings as of commit %s.", pr.SourceCommit)
+	}
+	summaryPosted := false
+	if summary != "" {
+		summaryPosted = postSummary(ctx, bbClient, finalPRID, summary, pr.SourceCommit, review.FindSummary(posted))
+	}
 
-	// Send prompt to LLM
-	fmt.Println("🤖 Sending review prompt to LLM...")
-	llmResp, err := llmClient.SendReviewPrompt(finalPrompt)
+	fmt.Printf("\n✅ Successfully posted %d inline comment(s)%s to PR #%s\n", inlineCount,
+		func() string {
+			if summaryPosted {
+				return " and summary"
+			}
+			return ""
+		}(), finalPRID)
+
+	return r, report.Posting{Posted: true, InlineComments: inlineCount, SummaryPosted: summaryPosted}, nil
+}
+
+// confirmPost decides whether to post the review to host: with --skip-inline as set by --post,
+// otherwise by asking the user.
+func confirmPost(host string) (bool, error) {
+	if skipInline {
+		return postToBB, nil
+	}
+	// Interactive mode: prompt user
+	confirmed, err := utils.PromptYesNo(fmt.Sprintf("Should I post this review to %s?", host), "n")
 	if err != nil {
-		return fmt.Errorf("failed to get response from LLM: %w", err)
+		return false, fmt.Errorf("failed to read user input: %w", err)
 	}
+	return confirmed, nil
+}
 
-	// Parse LLM response and print summary and inline comments
-	r := review.NewReview(finalPRID, diff)
-	if err := r.ParseDiff(); err != nil {
-		fmt.Fprintf(os.Stderr, "Warning: failed to parse diff for comment mapping: %v\n", err)
+// approveReview decides what to post to host: with --interactive the user approves each
+// comment and the summary, which returns only the approved parts; otherwise the whole review
+// is confirmed with confirmPost.
+func approveReview(host string, res review.Result) (review.Result, bool, error) {
+	if !interactive {
+		ok, err := confirmPost(host)
+		return res, ok, err
 	}
-	r.ParseLLMResponse(llmResp)
-
-	// Filter comments: only keep those that match the diff, and report unmatched
-	matched, unmatched := review.MatchCommentsToDiff(r.Comments, r.Files)
+	approved, err := res.Approve(os.Stdin, os.Stdout)
+	if err != nil {
+		return review.Result{}, false, fmt.Errorf("failed to read user input: %w", err)
+	}
+	ok := approved.Summary != "" || len(approved.FileLevel)+len(approved.Inline) > 0
+	return approved, ok, nil
+}
 
-	// Compose summary with unmatched comments as bullet points (no heading)
-	summaryWithUnmatched := r.Summary
-	if len(unmatched) > 0 {
-		var b strings.Builder
-		if summaryWithUnmatched != "" {
-			b.WriteString(summaryWithUnmatched)
-			b.WriteString("\n\n")
-		}
-		for _, cmt := range unmatched {
-			if cmt.IsFileLevel {
-				b.WriteString(fmt.Sprintf("- [%s] %s\n", cmt.FilePath, cmt.Text))
-			} else {
-				b.WriteString(fmt.Sprintf("- [%s:%d] %s\n", cmt.FilePath, cmt.Line, cmt.Text))
-			}
-		}
-		summaryWithUnmatched = b.String()
+// enableStreaming makes the client and its fallbacks print the response as it is generated
+// when --stream is set.
+func enableStreaming(llmClient *llm.Client) {
+	if !streamLLM {
+		return
 	}
+	for _, c := range append([]*llm.Client{llmClient}, llmClient.Fallbacks...) {
+		c.Stream = true
+		c.OnChunk = func(s string) { fmt.Print(s) }
+	}
+}
 
+// printReview prints the summary and the inline and file-level comments of a review. With
+// --summary-only the comments are listed only in verbose mode.
+func printReview(r *review.Review, res review.Result) {
 	fmt.Println("------ AI Review Summary ------")
-	if summaryWithUnmatched != "" {
-		fmt.Println(summaryWithUnmatched)
+	if res.Summary != "" {
+		fmt.Println(res.Summary)
 	} else {
 		fmt.Println("(No summary comment found in LLM output.)")
 	}
 	fmt.Println("------ Inline Comments ------")
-	if len(matched) == 0 {
+	if len(r.Matched) == 0 {
 		fmt.Println("(No valid inline or file-level comments found in LLM output.)")
+	} else if summaryOnly && !verbose {
+		fmt.Printf("(%d comment(s) not shown or posted with --summary-only; use -v to show them.)\n", len(r.Matched))
 	} else {
-		for _, cmt := range matched {
-			if cmt.IsFileLevel {
-				fmt.Printf("[File: %s]\n%s\n\n", cmt.FilePath, cmt.Text)
-			} else {
-				fmt.Printf("[%s:%d]\n%s\n\n", cmt.FilePath, cmt.Line, cmt.Text)
-			}
+		for _, cmt := range res.FileLevel {
+			fmt.Printf("[File: %s]%s\n%s\n\n", cmt.FilePath, commentTag(cmt), cmt.Text)
+		}
+		for _, cmt := range res.Inline {
+			fmt.Printf("[%s]%s\n%s\n\n", cmt.Location(), commentTag(cmt), cmt.Text)
 		}
 	}
+	fmt.Printf("📊 %d inline, %d file-level, %d in summary\n", len(res.Inline), len(res.FileLevel), len(res.Unmatched))
+}
 
-	// Determine if we should post based on skip-inline flag and user confirmation
-	shouldPost := postToBB
-	if !skipInline {
-		// Interactive mode: prompt user
-		confirmed, err := utils.PromptYesNo("Should I post this review to Bitbucket?", "n")
-		if err != nil {
-			return fmt.Errorf("failed to read user input: %w", err)
+// acquirePRLock takes the per-PR run lock and returns a function that releases it.
+func acquirePRLock(ctx context.Context, bbClient *bitbucket.Client, prID string) (func(), error) {
+	owner := fmt.Sprintf("pid-%d", os.Getpid())
+	if host, err := os.Hostname(); err == nil {
+		owner = fmt.Sprintf("%s:%d", host, os.Getpid())
+	}
+	callCtx, cancel := withHostTimeout(ctx)
+	lock, err := bbClient.AcquireLock(callCtx, prID, owner, lockTTL)
+	cancel()
+	if errors.Is(err, bitbucket.ErrLocked) {
+		return nil, fmt.Errorf("another pullreview run is already reviewing PR #%s: %w", prID, err)
+	} else if err != nil {
+		return nil, err
+	}
+	fmt.Printf("🔒 Acquired review lock on PR #%s\n", prID)
+	return func() {
+		// Release even if the run was interrupted
+		releaseCtx, cancel := withHostTimeout(context.Background())
+		defer cancel()
+		if err := lock.Release(releaseCtx); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}, nil
+}
+
+// incrementalDiff narrows the PR diff to the hunks touched since --since: a commit, or "last"
+// for the commit recorded by the pullreview summary comment on the PR (see
+// review.LastReviewedCommit). It returns the PR
+// diff unchanged when there is no earlier review to start from, and skip when nothing new has
+// been pushed.
+func incrementalDiff(ctx context.Context, bbClient *bitbucket.Client, prID, head, diff string) (narrowed string, skip bool, err error) {
+	base := sinceCommit
+	if base == "last" {
+		base = review.LastReviewedCommit(loadPostedComments(ctx, bbClient, prID))
+		if base == "" {
+			fmt.Println("ℹ️  No earlier review found on this PR; reviewing the whole diff.")
+			return diff, false, nil
 		}
-		shouldPost = confirmed
+	}
+	if review.SameCommit(base, head) {
+		fmt.Printf("ℹ️  Nothing pushed since %s; skipping review.\n", base)
+		return "", true, nil
 	}
 
-	if !shouldPost {
-		fmt.Println("ℹ️  Review not posted to Bitbucket.")
+	callCtx, cancel := withHostTimeout(ctx)
+	incDiff, err := bbClient.GetDiffBetween(callCtx, base, head)
+	cancel()
+	if errors.Is(err, bitbucket.ErrDiffTruncated) {
+		fmt.Fprintf(os.Stderr, "⚠️  %v; narrowing the review with the partial diff only\n", err)
+	} else if err != nil {
+		printBitbucketHint(err)
+		return "", false, fmt.Errorf("failed to fetch changes since %s: %w", base, err)
+	}
+	narrowed, err = review.IncrementalDiff(diff, incDiff)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to narrow the diff to changes since %s: %w", base, err)
+	}
+	if narrowed == "" {
+		fmt.Printf("ℹ️  No changes to the PR diff since %s; skipping review.\n", base)
+		return "", true, nil
+	}
+	fmt.Printf("🔎 Reviewing only the changes since %s (%d of %d bytes of the PR diff)\n", base, len(narrowed), len(diff))
+	return narrowed, false, nil
+}
+
+// loadPostedComments returns the comments earlier runs posted on the PR, identified by their
+// markers. On failure it warns and returns nil, so every comment is treated as new.
+func loadPostedComments(ctx context.Context, bbClient *bitbucket.Client, prID string) []review.PostedComment {
+	callCtx, cancel := withHostTimeout(ctx)
+	existing, err := bbClient.ListComments(callCtx, prID)
+	cancel()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not list existing comments, posting all as new: %v\n", err)
 		return nil
 	}
+	var posted []review.PostedComment
+	account := bbClient.Account()
+	for _, ec := range existing {
+		// Only our own comments count; someone else may have quoted one of our markers
+		if account != nil && ec.AuthorID != "" && ec.AuthorID != account.AccountID {
+			continue
+		}
+		marker, ok := review.DecodeMarker(ec.Text)
+		if !ok {
+			continue
+		}
+		posted = append(posted, review.PostedComment{
+			ID:       ec.ID,
+			Text:     review.StripMarker(ec.Text),
+			Marker:   marker,
+			Resolved: ec.Resolved,
+		})
+	}
+	return posted
+}
 
-	// Bitbucket posting output section
-	fmt.Println("\n📤 Posting review to Bitbucket...")
+// applyReconcileSteps carries out the reconcile decisions against Bitbucket and returns the
+// number of inline comments posted. New inline comments are posted concurrently; results are
+// reported in step order regardless of completion order.
+func applyReconcileSteps(ctx context.Context, bbClient *bitbucket.Client, prID string, steps []review.ReconcileStep) int {
+	// Post all new inline comments up front as one concurrent batch
+	var batch []bitbucket.PRComment
+	batchIndex := make(map[int]int) // step index -> batch index
+	for i, step := range steps {
+		if (step.Action == review.ActionPost || step.Action == review.ActionRepost) && !step.Comment.IsFileLevel {
+			batchIndex[i] = len(batch)
+			batch = append(batch, bitbucket.PRComment{
+				FilePath: step.Comment.FilePath,
+				Line:     step.Comment.Line,
+				FromLine: step.Comment.OldLine,
+				Text:     review.AppendMarker(step.Comment.Text, step.Marker),
+			})
+		}
+	}
+	var batchErrs []error
+	if len(batch) > 0 {
+		batchErrs = bbClient.PostInlineComments(ctx, prID, batch, postConcurrency, hostTimeout)
+	}
 
-	// Post inline and file-level comments (only matched)
 	inlineCount := 0
-	for _, cmt := range matched {
-		if cmt.IsFileLevel {
-			err := bbClient.PostSummaryComment(finalPRID, cmt.Text)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "   ❌ Failed to post file-level comment to %s: %v\n", cmt.FilePath, err)
-			} else {
-				fmt.Printf("   ✅ Posted file-level comment to %s\n", cmt.FilePath)
+	post := func(i int, cmt review.Comment, marker review.Marker) bool {
+		if !cmt.IsFileLevel {
+			if err := batchErrs[batchIndex[i]]; err != nil {
+				fmt.Fprintf(os.Stderr, "   ❌ Failed to post inline comment to %s: %v\n", cmt.Location(), err)
+				return false
 			}
+			inlineCount++
+			fmt.Printf("   ✅ Posted inline comment to %s\n", cmt.Location())
+			return true
+		}
+		callCtx, cancel := withHostTimeout(ctx)
+		defer cancel()
+		if err := bbClient.PostSummaryComment(callCtx, prID, review.AppendMarker(cmt.Text, marker)); err != nil {
+			fmt.Fprintf(os.Stderr, "   ❌ Failed to post file-level comment to %s: %v\n", cmt.FilePath, err)
+			return false
+		}
+		fmt.Printf("   ✅ Posted file-level comment to %s\n", cmt.FilePath)
+		return true
+	}
+	resolve := func(existing *review.PostedComment) {
+		callCtx, cancel := withHostTimeout(ctx)
+		defer cancel()
+		if err := bbClient.ResolveComment(callCtx, prID, existing.ID); err != nil {
+			fmt.Fprintf(os.Stderr, "   ❌ Failed to resolve comment %s on %s: %v\n", existing.ID, existing.Marker.Path, err)
 		} else {
-			err := bbClient.PostInlineComment(finalPRID, cmt.FilePath, cmt.Line, cmt.Text)
+			fmt.Printf("   ✅ Resolved comment %s on %s (code no longer present)\n", existing.ID, existing.Marker.Path)
+		}
+	}
+
+	for i, step := range steps {
+		switch step.Action {
+		case review.ActionPost:
+			post(i, step.Comment, step.Marker)
+		case review.ActionRepost:
+			// Only retire the old comment once its replacement is up
+			if post(i, step.Comment, step.Marker) {
+				resolve(step.Existing)
+			}
+		case review.ActionUpdate:
+			callCtx, cancel := withHostTimeout(ctx)
+			err := bbClient.UpdateComment(callCtx, prID, step.Existing.ID, review.AppendMarker(step.Comment.Text, step.Marker))
+			cancel()
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "   ❌ Failed to post inline comment to %s:%d: %v\n", cmt.FilePath, cmt.Line, err)
+				fmt.Fprintf(os.Stderr, "   ❌ Failed to update comment %s on %s: %v\n", step.Existing.ID, step.Marker.Path, err)
 			} else {
-				inlineCount++
-				fmt.Printf("   ✅ Posted inline comment to %s:%d\n", cmt.FilePath, cmt.Line)
+				fmt.Printf("   ✅ Updated comment %s on %s\n", step.Existing.ID, step.Marker.Path)
+			}
+		case review.ActionResolve:
+			resolve(step.Existing)
+		case review.ActionKeep:
+			if verbose && step.Existing != nil {
+				fmt.Printf("   ℹ️  Kept existing comment %s on %s\n", step.Existing.ID, step.Existing.Marker.Path)
 			}
 		}
 	}
+	return inlineCount
+}
 
-	// Post summary comment (with unmatched comments as bullet points)
-	summaryPosted := false
-	if summaryWithUnmatched != "" {
-		err := bbClient.PostSummaryComment(finalPRID, summaryWithUnmatched)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "   ❌ Failed to post summary comment: %v\n", err)
+// postSummary posts the summary comment with a marker recording the reviewed commit, or
+// updates existing (the summary of an earlier run) in place. It reports whether it succeeded.
+func postSummary(ctx context.Context, bbClient *bitbucket.Client, prID, summary, commit string, existing *review.PostedComment) bool {
+	text := review.AppendMarker(summary, review.SummaryMarker(commit))
+	callCtx, cancel := withHostTimeout(ctx)
+	defer cancel()
+	if existing != nil {
+		if err := bbClient.UpdateComment(callCtx, prID, existing.ID, text); err != nil {
+			fmt.Fprintf(os.Stderr, "   ❌ Failed to update summary comment %s: %v\n", existing.ID, err)
+			return false
+		}
+		fmt.Printf("   ✅ Updated summary comment %s\n", existing.ID)
+		return true
+	}
+	if err := bbClient.PostSummaryComment(callCtx, prID, text); err != nil {
+		fmt.Fprintf(os.Stderr, "   ❌ Failed to post summary comment: %v\n", err)
+		return false
+	}
+	fmt.Println("   ✅ Posted summary comment")
+	return true
+}
+
+// withHostTimeout derives the context for a single code host API call.
+func withHostTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, hostTimeout)
+}
+
+// loadConfig resolves the config file to use (see config.FindConfigFile) and loads it with the
+// CLI overrides. For a local review (see localReview) the code host settings are not required.
+// cfgFile is updated to the resolved path so the prompt file can be found relative to it.
+func loadConfig() (*config.Config, error) {
+	cfgFile = config.FindConfigFile(cfgFile)
+	if verbose {
+		if cfgFile != "" {
+			fmt.Printf("⚙️  Using config file %s\n", cfgFile)
 		} else {
-			summaryPosted = true
-			fmt.Println("   ✅ Posted summary comment")
+			fmt.Println("⚙️  No config file found; using environment variables and flags")
+		}
+	}
+	var cfg *config.Config
+	var err error
+	if localReview() {
+		cfg, err = config.LoadLocalConfig(cfgFile)
+	} else {
+		cfg, err = config.LoadConfigWithOverrides(cfgFile, bbEmail, bbAPIToken, repoSlug)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	return cfg, nil
+}
+
+// newAuthenticatedClient creates a Bitbucket client from config and verifies its credentials.
+func newAuthenticatedClient(ctx context.Context, cfg *config.Config) (*bitbucket.Client, error) {
+	bbClient, err := newBitbucketClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	authCtx, cancel := withHostTimeout(ctx)
+	defer cancel()
+	if err := bbClient.Authenticate(authCtx); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Bitbucket login failed: %v\n", err)
+		printBitbucketHint(err)
+		if cfg.Bitbucket.APIToken == "" && cfg.Bitbucket.AccessToken == "" {
+			fmt.Fprintln(os.Stderr, "  - Missing Bitbucket API token (set in config, env, or CLI flag)")
 		}
+		if cfg.Bitbucket.Workspace == "" {
+			fmt.Fprintln(os.Stderr, "  - Missing Bitbucket workspace (set in config, env, or CLI flag)")
+		}
+		return nil, fmt.Errorf("could not authenticate with Bitbucket")
 	}
 
-	fmt.Printf("\n✅ Successfully posted %d inline comment(s)%s to PR #%s\n", inlineCount,
-		func() string {
-			if summaryPosted {
-				return " and summary"
-			}
-			return ""
-		}(), finalPRID)
+	if account := bbClient.Account(); account != nil {
+		fmt.Printf("✅ Successfully authenticated with Bitbucket as %s (workspace: %s)\n", account.DisplayName, cfg.Bitbucket.Workspace)
+	} else {
+		fmt.Printf("✅ Successfully authenticated with Bitbucket (workspace: %s)\n", cfg.Bitbucket.Workspace)
+	}
+	return bbClient, nil
+}
+
+// printBitbucketHint prints targeted advice for well-known Bitbucket API failures.
+func printBitbucketHint(err error) {
+	switch {
+	case errors.Is(err, bitbucket.ErrUnauthorized):
+		fmt.Fprintln(os.Stderr, "  - Bitbucket rejected the credentials; the API token may be expired or revoked. Regenerate it and update your config, env, or --token flag")
+	case errors.Is(err, bitbucket.ErrForbidden):
+		fmt.Fprintln(os.Stderr, "  - The Bitbucket token lacks permission for this repository; check its scopes (pull request read/write)")
+	case errors.Is(err, bitbucket.ErrNotFound):
+		fmt.Fprintln(os.Stderr, "  - Bitbucket returned 404; check the PR ID, workspace, and repository slug")
+	case errors.Is(err, bitbucket.ErrRateLimited):
+		fmt.Fprintln(os.Stderr, "  - Bitbucket rate limit exceeded; wait a few minutes and try again")
+	}
+}
+
+// newBitbucketClient creates a Bitbucket client from config without contacting Bitbucket.
+func newBitbucketClient(cfg *config.Config) (*bitbucket.Client, error) {
+	bbClient := bitbucket.NewClient(
+		cfg.Bitbucket.Email,
+		cfg.Bitbucket.APIToken,
+		cfg.Bitbucket.Workspace,
+		cfg.Bitbucket.RepoSlug,
+		cfg.Bitbucket.BaseURL,
+	)
+	bbClient.Kind = cfg.Bitbucket.Kind
+	bbClient.AccessToken = cfg.Bitbucket.AccessToken
+	if err := bbClient.Validate(); err != nil {
+		return nil, err
+	}
+	return bbClient, nil
+}
+
+// newLLMClient creates the LLM client described by the config.
+func newLLMClient(cfg *config.Config) *llm.Client {
+	llm.SetVerbose(verbose)
+	// The format was validated by applyReviewFlags
+	logger, _ := logging.New(logFormat, "llm", verbose)
+	llmClient := llm.NewClient(cfg.LLM.Provider, cfg.LLM.APIKey, cfg.LLM.Endpoint)
+	llmClient.Logger = logger
+	llmClient.Model = cfg.LLM.Model
+	llmClient.Deployment = cfg.LLM.Deployment
+	llmClient.APIVersion = cfg.LLM.APIVersion
+	llmClient.Timeout = cfg.LLM.Timeout
+	llmClient.PricePer1KTokens = cfg.LLM.PricePer1KTokens
+	if cfg.LLM.CacheDir != "" && !noCache {
+		llmClient.Cache = llm.NewCache(cfg.LLM.CacheDir, cfg.LLM.CacheTTL)
+	}
+	for _, p := range cfg.LLM.Providers {
+		fb := llm.NewClient(p.Provider, p.APIKey, p.Endpoint)
+		fb.Model = p.Model
+		fb.Deployment = p.Deployment
+		fb.APIVersion = p.APIVersion
+		fb.PricePer1KTokens = p.PricePer1KTokens
+		fb.Timeout = llmClient.Timeout
+		fb.Cache = llmClient.Cache
+		fb.Logger = logger
+		llmClient.Fallbacks = append(llmClient.Fallbacks, fb)
+	}
+	return llmClient
+}
+
+// loadPromptTemplate reads the review prompt template, resolving it relative to the config file.
+func loadPromptTemplate(cfg *config.Config) (string, error) {
+	// Resolve prompt file path relative to config file location if not absolute
+	promptPath := cfg.PromptFile
+	if !filepath.IsAbs(promptPath) && cfgFile != "" {
+		cfgDir := filepath.Dir(cfgFile)
+		promptPath = filepath.Join(cfgDir, promptPath)
+	}
 
+	// Load prompt template
+	promptBytes, err := os.ReadFile(promptPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read prompt file %q: %w", promptPath, err)
+	}
+	promptTemplate := string(promptBytes)
+
+	// Validate prompt is not empty
+	if strings.TrimSpace(promptTemplate) == "" {
+		return "", fmt.Errorf("prompt file %q is empty - cannot proceed without a valid prompt template", promptPath)
+	}
+	if err := review.ValidatePromptTemplate(promptTemplate); err != nil {
+		return "", fmt.Errorf("invalid prompt file %q: %w", promptPath, err)
+	}
+	return promptTemplate, nil
+}
+
+// applyReviewFlags lets --min-severity, --max-inline-comments and --line-tolerance override
+// the configured values and validates the result. The numeric flags override whenever they
+// are given, so an explicit 0 turns the cap or snapping off again.
+func applyReviewFlags(cmd *cobra.Command, cfg *config.Config) error {
+	if minSeverity != "" {
+		cfg.MinSeverity = minSeverity
+	}
+	if cmd.Flags().Changed("max-inline-comments") {
+		if maxInline < 0 {
+			return fmt.Errorf("invalid --max-inline-comments %d (must not be negative)", maxInline)
+		}
+		cfg.MaxInlineComments = maxInline
+	}
+	if cmd.Flags().Changed("line-tolerance") {
+		if lineTolerance < 0 {
+			return fmt.Errorf("invalid --line-tolerance %d (must not be negative)", lineTolerance)
+		}
+		cfg.LineTolerance = lineTolerance
+	}
+	if err := review.ValidateSeverity(cfg.MinSeverity); err != nil {
+		return fmt.Errorf("invalid minimum severity: %w", err)
+	}
+	if err := pathFilter(nil).Validate(); err != nil {
+		return err
+	}
+	ignorePath := localIgnoreFile()
+	rules, err := review.LoadIgnoreFile(ignorePath)
+	if err != nil {
+		return err
+	}
+	ignoreRules = rules
+	if verbose && rules.Len() > 0 {
+		fmt.Printf("🙈 Loaded %d pattern(s) from %s\n", rules.Len(), ignorePath)
+	}
+	if _, err := logging.New(logFormat, "", false); err != nil {
+		return err
+	}
+	cfg.MinSeverity = review.NormalizeSeverity(cfg.MinSeverity)
 	return nil
 }
+
+// commentTag renders a comment's severity, category and line adjustment for the console, e.g.
+// " (high, security)", or "" if there is nothing to show.
+func commentTag(cmt review.Comment) string {
+	var labels []string
+	for _, l := range []string{cmt.Severity, cmt.Category} {
+		if l != "" {
+			labels = append(labels, l)
+		}
+	}
+	if cmt.SnappedFrom > 0 {
+		labels = append(labels, fmt.Sprintf("moved from line %d", cmt.SnappedFrom))
+	}
+	if len(labels) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" (%s)", strings.Join(labels, ", "))
+}
+
+// pathFilter returns the --include/--exclude path filter, plus the given .pullreviewignore rules.
+func pathFilter(ignore *review.IgnoreRules) review.PathFilter {
+	return review.PathFilter{Include: includePaths, Exclude: excludePaths, Ignore: ignore}
+}
+
+// localIgnoreFile returns the path of the .pullreviewignore used when it cannot be read from
+// the PR itself (everything but Bitbucket PR reviews): the one at the root of the git
+// repository containing the working directory, or in the working directory outside a
+// repository.
+func localIgnoreFile() string {
+	if root, err := utils.GetGitRepoRoot("."); err == nil && root != "" {
+		return filepath.Join(root, review.IgnoreFileName)
+	}
+	return review.IgnoreFileName
+}
+
+// prIgnoreRules reads the .pullreviewignore at the root of the repository as of the PR's
+// source commit, so each PR is filtered by its own rules wherever pullreview runs (serve,
+// --all-open). Without a source commit it falls back to the local rules.
+func prIgnoreRules(ctx context.Context, bbClient *bitbucket.Client, commit string) (*review.IgnoreRules, error) {
+	if commit == "" {
+		return ignoreRules, nil
+	}
+	callCtx, cancel := withHostTimeout(ctx)
+	data, err := bbClient.GetFileContent(callCtx, commit, review.IgnoreFileName)
+	cancel()
+	if errors.Is(err, bitbucket.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", review.IgnoreFileName, err)
+	}
+	rules, err := review.ParseIgnore(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s at %s: %w", review.IgnoreFileName, commit, err)
+	}
+	if verbose && rules.Len() > 0 {
+		fmt.Printf("🙈 Loaded %d pattern(s) from %s at %s\n", rules.Len(), review.IgnoreFileName, commit)
+	}
+	return rules, nil
+}
+
+// reviewDiff asks the LLM to review diff with review.Run, configured from cfg and the command
+// line flags, and reports progress on stdout. Diffs larger than llm.max_diff_bytes are reviewed
+// in per-file chunks. Diffs over oversized_diff_bytes are handled per oversized_diff; when they
+// are skipped, the returned review is nil. Files matched by ignore (the .pullreviewignore
+// rules) are not reviewed.
+func reviewDiff(ctx context.Context, llmClient *llm.Client, cfg *config.Config, promptTemplate, prID, diff string, pr review.PRInfo, ignore *review.IgnoreRules, timings *report.Timings) (*review.Review, error) {
+	opts := reviewOptions(cfg, ignore)
+	opts.Logger = &logging.Console{}
+	opts.StartParse = func() func() { return timings.Start(report.PhaseParse) }
+
+	tokens := 0
+	send := func(chunk string) (string, error) {
+		// Inject diff into prompt
+		instructions, finalPrompt, err := review.RenderPromptParts(promptTemplate, chunk, pr)
+		if err != nil {
+			return "", err
+		}
+
+		// Send prompt to LLM
+		fmt.Println("🤖 Sending review prompt to LLM...")
+		stopLLM := timings.Start(report.PhaseLLM)
+		llmResp, err := llmClient.SendReviewWithSystem(ctx, instructions, finalPrompt)
+		stopLLM()
+		if err != nil {
+			return "", err
+		}
+		if llmResp.Streamed {
+			// End the streamed output's last line
+			fmt.Println()
+		}
+		if len(llmClient.Fallbacks) > 0 {
+			fmt.Printf("🤖 Response served by %s (model %s)\n", llmResp.Provider, llmResp.Model)
+		}
+		// A cached response cost nothing this time, so its usage is not reported
+		if llmResp.Usage != nil && !llmResp.Cached {
+			fmt.Println(formatUsage(*llmResp.Usage, llmResp.PricePer1KTokens))
+			tokens += llmResp.Usage.TotalTokens
+		}
+		return llmResp.Content, nil
+	}
+	r, err := review.Run(prID, diff, opts, send)
+	if err != nil {
+		printLLMHint(err)
+		return nil, err
+	}
+	if r != nil {
+		r.Tokens = tokens
+	}
+	return r, nil
+}
+
+// reviewOptions returns the review.Run options set by cfg and the command line flags, with
+// the given .pullreviewignore rules.
+func reviewOptions(cfg *config.Config, ignore *review.IgnoreRules) review.Options {
+	opts := review.Options{
+		Paths:              pathFilter(ignore),
+		Categories:         categories,
+		MinSeverity:        cfg.MinSeverity,
+		LineTolerance:      cfg.LineTolerance,
+		MaxInlineComments:  cfg.MaxInlineComments,
+		MaxDiffBytes:       cfg.LLM.MaxDiffBytes,
+		OversizedDiffBytes: cfg.OversizedDiffBytes,
+		OversizedDiff:      cfg.OversizedDiff,
+		OutsideDiff:        outsideDiff,
+	}
+	if outsideDiff == review.OutsideDiffVerify {
+		if wd, err := os.Getwd(); err == nil {
+			if root, err := utils.GetGitRepoRoot(wd); err == nil {
+				opts.RepoRoot = root
+			} else {
+				opts.RepoRoot = wd
+			}
+		}
+	}
+	return opts
+}
+
+// formatUsage renders a one-line token usage summary, including an estimated cost when a
+// price per 1,000 tokens is configured.
+func formatUsage(u llm.Usage, pricePer1K float64) string {
+	line := fmt.Sprintf("🔢 Tokens used: %d prompt + %d completion = %d total",
+		u.PromptTokens, u.CompletionTokens, u.TotalTokens)
+	if pricePer1K > 0 {
+		line += fmt.Sprintf(" (estimated cost: $%.4f)", u.Cost(pricePer1K))
+	}
+	return line
+}
+
+// printLLMHint prints targeted advice for well-known LLM provider failures.
+func printLLMHint(err error) {
+	switch {
+	case errors.Is(err, llm.ErrInsufficientQuota):
+		fmt.Fprintln(os.Stderr, "  - The LLM account is out of credit or quota; top up or raise the spending limit with your provider, or switch llm.provider/llm.model")
+	case errors.Is(err, llm.ErrInvalidAPIKey):
+		fmt.Fprintln(os.Stderr, "  - The LLM provider rejected the API key; check llm.api_key or LLM_API_KEY")
+	case errors.Is(err, llm.ErrRateLimited):
+		fmt.Fprintln(os.Stderr, "  - The LLM provider is rate limiting requests; wait a few minutes, raise your plan's limits, or pick a less busy model")
+	}
+}
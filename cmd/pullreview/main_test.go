@@ -0,0 +1,48 @@
+package main
+
+import (
+	"testing"
+
+	"pullreview/internal/config"
+)
+
+func TestApplyReviewFlags_ExplicitZeroOverridesConfig(t *testing.T) {
+	cmd := newRootCmd()
+	if err := cmd.ParseFlags([]string{"--max-inline-comments=0", "--line-tolerance=0"}); err != nil {
+		t.Fatalf("failed to parse flags: %v", err)
+	}
+	cfg := &config.Config{MaxInlineComments: 20, LineTolerance: 3}
+	if err := applyReviewFlags(cmd, cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.MaxInlineComments != 0 || cfg.LineTolerance != 0 {
+		t.Errorf("expected explicit zero flags to override the config, got cap %d, tolerance %d", cfg.MaxInlineComments, cfg.LineTolerance)
+	}
+
+	cmd = newRootCmd()
+	cfg = &config.Config{MaxInlineComments: 20, LineTolerance: 3}
+	if err := applyReviewFlags(cmd, cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.MaxInlineComments != 20 || cfg.LineTolerance != 3 {
+		t.Errorf("expected the configured values without the flags, got cap %d, tolerance %d", cfg.MaxInlineComments, cfg.LineTolerance)
+	}
+}
+
+func TestApplyReviewFlags_RejectsNegative(t *testing.T) {
+	cmd := newRootCmd()
+	if err := cmd.ParseFlags([]string{"--max-inline-comments=-1"}); err != nil {
+		t.Fatalf("failed to parse flags: %v", err)
+	}
+	if err := applyReviewFlags(cmd, &config.Config{}); err == nil {
+		t.Error("expected an error for a negative --max-inline-comments")
+	}
+
+	cmd = newRootCmd()
+	if err := cmd.ParseFlags([]string{"--line-tolerance=-2"}); err != nil {
+		t.Fatalf("failed to parse flags: %v", err)
+	}
+	if err := applyReviewFlags(cmd, &config.Config{}); err == nil {
+		t.Error("expected an error for a negative --line-tolerance")
+	}
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"pullreview/internal/config"
+	"pullreview/internal/github"
+	"pullreview/internal/gitlab"
+	"pullreview/internal/llm"
+	"pullreview/internal/provider"
+	"pullreview/internal/report"
+	"pullreview/internal/review"
+)
+
+// newGitHubClient creates a GitHub client from config and checks its settings.
+func newGitHubClient(cfg *config.Config) (*github.Client, error) {
+	client := github.NewClient(cfg.GitHub.Token, cfg.GitHub.Owner, cfg.GitHub.Repo, cfg.GitHub.BaseURL)
+	if err := client.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid GitHub configuration: %w", err)
+	}
+	return client, nil
+}
+
+// newGitLabClient creates a GitLab client from config and checks its settings.
+func newGitLabClient(cfg *config.Config) (*gitlab.Client, error) {
+	client := gitlab.NewClient(cfg.GitLab.Token, cfg.GitLab.Project, cfg.GitLab.BaseURL)
+	if err := client.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid GitLab configuration: %w", err)
+	}
+	return client, nil
+}
+
+// newProviderClient creates the posting layer for a non-Bitbucket provider, along with the
+// host name used in messages.
+func newProviderClient(cfg *config.Config) (provider.Provider, string, error) {
+	switch cfg.Provider {
+	case config.ProviderGitHub:
+		client, err := newGitHubClient(cfg)
+		return client, "GitHub", err
+	case config.ProviderGitLab:
+		client, err := newGitLabClient(cfg)
+		return client, "GitLab", err
+	default:
+		return nil, "", fmt.Errorf("provider %s has no generic posting layer", cfg.Provider)
+	}
+}
+
+// requireBitbucket rejects commands that only work against Bitbucket when another provider is
+// configured.
+func requireBitbucket(cfg *config.Config, command string) error {
+	if cfg.Provider != config.ProviderBitbucket {
+		return fmt.Errorf("%s only supports provider %s (configured: %s)", command, config.ProviderBitbucket, cfg.Provider)
+	}
+	return nil
+}
+
+// validateProviderFlags rejects the flags that rely on Bitbucket-only features when reviewing
+// PRs on another provider.
+func validateProviderFlags(name string) error {
+	switch {
+	case len(prIDs) == 0:
+		return fmt.Errorf("--pr is required with provider %s", name)
+	case allOpen:
+		return fmt.Errorf("--all-open is not supported with provider %s", name)
+	case sinceCommit != "":
+		return fmt.Errorf("--since is not supported with provider %s", name)
+	case useLock:
+		return fmt.Errorf("--lock is not supported with provider %s", name)
+	case skipApproved:
+		return fmt.Errorf("--skip-approved is not supported with provider %s", name)
+	}
+	return nil
+}
+
+// runProviderReview reviews the --pr pull requests (GitLab merge request IIDs) on a
+// non-Bitbucket provider.
+func runProviderReview(ctx context.Context, cfg *config.Config, failThreshold string) error {
+	if err := validateProviderFlags(cfg.Provider); err != nil {
+		return err
+	}
+	client, host, err := newProviderClient(cfg)
+	if err != nil {
+		return err
+	}
+	llmClient := newLLMClient(cfg)
+	enableStreaming(llmClient)
+	promptTemplate, err := loadPromptTemplate(cfg)
+	if err != nil {
+		return err
+	}
+	return reviewAll(ctx, prIDs, map[string]string{}, failThreshold, report.NewHistory(cfg.HistoryFile), func(id string, timings *report.Timings) (*review.Review, report.Posting, error) {
+		return reviewWithProvider(ctx, cfg, client, host, llmClient, promptTemplate, id, timings)
+	})
+}
+
+// reviewWithProvider reviews one PR through the generic posting layer: it fetches the diff,
+// runs the review, and posts the comments and summary to host. Unlike the Bitbucket flow, it
+// does not reconcile with comments from earlier runs, so re-running posts the comments again.
+func reviewWithProvider(ctx context.Context, cfg *config.Config, p provider.Provider, host string, llmClient *llm.Client, promptTemplate, prID string, timings *report.Timings) (*review.Review, report.Posting, error) {
+	stopFetch := timings.Start(report.PhaseFetchPR)
+	callCtx, cancel := withHostTimeout(ctx)
+	info, err := p.GetPRInfo(callCtx, prID)
+	cancel()
+	stopFetch()
+	if err != nil {
+		return nil, report.Posting{}, fmt.Errorf("failed to fetch PR: %w", err)
+	}
+	fmt.Printf("🔖 PR Title: %s\n", info.Title)
+
+	stopFetch = timings.Start(report.PhaseFetchDiff)
+	callCtx, cancel = withHostTimeout(ctx)
+	diff, err := p.GetPRDiff(callCtx, prID)
+	cancel()
+	stopFetch()
+	if err != nil {
+		return nil, report.Posting{}, fmt.Errorf("failed to fetch PR diff: %w", err)
+	}
+	if diff == "" {
+		fmt.Printf("ℹ️  PR #%s has an empty diff; nothing to review.\n", prID)
+		return nil, report.Posting{}, nil
+	}
+	fmt.Printf("✅ Fetched PR diff for PR #%s from %s (length: %d bytes)\n", prID, host, len(diff))
+	if verbose {
+		fmt.Println("------ BEGIN PR DIFF ------")
+		fmt.Println(diff)
+		fmt.Println("------- END PR DIFF -------")
+	}
+
+	r, err := reviewDiff(ctx, llmClient, cfg, promptTemplate, prID, diff, review.PRInfo{Title: info.Title, Description: info.Description}, ignoreRules, timings)
+	if err != nil || r == nil {
+		return nil, report.Posting{}, err
+	}
+	res := r.Result()
+	printReview(r, res)
+	if summaryOnly {
+		res = res.SummaryOnly(foldInline)
+	}
+
+	res, shouldPost, err := approveReview(host, res)
+	if err != nil {
+		return nil, report.Posting{}, err
+	}
+	if !shouldPost {
+		fmt.Printf("ℹ️  Review not posted to %s.\n", host)
+		return r, report.Posting{}, nil
+	}
+
+	fmt.Printf("\n📤 Posting review to %s...\n", host)
+	fmt.Printf("ℹ️  Comments from earlier runs on %s are not updated or resolved; re-running posts them again.\n", host)
+	defer timings.Start(report.PhasePost)()
+	posting := report.Posting{Posted: true}
+	for _, cmt := range res.FileLevel {
+		callCtx, cancel := withHostTimeout(ctx)
+		err := p.PostSummaryComment(callCtx, prID, fileLevelText(cmt))
+		cancel()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "   ❌ Failed to post file-level comment to %s: %v\n", cmt.FilePath, err)
+			continue
+		}
+		posting.InlineComments++
+		fmt.Printf("   ✅ Posted file-level comment to %s\n", cmt.FilePath)
+	}
+	for _, cmt := range res.Inline {
+		callCtx, cancel := withHostTimeout(ctx)
+		err := p.PostInlineComment(callCtx, prID, provider.Comment{
+			FilePath: cmt.FilePath,
+			Line:     cmt.Line,
+			FromLine: cmt.OldLine,
+			Text:     cmt.Text,
+		})
+		cancel()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "   ❌ Failed to post inline comment to %s: %v\n", cmt.Location(), err)
+			continue
+		}
+		posting.InlineComments++
+		fmt.Printf("   ✅ Posted inline comment to %s\n", cmt.Location())
+	}
+	if res.Summary != "" {
+		callCtx, cancel := withHostTimeout(ctx)
+		err := p.PostSummaryComment(callCtx, prID, res.Summary)
+		cancel()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "   ❌ Failed to post summary comment: %v\n", err)
+		} else {
+			posting.SummaryPosted = true
+			fmt.Println("   ✅ Posted summary comment")
+		}
+	}
+	andSummary := ""
+	if posting.SummaryPosted {
+		andSummary = " and summary"
+	}
+	fmt.Printf("\n✅ Posted %d comment(s)%s to PR #%s\n", posting.InlineComments, andSummary, prID)
+	return r, posting, nil
+}
+
+// fileLevelText returns the text of a file-level comment posted as a top-level PR comment,
+// headed by the file's path since the comment itself is not attached to the file.
+func fileLevelText(cmt review.Comment) string {
+	return fmt.Sprintf("**%s**\n\n%s", cmt.FilePath, cmt.Text)
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"pullreview/internal/report"
+	"pullreview/internal/review"
+	"pullreview/internal/webhook"
+)
+
+const defaultListenAddr = ":8080"
+
+var (
+	serveAddr   string
+	serveDryRun bool
+)
+
+// newServeCmd creates the serve subcommand, which runs an HTTP server that reviews PRs when
+// Bitbucket sends pull request created/updated webhooks.
+func newServeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Run an HTTP server that reviews PRs on Bitbucket webhooks",
+		Long: "serve listens for Bitbucket pull request webhooks on /webhook and runs the review for " +
+			"each created or updated PR in the background, posting the results. Webhook requests " +
+			"must be signed with webhook.secret.",
+		RunE: runServe,
+	}
+	cmd.Flags().StringVar(&serveAddr, "addr", "", "Listen address (overrides webhook.listen_addr; default "+defaultListenAddr+")")
+	cmd.Flags().BoolVar(&serveDryRun, "dry-run", false, "Review PRs but do not post anything to Bitbucket")
+	return cmd
+}
+
+func runServe(cmd *cobra.Command, args []string) error {
+	if err := review.ValidateOutsideDiffPolicy(outsideDiff); err != nil {
+		return err
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	if err := applyReviewFlags(cmd, cfg); err != nil {
+		return err
+	}
+	if err := requireBitbucket(cfg, "serve"); err != nil {
+		return err
+	}
+	if cfg.Webhook.Secret == "" {
+		return errors.New("webhook.secret (or PULLREVIEW_WEBHOOK_SECRET) is required to verify webhook signatures")
+	}
+	addr := cfg.Webhook.ListenAddr
+	if serveAddr != "" {
+		addr = serveAddr
+	}
+	if addr == "" {
+		addr = defaultListenAddr
+	}
+
+	ctx := cmd.Context()
+	bbClient, err := newAuthenticatedClient(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	llmClient := newLLMClient(cfg)
+	promptTemplate, err := loadPromptTemplate(cfg)
+	if err != nil {
+		return err
+	}
+
+	history := report.NewHistory(cfg.HistoryFile)
+
+	// Reviews run unattended: never prompt, and post unless --dry-run
+	skipInline, postToBB = true, !serveDryRun
+
+	// Each PR is reviewed by at most one goroutine at a time. Events for a PR under review
+	// queue a single re-run so the latest commits are still reviewed.
+	var (
+		mu      sync.Mutex
+		running = make(map[string]bool)
+		rerun   = make(map[string]bool)
+		wg      sync.WaitGroup
+	)
+	var start func(prID string)
+	start = func(prID string) {
+		mu.Lock()
+		defer mu.Unlock()
+		if running[prID] {
+			rerun[prID] = true
+			fmt.Printf("ℹ️  PR #%s is already being reviewed; queued a re-run\n", prID)
+			return
+		}
+		running[prID] = true
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			fmt.Printf("📄 Reviewing PR #%s (webhook)...\n", prID)
+			timings := &report.Timings{}
+			r, posting, err := reviewPR(ctx, cfg, bbClient, llmClient, promptTemplate, prID, timings)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "❌ Failed to review PR #%s: %v\n", prID, err)
+			}
+			entry := report.NewHistoryEntry(newReviewResult(prID, r, posting, err, timings), time.Now())
+			if err := history.Append(entry); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to record review history: %v\n", err)
+			}
+			mu.Lock()
+			delete(running, prID)
+			again := rerun[prID] && ctx.Err() == nil
+			delete(rerun, prID)
+			mu.Unlock()
+			if again {
+				start(prID)
+			}
+		}()
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/webhook", &webhook.Handler{Secret: cfg.Webhook.Secret, Review: start})
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	srv := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+
+	fmt.Printf("👂 Listening for Bitbucket webhooks on %s/webhook\n", addr)
+	if err := srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("webhook server failed: %w", err)
+	}
+	// In-flight reviews were cancelled with ctx; wait for them to wind down
+	wg.Wait()
+	return nil
+}
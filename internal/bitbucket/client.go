@@ -2,44 +2,91 @@ package bitbucket
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	"unicode"
+
+	"pullreview/internal/provider"
 )
 
-// PRComment represents a comment to be posted to a PR.
-type PRComment struct {
-	FilePath string // Relative file path for inline comments
-	Line     int    // Line number for inline comments (new file)
-	Text     string // Markdown comment text
+// ErrDiffTruncated indicates that Bitbucket truncated a PR diff. GetPRDiff returns it wrapped
+// in a *TruncatedDiffError, so callers can use errors.Is to detect it and errors.As to recover
+// the partial diff.
+var ErrDiffTruncated = errors.New("PR diff was truncated by Bitbucket")
+
+// TruncatedDiffError carries the partial diff returned by Bitbucket when the full diff was too large.
+type TruncatedDiffError struct {
+	PartialDiff string // Diff content received before the truncation point, with the notice removed
+}
+
+func (e *TruncatedDiffError) Error() string {
+	return fmt.Sprintf("%v (received %d bytes)", ErrDiffTruncated, len(e.PartialDiff))
+}
+
+// Unwrap allows errors.Is(err, ErrDiffTruncated).
+func (e *TruncatedDiffError) Unwrap() error {
+	return ErrDiffTruncated
+}
+
+// maxDiffResponseBytes is the size at which Bitbucket caps diff responses; a body of at least
+// this size is treated as truncated even when no notice is present.
+const maxDiffResponseBytes = 10 * 1024 * 1024
+
+// diffTruncationMarkers are notices Bitbucket appends to a diff it has cut short.
+var diffTruncationMarkers = []string{
+	"This diff has been truncated",
+	"Diff truncated",
+	"diff is too large",
+}
+
+// detectTruncatedDiff reports whether the diff body was truncated and returns it with any
+// trailing truncation notice removed.
+func detectTruncatedDiff(diff string) (string, bool) {
+	lines := strings.Split(strings.TrimRight(diff, "\n"), "\n")
+	for i := len(lines) - 1; i >= 0 && i >= len(lines)-3; i-- {
+		// Diff content lines (including added text that happens to mention truncation) are never notices
+		if strings.HasPrefix(lines[i], "+") || strings.HasPrefix(lines[i], "-") || strings.HasPrefix(lines[i], " ") {
+			continue
+		}
+		for _, marker := range diffTruncationMarkers {
+			if strings.Contains(strings.ToLower(lines[i]), strings.ToLower(marker)) {
+				return strings.Join(lines[:i], "\n") + "\n", true
+			}
+		}
+	}
+	return diff, len(diff) >= maxDiffResponseBytes
 }
 
-// PostInlineComment posts an inline comment to a specific line in a PR.
-func (c *Client) PostInlineComment(prID, filePath string, line int, text string) error {
-	if prID == "" || filePath == "" || line <= 0 || text == "" {
+// PRComment represents a comment to be posted to a PR.
+type PRComment = provider.Comment
+
+// Client implements the posting layer shared with other code hosts.
+var _ provider.Provider = (*Client)(nil)
+
+// PostInlineComment posts an inline comment to a specific line in a PR, anchored to the new
+// file (Line) or, for deleted lines, to the old file (FromLine).
+func (c *Client) PostInlineComment(ctx context.Context, prID string, cmt PRComment) error {
+	if prID == "" || cmt.FilePath == "" || (cmt.Line <= 0 && cmt.FromLine <= 0) || cmt.Text == "" {
 		return errors.New("missing required fields for inline comment")
 	}
-	url := fmt.Sprintf("%s/repositories/%s/%s/pullrequests/%s/comments", c.BaseURL, c.Workspace, c.RepoSlug, prID)
-	body := map[string]interface{}{
-		"content": map[string]string{
-			"raw": text,
-		},
-		"inline": map[string]interface{}{
-			"path": filePath,
-			"to":   line,
-		},
-	}
+	url := c.api().commentsURL(c, prID)
+	body := c.api().inlineCommentBody(cmt)
 	bodyBytes, err := json.Marshal(body)
 	if err != nil {
 		return fmt.Errorf("failed to marshal inline comment: %w", err)
 	}
-	req, err := http.NewRequest("POST", url, bytes.NewReader(bodyBytes))
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(bodyBytes))
 	if err != nil {
 		return fmt.Errorf("failed to create inline comment request: %w", err)
 	}
-	req.SetBasicAuth(c.Email, c.APIToken)
+	c.setAuth(req)
 	req.Header.Set("Content-Type", "application/json")
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
@@ -47,32 +94,27 @@ func (c *Client) PostInlineComment(prID, filePath string, line int, text string)
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusCreated {
-		respBody, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("failed to post inline comment: status %d, response: %s", resp.StatusCode, string(respBody))
+		return fmt.Errorf("failed to post inline comment: %w", newAPIError(req.URL.String(), resp))
 	}
 	return nil
 }
 
 // PostSummaryComment posts a summary (top-level) comment to a PR.
-func (c *Client) PostSummaryComment(prID, text string) error {
+func (c *Client) PostSummaryComment(ctx context.Context, prID, text string) error {
 	if prID == "" || text == "" {
 		return errors.New("missing required fields for summary comment")
 	}
-	url := fmt.Sprintf("%s/repositories/%s/%s/pullrequests/%s/comments", c.BaseURL, c.Workspace, c.RepoSlug, prID)
-	body := map[string]interface{}{
-		"content": map[string]string{
-			"raw": text,
-		},
-	}
+	url := c.api().commentsURL(c, prID)
+	body := c.api().summaryCommentBody(text)
 	bodyBytes, err := json.Marshal(body)
 	if err != nil {
 		return fmt.Errorf("failed to marshal summary comment: %w", err)
 	}
-	req, err := http.NewRequest("POST", url, bytes.NewReader(bodyBytes))
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(bodyBytes))
 	if err != nil {
 		return fmt.Errorf("failed to create summary comment request: %w", err)
 	}
-	req.SetBasicAuth(c.Email, c.APIToken)
+	c.setAuth(req)
 	req.Header.Set("Content-Type", "application/json")
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
@@ -80,22 +122,53 @@ func (c *Client) PostSummaryComment(prID, text string) error {
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusCreated {
-		respBody, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("failed to post summary comment: status %d, response: %s", resp.StatusCode, string(respBody))
+		return fmt.Errorf("failed to post summary comment: %w", newAPIError(req.URL.String(), resp))
 	}
 	return nil
 }
 
-// Client provides methods for interacting with the Bitbucket Cloud API.
+// Client provides methods for interacting with the Bitbucket Cloud or Bitbucket Server API.
 type Client struct {
 	Email     string
 	APIToken  string
-	Workspace string
+	Workspace string // Workspace (Cloud) or project key (Server)
 	RepoSlug  string
 	BaseURL   string
+	Kind      string // KindCloud (default) or KindServer
+
+	// AccessToken is an OAuth2 access token. When set, requests use "Authorization: Bearer"
+	// instead of basic auth with Email and APIToken.
+	AccessToken string
+
+	account *Account // Set by a successful Authenticate on Bitbucket Cloud
 }
 
-// NewClient creates a new Bitbucket API client.
+// Account identifies the Bitbucket user the client authenticated as.
+type Account struct {
+	AccountID   string
+	UUID        string
+	Username    string
+	DisplayName string
+}
+
+// Account returns the account the client authenticated as, or nil if Authenticate has not
+// succeeded or the deployment does not report it (Bitbucket Server).
+func (c *Client) Account() *Account {
+	return c.account
+}
+
+// setAuth applies the configured authentication to a request: a bearer token when
+// AccessToken is set, otherwise basic auth with the account email and API token.
+func (c *Client) setAuth(req *http.Request) {
+	if c.AccessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+c.AccessToken)
+		return
+	}
+	req.SetBasicAuth(c.Email, c.APIToken)
+}
+
+// NewClient creates a new Bitbucket API client. Surrounding whitespace is trimmed from the
+// workspace and repo slug; call Validate to check their format.
 func NewClient(email, apiToken, workspace, repoSlug, baseURL string) *Client {
 	if baseURL == "" {
 		baseURL = "https://api.bitbucket.org/2.0"
@@ -103,29 +176,66 @@ func NewClient(email, apiToken, workspace, repoSlug, baseURL string) *Client {
 	return &Client{
 		Email:     email,
 		APIToken:  apiToken,
-		Workspace: workspace,
-		RepoSlug:  repoSlug,
+		Workspace: strings.TrimSpace(workspace),
+		RepoSlug:  strings.TrimSpace(repoSlug),
 		BaseURL:   baseURL,
 	}
 }
 
-// Authenticate checks if the Bitbucket credentials are valid by calling the /user endpoint.
-// Returns nil if authentication is successful, or an error with details otherwise.
-func (c *Client) Authenticate() error {
-	if c.Email == "" {
-		return errors.New("missing Bitbucket account email")
+// Validate trims surrounding whitespace from the workspace and repo slug and checks that they
+// are bare identifiers rather than URLs or paths, so configuration mistakes surface before the
+// first API call fails with a confusing 404. An empty repo slug is allowed here; methods that
+// need it report it themselves.
+func (c *Client) Validate() error {
+	c.Workspace = strings.TrimSpace(c.Workspace)
+	c.RepoSlug = strings.TrimSpace(c.RepoSlug)
+	if c.Workspace == "" {
+		return errors.New("bitbucket workspace is required")
+	}
+	if err := validateSlug("workspace", c.Workspace); err != nil {
+		return err
+	}
+	if c.RepoSlug != "" {
+		if err := validateSlug("repo slug", c.RepoSlug); err != nil {
+			return err
+		}
 	}
-	if c.APIToken == "" {
-		return errors.New("missing Bitbucket API token")
+	return nil
+}
+
+// validateSlug rejects values that look like URLs or paths, or contain whitespace.
+func validateSlug(name, value string) error {
+	switch {
+	case strings.Contains(value, "://"):
+		return fmt.Errorf("invalid bitbucket %s %q: use the bare name, not a URL", name, value)
+	case strings.Contains(value, "/"):
+		return fmt.Errorf("invalid bitbucket %s %q: must not contain '/'", name, value)
+	case strings.IndexFunc(value, unicode.IsSpace) >= 0:
+		return fmt.Errorf("invalid bitbucket %s %q: must not contain whitespace", name, value)
+	}
+	return nil
+}
+
+// Authenticate checks if the Bitbucket credentials are valid by calling the /user endpoint.
+// Returns nil if authentication is successful, or an error with details otherwise. On Bitbucket
+// Cloud it also records the authenticated account, available via Account.
+func (c *Client) Authenticate(ctx context.Context) error {
+	if c.AccessToken == "" {
+		if c.Email == "" {
+			return errors.New("missing Bitbucket account email")
+		}
+		if c.APIToken == "" {
+			return errors.New("missing Bitbucket API token")
+		}
 	}
 
-	req, err := http.NewRequest("GET", c.BaseURL+"/user", nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", c.api().authURL(c), nil)
 	if err != nil {
 		return fmt.Errorf("failed to create authentication request: %w", err)
 	}
 
-	// ✅ Use email as username and API token as password
-	req.SetBasicAuth(c.Email, c.APIToken)
+	// ✅ Use the bearer token if configured, otherwise email as username and API token as password
+	c.setAuth(req)
 
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
@@ -133,43 +243,70 @@ func (c *Client) Authenticate() error {
 	}
 	defer resp.Body.Close()
 
-	bodyBytes, _ := io.ReadAll(resp.Body)
-	bodyStr := string(bodyBytes)
-
 	switch resp.StatusCode {
 	case http.StatusOK:
+		if !c.isServer() {
+			account, err := decodeAccount(resp.Body)
+			if err != nil {
+				return err
+			}
+			c.account = account
+		}
 		return nil
 	case http.StatusUnauthorized, http.StatusForbidden:
-		return fmt.Errorf("authentication failed: invalid Bitbucket credentials. Response: %s", bodyStr)
+		return fmt.Errorf("authentication failed: invalid Bitbucket credentials: %w", newAPIError(req.URL.String(), resp))
 	default:
-		return fmt.Errorf("authentication failed: Bitbucket API returned status %d. Response: %s",
-			resp.StatusCode, bodyStr)
+		return fmt.Errorf("authentication failed: %w", newAPIError(req.URL.String(), resp))
 	}
 }
 
+// decodeAccount decodes a Bitbucket Cloud /user response.
+func decodeAccount(r io.Reader) (*Account, error) {
+	var user struct {
+		AccountID   string `json:"account_id"`
+		UUID        string `json:"uuid"`
+		Username    string `json:"username"`
+		Nickname    string `json:"nickname"`
+		DisplayName string `json:"display_name"`
+	}
+	if err := json.NewDecoder(r).Decode(&user); err != nil {
+		return nil, fmt.Errorf("failed to decode authenticated user: %w", err)
+	}
+	username := user.Username
+	if username == "" {
+		// Bitbucket Cloud no longer returns usernames for most accounts
+		username = user.Nickname
+	}
+	return &Account{
+		AccountID:   user.AccountID,
+		UUID:        user.UUID,
+		Username:    username,
+		DisplayName: user.DisplayName,
+	}, nil
+}
+
 // GetPRIDByBranch fetches the PR ID associated with the given branch in the workspace/repo.
 // Returns the PR ID as a string, or an error if not found or on failure.
-func (c *Client) GetPRIDByBranch(branch string) (string, error) {
+func (c *Client) GetPRIDByBranch(ctx context.Context, branch string) (string, error) {
 	if branch == "" {
 		return "", errors.New("branch name is required")
 	}
 	if c.RepoSlug == "" {
 		return "", errors.New("repo slug is required")
 	}
-	url := fmt.Sprintf("%s/repositories/%s/%s/pullrequests?q=source.branch.name=\"%s\"&state=OPEN", c.BaseURL, c.Workspace, c.RepoSlug, branch)
-	req, err := http.NewRequest("GET", url, nil)
+	url := c.api().prByBranchURL(c, branch)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return "", fmt.Errorf("failed to create PR lookup request: %w", err)
 	}
-	req.SetBasicAuth(c.Email, c.APIToken)
+	c.setAuth(req)
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return "", fmt.Errorf("failed to contact Bitbucket API: %w", err)
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("failed to fetch PRs: status %d, response: %s", resp.StatusCode, string(body))
+		return "", fmt.Errorf("failed to fetch PRs: %w", newAPIError(req.URL.String(), resp))
 	}
 	type prList struct {
 		Values []struct {
@@ -189,65 +326,462 @@ func (c *Client) GetPRIDByBranch(branch string) (string, error) {
 		return "", fmt.Errorf("failed to decode PR list: %w", err)
 	}
 	if len(prs.Values) == 0 {
-		return "", fmt.Errorf("no open PR found for branch %q", branch)
+		return "", fmt.Errorf("no open PR found for branch %q: %w", branch, ErrNotFound)
 	}
 	return fmt.Sprintf("%d", prs.Values[0].ID), nil
 }
 
 // GetPRMetadata fetches metadata for a given PR ID.
 // Returns the raw JSON response as bytes, or an error.
-func (c *Client) GetPRMetadata(prID string) ([]byte, error) {
+func (c *Client) GetPRMetadata(ctx context.Context, prID string) ([]byte, error) {
 	if prID == "" {
 		return nil, errors.New("PR ID is required")
 	}
 	if c.RepoSlug == "" {
 		return nil, errors.New("repo slug is required")
 	}
-	url := fmt.Sprintf("%s/repositories/%s/%s/pullrequests/%s", c.BaseURL, c.Workspace, c.RepoSlug, prID)
-	req, err := http.NewRequest("GET", url, nil)
+	url := c.api().prURL(c, prID)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create PR metadata request: %w", err)
 	}
-	req.SetBasicAuth(c.Email, c.APIToken)
+	c.setAuth(req)
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to contact Bitbucket API: %w", err)
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("failed to fetch PR metadata: status %d, response: %s", resp.StatusCode, string(body))
+		return nil, fmt.Errorf("failed to fetch PR metadata: %w", newAPIError(req.URL.String(), resp))
 	}
 	return io.ReadAll(resp.Body)
 }
 
+// GetPullRequest fetches and decodes a PR, including its participants, normalizing the Cloud
+// and Server response shapes.
+func (c *Client) GetPullRequest(ctx context.Context, prID string) (*PullRequest, error) {
+	data, err := c.GetPRMetadata(ctx, prID)
+	if err != nil {
+		return nil, err
+	}
+	pr, err := c.api().decodePullRequest(data)
+	if err != nil {
+		return nil, err
+	}
+	if pr.Participants, err = c.api().decodeParticipants(data); err != nil {
+		return nil, err
+	}
+	return pr, nil
+}
+
+// GetPRInfo returns the title and description of a PR.
+func (c *Client) GetPRInfo(ctx context.Context, prID string) (provider.PRInfo, error) {
+	pr, err := c.GetPullRequest(ctx, prID)
+	if err != nil {
+		return provider.PRInfo{}, err
+	}
+	return provider.PRInfo{Title: pr.Title, Description: pr.Description}, nil
+}
+
+// GetPullRequestParticipants fetches the users involved in a PR along with their approval
+// state. Use GetPullRequest's Participants when the PR itself is needed too.
+func (c *Client) GetPullRequestParticipants(ctx context.Context, prID string) ([]Participant, error) {
+	pr, err := c.GetPullRequest(ctx, prID)
+	if err != nil {
+		return nil, err
+	}
+	return pr.Participants, nil
+}
+
+// IsApproved reports whether any participant has approved the PR.
+func IsApproved(participants []Participant) bool {
+	return len(Approvers(participants)) > 0
+}
+
+// Approvers returns the participants who have approved the PR.
+func Approvers(participants []Participant) []Participant {
+	var approvers []Participant
+	for _, p := range participants {
+		if p.Approved {
+			approvers = append(approvers, p)
+		}
+	}
+	return approvers
+}
+
+// UpdatePullRequestReviewers adds reviewers to an existing PR. The current reviewers are fetched
+// first and kept, so existing reviewers are never removed. Each reviewer may be given as a
+// username, an account ID, or a {UUID}.
+func (c *Client) UpdatePullRequestReviewers(ctx context.Context, prID string, usernames []string) error {
+	if c.isServer() {
+		return errors.New("updating reviewers is only supported on Bitbucket Cloud")
+	}
+	if len(usernames) == 0 {
+		return errors.New("at least one reviewer is required")
+	}
+	for _, u := range usernames {
+		if strings.TrimSpace(u) == "" {
+			return errors.New("reviewer usernames must be non-empty")
+		}
+	}
+
+	data, err := c.GetPRMetadata(ctx, prID)
+	if err != nil {
+		return err
+	}
+	var pr struct {
+		Title     string `json:"title"`
+		Reviewers []struct {
+			UUID      string `json:"uuid"`
+			AccountID string `json:"account_id"`
+			Username  string `json:"username"`
+			Nickname  string `json:"nickname"`
+		} `json:"reviewers"`
+	}
+	if err := json.Unmarshal(data, &pr); err != nil {
+		return fmt.Errorf("failed to decode pull request: %w", err)
+	}
+
+	var reviewers []map[string]string
+	known := make(map[string]bool)
+	for _, r := range pr.Reviewers {
+		reviewers = append(reviewers, map[string]string{"uuid": r.UUID})
+		for _, id := range []string{r.UUID, r.AccountID, r.Username, r.Nickname} {
+			if id != "" {
+				known[id] = true
+			}
+		}
+	}
+	for _, u := range usernames {
+		u = strings.TrimSpace(u)
+		if known[u] {
+			continue
+		}
+		known[u] = true
+		reviewers = append(reviewers, reviewerRef(u))
+	}
+
+	// Title is included because Bitbucket Cloud rejects PR updates without it
+	bodyBytes, err := json.Marshal(map[string]interface{}{
+		"title":     pr.Title,
+		"reviewers": reviewers,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal reviewers update: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, "PUT", c.api().prURL(c, prID), bytes.NewReader(bodyBytes))
+	if err != nil {
+		return fmt.Errorf("failed to create reviewers update request: %w", err)
+	}
+	c.setAuth(req)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to update reviewers: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to update reviewers: %w", newAPIError(req.URL.String(), resp))
+	}
+	return nil
+}
+
+// reviewerRef builds the reviewer object for an identifier: a {UUID}, an account ID
+// (which contains a colon), or otherwise a username.
+func reviewerRef(id string) map[string]string {
+	switch {
+	case strings.HasPrefix(id, "{") && strings.HasSuffix(id, "}"):
+		return map[string]string{"uuid": id}
+	case strings.Contains(id, ":"):
+		return map[string]string{"account_id": id}
+	default:
+		return map[string]string{"username": id}
+	}
+}
+
 // GetPRDiff fetches the unified diff for a given PR ID.
-// Returns the diff as a string, or an error.
-func (c *Client) GetPRDiff(prID string) (string, error) {
+// Returns the diff as a string, or an error. If Bitbucket truncated the diff, the error is a
+// *TruncatedDiffError (matching ErrDiffTruncated) and the partial diff is returned as well.
+func (c *Client) GetPRDiff(ctx context.Context, prID string) (string, error) {
 	if prID == "" {
 		return "", errors.New("PR ID is required")
 	}
 	if c.RepoSlug == "" {
 		return "", errors.New("repo slug is required")
 	}
-	url := fmt.Sprintf("%s/repositories/%s/%s/pullrequests/%s/diff", c.BaseURL, c.Workspace, c.RepoSlug, prID)
-	req, err := http.NewRequest("GET", url, nil)
+	url := c.api().diffURL(c, prID)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return "", fmt.Errorf("failed to create PR diff request: %w", err)
 	}
-	req.SetBasicAuth(c.Email, c.APIToken)
+	c.setAuth(req)
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return "", fmt.Errorf("failed to contact Bitbucket API: %w", err)
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("failed to fetch PR diff: status %d, response: %s", resp.StatusCode, string(body))
+		return "", fmt.Errorf("failed to fetch PR diff: %w", newAPIError(req.URL.String(), resp))
 	}
 	diffBytes, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return "", fmt.Errorf("failed to read PR diff: %w", err)
 	}
-	return string(diffBytes), nil
+	diff, truncated := detectTruncatedDiff(string(diffBytes))
+	if truncated {
+		return diff, &TruncatedDiffError{PartialDiff: diff}
+	}
+	return diff, nil
+}
+
+// GetDiffBetween fetches the unified diff from commit base to commit head, e.g. the changes
+// pushed to a PR since an earlier review. Truncation is reported as by GetPRDiff.
+func (c *Client) GetDiffBetween(ctx context.Context, base, head string) (string, error) {
+	if c.isServer() {
+		return "", errors.New("diffs between commits are only supported on Bitbucket Cloud")
+	}
+	if base == "" || head == "" {
+		return "", errors.New("base and head commits are required")
+	}
+	if c.RepoSlug == "" {
+		return "", errors.New("repo slug is required")
+	}
+	// Bitbucket Cloud ranges read "head..base" (the changes in head relative to base);
+	// topic=false asks for a plain two-dot diff instead of one against the merge base, so a
+	// base from before a force-push still yields exactly the changes between the two commits
+	diffURL := fmt.Sprintf("%s/repositories/%s/%s/diff/%s..%s?topic=false", c.BaseURL, c.Workspace, c.RepoSlug,
+		url.PathEscape(head), url.PathEscape(base))
+	req, err := http.NewRequestWithContext(ctx, "GET", diffURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create diff request: %w", err)
+	}
+	c.setAuth(req)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to contact Bitbucket API: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to fetch diff %s..%s: %w", base, head, newAPIError(req.URL.String(), resp))
+	}
+	diffBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read diff: %w", err)
+	}
+	diff, truncated := detectTruncatedDiff(string(diffBytes))
+	if truncated {
+		return diff, &TruncatedDiffError{PartialDiff: diff}
+	}
+	return diff, nil
+}
+
+// GetFileContent fetches the raw content of a file at the given ref. The ref may be a branch
+// name or a commit SHA; pass the PR's source commit (see PullRequest.SourceCommit) to read the
+// file exactly as reviewed, unaffected by later pushes to the branch.
+func (c *Client) GetFileContent(ctx context.Context, ref, path string) ([]byte, error) {
+	if ref == "" || path == "" {
+		return nil, errors.New("ref and file path are required")
+	}
+	if c.RepoSlug == "" {
+		return nil, errors.New("repo slug is required")
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", c.api().fileContentURL(c, ref, path), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create file content request: %w", err)
+	}
+	c.setAuth(req)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to contact Bitbucket API: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch %s at %s: %w", path, ref, newAPIError(req.URL.String(), resp))
+	}
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file content: %w", err)
+	}
+	return content, nil
+}
+
+// DiffStatEntry describes a single file changed in a PR, as reported by the diffstat endpoint.
+type DiffStatEntry struct {
+	Status       string // added, modified, removed, or renamed
+	OldPath      string // Empty for added files
+	NewPath      string // Empty for removed files
+	LinesAdded   int
+	LinesRemoved int
+}
+
+// Path returns the most relevant path for the entry: the new path, or the old path for removed files.
+func (e DiffStatEntry) Path() string {
+	if e.NewPath != "" {
+		return e.NewPath
+	}
+	return e.OldPath
+}
+
+// GetPRDiffstat fetches the per-file change summary for a given PR ID, following pagination.
+// This is much cheaper than GetPRDiff when only the list of changed files is needed.
+func (c *Client) GetPRDiffstat(ctx context.Context, prID string) ([]DiffStatEntry, error) {
+	if c.isServer() {
+		return nil, errors.New("diffstat is only supported on Bitbucket Cloud")
+	}
+	if prID == "" {
+		return nil, errors.New("PR ID is required")
+	}
+	if c.RepoSlug == "" {
+		return nil, errors.New("repo slug is required")
+	}
+	type diffStatPage struct {
+		Values []struct {
+			Status       string `json:"status"`
+			LinesAdded   int    `json:"lines_added"`
+			LinesRemoved int    `json:"lines_removed"`
+			Old          *struct {
+				Path string `json:"path"`
+			} `json:"old"`
+			New *struct {
+				Path string `json:"path"`
+			} `json:"new"`
+		} `json:"values"`
+		Next string `json:"next"`
+	}
+
+	var entries []DiffStatEntry
+	url := fmt.Sprintf("%s/repositories/%s/%s/pullrequests/%s/diffstat", c.BaseURL, c.Workspace, c.RepoSlug, prID)
+	for url != "" {
+		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create PR diffstat request: %w", err)
+		}
+		c.setAuth(req)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to contact Bitbucket API: %w", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			apiErr := newAPIError(req.URL.String(), resp)
+			resp.Body.Close()
+			return nil, fmt.Errorf("failed to fetch PR diffstat: %w", apiErr)
+		}
+		var page diffStatPage
+		err = json.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode PR diffstat: %w", err)
+		}
+		for _, v := range page.Values {
+			entry := DiffStatEntry{
+				Status:       v.Status,
+				LinesAdded:   v.LinesAdded,
+				LinesRemoved: v.LinesRemoved,
+			}
+			if v.Old != nil {
+				entry.OldPath = v.Old.Path
+			}
+			if v.New != nil {
+				entry.NewPath = v.New.Path
+			}
+			entries = append(entries, entry)
+		}
+		url = page.Next
+	}
+	return entries, nil
+}
+
+// PullRequestSummary is a minimal view of a PR as returned by the pull request listing endpoint.
+type PullRequestSummary struct {
+	ID           string
+	Title        string
+	State        string
+	Branch       string // Source branch name
+	SourceCommit string // Head commit of the source branch
+	UpdatedOn    time.Time
+}
+
+// ListPullRequests lists PRs in the given state (OPEN, MERGED, DECLINED, or SUPERSEDED), optionally
+// restricted to those last updated within [since, until). A zero time disables that bound.
+// Follows pagination until all matching PRs are collected.
+func (c *Client) ListPullRequests(ctx context.Context, state string, since, until time.Time) ([]PullRequestSummary, error) {
+	if c.isServer() {
+		return nil, errors.New("listing pull requests is only supported on Bitbucket Cloud")
+	}
+	if c.RepoSlug == "" {
+		return nil, errors.New("repo slug is required")
+	}
+	params := url.Values{}
+	if state != "" {
+		params.Set("state", strings.ToUpper(state))
+	}
+	var filters []string
+	if !since.IsZero() {
+		filters = append(filters, fmt.Sprintf("updated_on >= %s", since.UTC().Format(time.RFC3339)))
+	}
+	if !until.IsZero() {
+		filters = append(filters, fmt.Sprintf("updated_on < %s", until.UTC().Format(time.RFC3339)))
+	}
+	if len(filters) > 0 {
+		params.Set("q", strings.Join(filters, " AND "))
+	}
+	type prPage struct {
+		Values []struct {
+			ID        int       `json:"id"`
+			Title     string    `json:"title"`
+			State     string    `json:"state"`
+			UpdatedOn time.Time `json:"updated_on"`
+			Source    struct {
+				Branch struct {
+					Name string `json:"name"`
+				} `json:"branch"`
+				Commit struct {
+					Hash string `json:"hash"`
+				} `json:"commit"`
+			} `json:"source"`
+		} `json:"values"`
+		Next string `json:"next"`
+	}
+
+	var prs []PullRequestSummary
+	pageURL := fmt.Sprintf("%s/repositories/%s/%s/pullrequests?%s", c.BaseURL, c.Workspace, c.RepoSlug, params.Encode())
+	for pageURL != "" {
+		req, err := http.NewRequestWithContext(ctx, "GET", pageURL, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create PR list request: %w", err)
+		}
+		c.setAuth(req)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to contact Bitbucket API: %w", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			apiErr := newAPIError(req.URL.String(), resp)
+			resp.Body.Close()
+			return nil, fmt.Errorf("failed to list PRs: %w", apiErr)
+		}
+		var page prPage
+		err = json.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode PR list: %w", err)
+		}
+		for _, v := range page.Values {
+			prs = append(prs, PullRequestSummary{
+				ID:           fmt.Sprintf("%d", v.ID),
+				Title:        v.Title,
+				State:        v.State,
+				Branch:       v.Source.Branch.Name,
+				SourceCommit: v.Source.Commit.Hash,
+				UpdatedOn:    v.UpdatedOn,
+			})
+		}
+		pageURL = page.Next
+	}
+	return prs, nil
+}
+
+// ListOpenPullRequests lists every open PR in the repository.
+func (c *Client) ListOpenPullRequests(ctx context.Context) ([]PullRequestSummary, error) {
+	return c.ListPullRequests(ctx, "OPEN", time.Time{}, time.Time{})
 }
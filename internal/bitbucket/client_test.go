@@ -2,9 +2,14 @@ package bitbucket
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"io"
 	"net/http"
+	"net/url"
+	"strings"
 	"testing"
+	"time"
 )
 
 // mockRoundTripper implements http.RoundTripper for testing HTTP requests.
@@ -29,6 +34,27 @@ func (m *mockRoundTripper) RoundTrip(req *http.Request) (*http.Response, error)
 	return resp, nil
 }
 
+// pagedRoundTripper serves canned responses keyed by request URL, for testing paginated endpoints.
+type pagedRoundTripper struct {
+	pages    map[string]string
+	requests []string
+}
+
+func (m *pagedRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	m.requests = append(m.requests, req.URL.String())
+	body, ok := m.pages[req.URL.String()]
+	code := http.StatusOK
+	if !ok {
+		code = http.StatusNotFound
+		body = `{"error": "not found"}`
+	}
+	return &http.Response{
+		StatusCode: code,
+		Body:       io.NopCloser(bytes.NewBufferString(body)),
+		Header:     make(http.Header),
+	}, nil
+}
+
 func TestPostInlineComment_Success(t *testing.T) {
 	mock := &mockRoundTripper{
 		responseCode: http.StatusCreated,
@@ -46,7 +72,7 @@ func TestPostInlineComment_Success(t *testing.T) {
 	http.DefaultClient.Transport = mock
 	defer func() { http.DefaultClient.Transport = origTransport }()
 
-	err := client.PostInlineComment("123", "foo.go", 42, "Test inline comment")
+	err := client.PostInlineComment(context.Background(), "123", PRComment{FilePath: "foo.go", Line: 42, Text: "Test inline comment"})
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
@@ -80,7 +106,7 @@ func TestPostInlineComment_Failure(t *testing.T) {
 	http.DefaultClient.Transport = mock
 	defer func() { http.DefaultClient.Transport = origTransport }()
 
-	err := client.PostInlineComment("123", "foo.go", 42, "Test inline comment")
+	err := client.PostInlineComment(context.Background(), "123", PRComment{FilePath: "foo.go", Line: 42, Text: "Test inline comment"})
 	if err == nil {
 		t.Fatal("expected error, got nil")
 	}
@@ -105,7 +131,7 @@ func TestPostSummaryComment_Success(t *testing.T) {
 	http.DefaultClient.Transport = mock
 	defer func() { http.DefaultClient.Transport = origTransport }()
 
-	err := client.PostSummaryComment("123", "This is a summary comment")
+	err := client.PostSummaryComment(context.Background(), "123", "This is a summary comment")
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
@@ -136,7 +162,7 @@ func TestPostSummaryComment_Failure(t *testing.T) {
 	http.DefaultClient.Transport = mock
 	defer func() { http.DefaultClient.Transport = origTransport }()
 
-	err := client.PostSummaryComment("123", "This is a summary comment")
+	err := client.PostSummaryComment(context.Background(), "123", "This is a summary comment")
 	if err == nil {
 		t.Fatal("expected error, got nil")
 	}
@@ -144,3 +170,514 @@ func TestPostSummaryComment_Failure(t *testing.T) {
 		t.Fatal("expected request to be made")
 	}
 }
+
+func TestGetPRDiffstat_Paged(t *testing.T) {
+	base := "https://api.bitbucket.org/2.0/repositories/ws/repo/pullrequests/7/diffstat"
+	mock := &pagedRoundTripper{
+		pages: map[string]string{
+			base: `{"values": [
+				{"status": "modified", "lines_added": 3, "lines_removed": 1, "old": {"path": "a.go"}, "new": {"path": "a.go"}},
+				{"status": "added", "lines_added": 10, "lines_removed": 0, "old": null, "new": {"path": "b.go"}}
+			], "next": "` + base + `?page=2"}`,
+			base + "?page=2": `{"values": [
+				{"status": "removed", "lines_added": 0, "lines_removed": 4, "old": {"path": "c.go"}, "new": null}
+			]}`,
+		},
+	}
+	client := &Client{
+		Email:     "user@example.com",
+		APIToken:  "token",
+		Workspace: "ws",
+		RepoSlug:  "repo",
+		BaseURL:   "https://api.bitbucket.org/2.0",
+	}
+	origTransport := http.DefaultClient.Transport
+	http.DefaultClient.Transport = mock
+	defer func() { http.DefaultClient.Transport = origTransport }()
+
+	entries, err := client.GetPRDiffstat(context.Background(), "7")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(mock.requests) != 2 {
+		t.Errorf("expected 2 page requests, got %d", len(mock.requests))
+	}
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries, got %d", len(entries))
+	}
+	if entries[0].Status != "modified" || entries[0].Path() != "a.go" || entries[0].LinesAdded != 3 || entries[0].LinesRemoved != 1 {
+		t.Errorf("unexpected first entry: %+v", entries[0])
+	}
+	if entries[1].Status != "added" || entries[1].OldPath != "" || entries[1].Path() != "b.go" {
+		t.Errorf("unexpected second entry: %+v", entries[1])
+	}
+	if entries[2].Status != "removed" || entries[2].NewPath != "" || entries[2].Path() != "c.go" {
+		t.Errorf("unexpected third entry: %+v", entries[2])
+	}
+}
+
+func TestGetPRDiffstat_Failure(t *testing.T) {
+	mock := &pagedRoundTripper{pages: map[string]string{}}
+	client := &Client{
+		Email:     "user@example.com",
+		APIToken:  "token",
+		Workspace: "ws",
+		RepoSlug:  "repo",
+		BaseURL:   "https://api.bitbucket.org/2.0",
+	}
+	origTransport := http.DefaultClient.Transport
+	http.DefaultClient.Transport = mock
+	defer func() { http.DefaultClient.Transport = origTransport }()
+
+	if _, err := client.GetPRDiffstat(context.Background(), "7"); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
+
+func TestGetPRDiff_Truncated(t *testing.T) {
+	partial := "diff --git a/foo.go b/foo.go\n--- a/foo.go\n+++ b/foo.go\n@@ -1,1 +1,2 @@\n line\n+added\n"
+	mock := &mockRoundTripper{
+		responseCode: http.StatusOK,
+		responseBody: partial + "This diff has been truncated because it is too large.\n",
+	}
+	client := &Client{
+		Email:     "user@example.com",
+		APIToken:  "token",
+		Workspace: "ws",
+		RepoSlug:  "repo",
+		BaseURL:   "https://api.bitbucket.org/2.0",
+	}
+	origTransport := http.DefaultClient.Transport
+	http.DefaultClient.Transport = mock
+	defer func() { http.DefaultClient.Transport = origTransport }()
+
+	diff, err := client.GetPRDiff(context.Background(), "123")
+	if !errors.Is(err, ErrDiffTruncated) {
+		t.Fatalf("expected ErrDiffTruncated, got %v", err)
+	}
+	var truncErr *TruncatedDiffError
+	if !errors.As(err, &truncErr) {
+		t.Fatalf("expected *TruncatedDiffError, got %T", err)
+	}
+	if truncErr.PartialDiff != partial {
+		t.Errorf("expected partial diff without notice, got %q", truncErr.PartialDiff)
+	}
+	if diff != partial {
+		t.Errorf("expected partial diff to be returned, got %q", diff)
+	}
+}
+
+func TestGetPRDiff_NotTruncated(t *testing.T) {
+	body := "diff --git a/foo.go b/foo.go\n--- a/foo.go\n+++ b/foo.go\n@@ -1,1 +1,2 @@\n line\n+// Diff truncated here is just code\n"
+	mock := &mockRoundTripper{
+		responseCode: http.StatusOK,
+		responseBody: body,
+	}
+	client := &Client{
+		Email:     "user@example.com",
+		APIToken:  "token",
+		Workspace: "ws",
+		RepoSlug:  "repo",
+		BaseURL:   "https://api.bitbucket.org/2.0",
+	}
+	origTransport := http.DefaultClient.Transport
+	http.DefaultClient.Transport = mock
+	defer func() { http.DefaultClient.Transport = origTransport }()
+
+	diff, err := client.GetPRDiff(context.Background(), "123")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if diff != body {
+		t.Errorf("expected diff to be returned unchanged")
+	}
+}
+
+func TestListPullRequests_MergedInRange(t *testing.T) {
+	since := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	params := url.Values{}
+	params.Set("state", "MERGED")
+	params.Set("q", "updated_on >= 2024-01-01T00:00:00Z AND updated_on < 2024-02-01T00:00:00Z")
+	first := "https://api.bitbucket.org/2.0/repositories/ws/repo/pullrequests?" + params.Encode()
+	mock := &pagedRoundTripper{
+		pages: map[string]string{
+			first: `{"values": [{"id": 5, "title": "Five", "state": "MERGED", "updated_on": "2024-01-10T12:00:00.000000+00:00", "source": {"branch": {"name": "feature/five"}, "commit": {"hash": "abc123"}}}],
+				"next": "https://api.bitbucket.org/2.0/repositories/ws/repo/pullrequests?page=2"}`,
+			"https://api.bitbucket.org/2.0/repositories/ws/repo/pullrequests?page=2": `{"values": [{"id": 9, "title": "Nine", "state": "MERGED", "updated_on": "2024-01-20T08:00:00.000000+00:00"}]}`,
+		},
+	}
+	client := &Client{
+		Email:     "user@example.com",
+		APIToken:  "token",
+		Workspace: "ws",
+		RepoSlug:  "repo",
+		BaseURL:   "https://api.bitbucket.org/2.0",
+	}
+	origTransport := http.DefaultClient.Transport
+	http.DefaultClient.Transport = mock
+	defer func() { http.DefaultClient.Transport = origTransport }()
+
+	prs, err := client.ListPullRequests(context.Background(), "merged", since, until)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(prs) != 2 {
+		t.Fatalf("expected 2 PRs, got %d (requests: %v)", len(prs), mock.requests)
+	}
+	if prs[0].ID != "5" || prs[0].Title != "Five" || prs[0].Branch != "feature/five" || prs[0].SourceCommit != "abc123" {
+		t.Errorf("unexpected first PR: %+v", prs[0])
+	}
+	if prs[1].ID != "9" || prs[1].UpdatedOn.Day() != 20 {
+		t.Errorf("unexpected second PR: %+v", prs[1])
+	}
+}
+
+func TestAuthenticate_BasicAuth(t *testing.T) {
+	mock := &mockRoundTripper{
+		responseCode: http.StatusOK,
+		responseBody: `{"username": "me"}`,
+	}
+	client := &Client{
+		Email:     "user@example.com",
+		APIToken:  "token",
+		Workspace: "ws",
+		RepoSlug:  "repo",
+		BaseURL:   "https://api.bitbucket.org/2.0",
+	}
+	origTransport := http.DefaultClient.Transport
+	http.DefaultClient.Transport = mock
+	defer func() { http.DefaultClient.Transport = origTransport }()
+
+	if err := client.Authenticate(context.Background()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	user, pass, ok := mock.lastRequest.BasicAuth()
+	if !ok || user != "user@example.com" || pass != "token" {
+		t.Errorf("expected basic auth with email and API token, got %q", mock.lastRequest.Header.Get("Authorization"))
+	}
+}
+
+func TestAuthenticate_BearerToken(t *testing.T) {
+	mock := &mockRoundTripper{
+		responseCode: http.StatusOK,
+		responseBody: `{"username": "me"}`,
+	}
+	// No email or API token: the access token alone is enough
+	client := &Client{
+		AccessToken: "oauth-token",
+		Workspace:   "ws",
+		RepoSlug:    "repo",
+		BaseURL:     "https://api.bitbucket.org/2.0",
+	}
+	origTransport := http.DefaultClient.Transport
+	http.DefaultClient.Transport = mock
+	defer func() { http.DefaultClient.Transport = origTransport }()
+
+	if err := client.Authenticate(context.Background()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if got := mock.lastRequest.Header.Get("Authorization"); got != "Bearer oauth-token" {
+		t.Errorf("expected bearer header, got %q", got)
+	}
+
+	// Other requests use the bearer token too
+	mock.responseCode = http.StatusCreated
+	if err := client.PostSummaryComment(context.Background(), "1", "hi"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if got := mock.lastRequest.Header.Get("Authorization"); got != "Bearer oauth-token" {
+		t.Errorf("expected bearer header on comment request, got %q", got)
+	}
+}
+
+// hangingRoundTripper blocks until the request context is done, simulating an unresponsive server.
+type hangingRoundTripper struct{}
+
+func (hangingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	<-req.Context().Done()
+	return nil, req.Context().Err()
+}
+
+func TestClientMethods_HonorContextDeadline(t *testing.T) {
+	client := &Client{
+		Email:     "user@example.com",
+		APIToken:  "token",
+		Workspace: "ws",
+		RepoSlug:  "repo",
+		BaseURL:   "https://api.bitbucket.org/2.0",
+	}
+	origTransport := http.DefaultClient.Transport
+	http.DefaultClient.Transport = hangingRoundTripper{}
+	defer func() { http.DefaultClient.Transport = origTransport }()
+
+	calls := map[string]func(ctx context.Context) error{
+		"Authenticate":    func(ctx context.Context) error { return client.Authenticate(ctx) },
+		"GetPRIDByBranch": func(ctx context.Context) error { _, err := client.GetPRIDByBranch(ctx, "feature"); return err },
+		"GetPRMetadata":   func(ctx context.Context) error { _, err := client.GetPRMetadata(ctx, "1"); return err },
+		"GetPRDiff":       func(ctx context.Context) error { _, err := client.GetPRDiff(ctx, "1"); return err },
+		"PostInline": func(ctx context.Context) error {
+			return client.PostInlineComment(ctx, "1", PRComment{FilePath: "a.go", Line: 1, Text: "x"})
+		},
+		"PostSummary": func(ctx context.Context) error { return client.PostSummaryComment(ctx, "1", "x") },
+	}
+	for name, call := range calls {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		err := call(ctx)
+		cancel()
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("%s: expected context.DeadlineExceeded, got %v", name, err)
+		}
+	}
+}
+
+func TestAuthenticate_RecordsAccount(t *testing.T) {
+	mock := &mockRoundTripper{
+		responseCode: http.StatusOK,
+		responseBody: `{
+			"display_name": "Review Bot",
+			"uuid": "{b7c2a1e0-0000-4000-8000-000000000001}",
+			"account_id": "557058:abcd",
+			"nickname": "reviewbot",
+			"type": "user"
+		}`,
+	}
+	client := &Client{
+		Email:     "user@example.com",
+		APIToken:  "token",
+		Workspace: "ws",
+		RepoSlug:  "repo",
+		BaseURL:   "https://api.bitbucket.org/2.0",
+	}
+	origTransport := http.DefaultClient.Transport
+	http.DefaultClient.Transport = mock
+	defer func() { http.DefaultClient.Transport = origTransport }()
+
+	if client.Account() != nil {
+		t.Fatal("expected no account before authenticating")
+	}
+	if err := client.Authenticate(context.Background()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	want := Account{
+		AccountID:   "557058:abcd",
+		UUID:        "{b7c2a1e0-0000-4000-8000-000000000001}",
+		Username:    "reviewbot",
+		DisplayName: "Review Bot",
+	}
+	if got := client.Account(); got == nil || *got != want {
+		t.Errorf("unexpected account %+v, want %+v", got, want)
+	}
+}
+
+func TestPostInlineComment_Anchors(t *testing.T) {
+	mock := &mockRoundTripper{responseCode: http.StatusCreated, responseBody: `{"id": 1}`}
+	origTransport := http.DefaultClient.Transport
+	http.DefaultClient.Transport = mock
+	defer func() { http.DefaultClient.Transport = origTransport }()
+	client := &Client{Workspace: "ws", RepoSlug: "repo", BaseURL: "https://api.bitbucket.org/2.0"}
+
+	tests := []struct {
+		name    string
+		comment PRComment
+		want    string
+	}{
+		{"new line", PRComment{FilePath: "a.go", Line: 5, Text: "x"}, `{"content":{"raw":"x"},"inline":{"path":"a.go","to":5}}`},
+		{"deleted line", PRComment{FilePath: "a.go", FromLine: 7, Text: "x"}, `{"content":{"raw":"x"},"inline":{"from":7,"path":"a.go"}}`},
+	}
+	for _, tt := range tests {
+		if err := client.PostInlineComment(context.Background(), "1", tt.comment); err != nil {
+			t.Fatalf("%s: expected no error, got %v", tt.name, err)
+		}
+		if string(mock.lastBody) != tt.want {
+			t.Errorf("%s: expected body %s, got %s", tt.name, tt.want, mock.lastBody)
+		}
+	}
+
+	if err := client.PostInlineComment(context.Background(), "1", PRComment{FilePath: "a.go", Text: "x"}); err == nil {
+		t.Error("expected error when neither Line nor FromLine is set")
+	}
+}
+
+func TestGetFileContent_AtCommit(t *testing.T) {
+	mock := &mockRoundTripper{responseCode: http.StatusOK, responseBody: "package main\n"}
+	origTransport := http.DefaultClient.Transport
+	http.DefaultClient.Transport = mock
+	defer func() { http.DefaultClient.Transport = origTransport }()
+	client := &Client{Workspace: "ws", RepoSlug: "repo", BaseURL: "https://api.bitbucket.org/2.0"}
+
+	sha := "4f2c9e1a7b3d"
+	content, err := client.GetFileContent(context.Background(), sha, "cmd/my app/main.go")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if string(content) != "package main\n" {
+		t.Errorf("unexpected content %q", content)
+	}
+	want := "https://api.bitbucket.org/2.0/repositories/ws/repo/src/4f2c9e1a7b3d/cmd/my%20app/main.go"
+	if got := mock.lastRequest.URL.String(); got != want {
+		t.Errorf("expected URL %s, got %s", want, got)
+	}
+
+	mock.responseCode = http.StatusNotFound
+	if _, err := client.GetFileContent(context.Background(), sha, "missing.go"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestUpdatePullRequestReviewers_MergesThenPuts(t *testing.T) {
+	mock := &mockRoundTripper{
+		responseCode: http.StatusOK,
+		responseBody: `{
+			"id": 7,
+			"title": "Fix login",
+			"reviewers": [
+				{"uuid": "{aaaa}", "account_id": "557058:alice", "nickname": "alice"}
+			]
+		}`,
+	}
+	origTransport := http.DefaultClient.Transport
+	http.DefaultClient.Transport = mock
+	defer func() { http.DefaultClient.Transport = origTransport }()
+	client := &Client{Workspace: "ws", RepoSlug: "repo", BaseURL: "https://api.bitbucket.org/2.0"}
+
+	err := client.UpdatePullRequestReviewers(context.Background(), "7", []string{"alice", "557058:bob", "{cccc}", "dave"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if mock.lastRequest.Method != "PUT" || mock.lastRequest.URL.Path != "/2.0/repositories/ws/repo/pullrequests/7" {
+		t.Fatalf("expected PUT to the PR, got %s %s", mock.lastRequest.Method, mock.lastRequest.URL.Path)
+	}
+	want := `{"reviewers":[{"uuid":"{aaaa}"},{"account_id":"557058:bob"},{"uuid":"{cccc}"},{"username":"dave"}],"title":"Fix login"}`
+	if string(mock.lastBody) != want {
+		t.Errorf("unexpected PUT body:\n got %s\nwant %s", mock.lastBody, want)
+	}
+}
+
+func TestUpdatePullRequestReviewers_Validation(t *testing.T) {
+	client := &Client{Workspace: "ws", RepoSlug: "repo", BaseURL: "https://api.bitbucket.org/2.0"}
+	if err := client.UpdatePullRequestReviewers(context.Background(), "7", nil); err == nil {
+		t.Error("expected error for no reviewers")
+	}
+	if err := client.UpdatePullRequestReviewers(context.Background(), "7", []string{"alice", " "}); err == nil {
+		t.Error("expected error for blank reviewer")
+	}
+}
+
+func TestUpdatePullRequestReviewers_APIError(t *testing.T) {
+	mock := &mockRoundTripper{responseCode: http.StatusBadRequest, responseBody: `{"error": {"message": "reviewer not found"}}`}
+	origTransport := http.DefaultClient.Transport
+	http.DefaultClient.Transport = mock
+	defer func() { http.DefaultClient.Transport = origTransport }()
+	client := &Client{Workspace: "ws", RepoSlug: "repo", BaseURL: "https://api.bitbucket.org/2.0"}
+
+	err := client.UpdatePullRequestReviewers(context.Background(), "7", []string{"ghost"})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || !strings.Contains(apiErr.Body, "reviewer not found") {
+		t.Errorf("expected API error body to be returned, got %v", err)
+	}
+}
+
+func TestClient_Validate(t *testing.T) {
+	tests := []struct {
+		name          string
+		workspace     string
+		repoSlug      string
+		wantErr       bool
+		wantWorkspace string
+		wantRepoSlug  string
+	}{
+		{name: "valid", workspace: "my-team", repoSlug: "my_repo.v2", wantWorkspace: "my-team", wantRepoSlug: "my_repo.v2"},
+		{name: "trims whitespace", workspace: "  my-team\n", repoSlug: "\trepo ", wantWorkspace: "my-team", wantRepoSlug: "repo"},
+		{name: "empty repo slug allowed", workspace: "my-team", wantWorkspace: "my-team"},
+		{name: "missing workspace", workspace: "  ", repoSlug: "repo", wantErr: true},
+		{name: "workspace URL", workspace: "https://bitbucket.org/my-team", repoSlug: "repo", wantErr: true},
+		{name: "repo slug URL", workspace: "my-team", repoSlug: "https://bitbucket.org/my-team/repo", wantErr: true},
+		{name: "protocol without slash", workspace: "my-team", repoSlug: "git://repo", wantErr: true},
+		{name: "trailing slash", workspace: "my-team/", repoSlug: "repo", wantErr: true},
+		{name: "workspace/repo path", workspace: "my-team", repoSlug: "my-team/repo", wantErr: true},
+		{name: "inner whitespace", workspace: "my team", repoSlug: "repo", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewClient("user@example.com", "token", tt.workspace, tt.repoSlug, "")
+			err := client.Validate()
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected error for workspace %q, repo slug %q", tt.workspace, tt.repoSlug)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if client.Workspace != tt.wantWorkspace || client.RepoSlug != tt.wantRepoSlug {
+				t.Errorf("expected %q/%q, got %q/%q", tt.wantWorkspace, tt.wantRepoSlug, client.Workspace, client.RepoSlug)
+			}
+		})
+	}
+}
+
+func TestListOpenPullRequests(t *testing.T) {
+	first := "https://api.bitbucket.org/2.0/repositories/ws/repo/pullrequests?state=OPEN"
+	mock := &pagedRoundTripper{
+		pages: map[string]string{
+			first: `{"values": [{"id": 3, "title": "Three", "state": "OPEN", "source": {"branch": {"name": "feature/three"}}}],
+				"next": "https://api.bitbucket.org/2.0/repositories/ws/repo/pullrequests?page=2"}`,
+			"https://api.bitbucket.org/2.0/repositories/ws/repo/pullrequests?page=2": `{"values": [{"id": 4, "title": "Four", "state": "OPEN"}]}`,
+		},
+	}
+	client := &Client{
+		Email:     "user@example.com",
+		APIToken:  "token",
+		Workspace: "ws",
+		RepoSlug:  "repo",
+		BaseURL:   "https://api.bitbucket.org/2.0",
+	}
+	origTransport := http.DefaultClient.Transport
+	http.DefaultClient.Transport = mock
+	defer func() { http.DefaultClient.Transport = origTransport }()
+
+	prs, err := client.ListOpenPullRequests(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(prs) != 2 || prs[0].ID != "3" || prs[0].Branch != "feature/three" || prs[1].ID != "4" {
+		t.Errorf("unexpected PRs: %+v (requests: %v)", prs, mock.requests)
+	}
+}
+
+func TestGetDiffBetween(t *testing.T) {
+	diff := "diff --git a/foo.go b/foo.go\n--- a/foo.go\n+++ b/foo.go\n@@ -1,1 +1,2 @@\n line\n+added\n"
+	mock := &pagedRoundTripper{
+		pages: map[string]string{
+			"https://api.bitbucket.org/2.0/repositories/ws/repo/diff/def456..abc123?topic=false": diff,
+		},
+	}
+	client := &Client{
+		Email:     "user@example.com",
+		APIToken:  "token",
+		Workspace: "ws",
+		RepoSlug:  "repo",
+		BaseURL:   "https://api.bitbucket.org/2.0",
+	}
+	origTransport := http.DefaultClient.Transport
+	http.DefaultClient.Transport = mock
+	defer func() { http.DefaultClient.Transport = origTransport }()
+
+	got, err := client.GetDiffBetween(context.Background(), "abc123", "def456")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if got != diff {
+		t.Errorf("unexpected diff %q", got)
+	}
+	if _, err := client.GetDiffBetween(context.Background(), "abc123", "missing"); err == nil {
+		t.Error("expected an error for an unknown commit")
+	}
+
+	client.Kind = KindServer
+	if _, err := client.GetDiffBetween(context.Background(), "abc123", "def456"); err == nil {
+		t.Error("expected an error on Bitbucket Server")
+	}
+}
@@ -0,0 +1,249 @@
+package bitbucket
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ExistingComment is a comment already present on a PR.
+type ExistingComment struct {
+	ID       string
+	Text     string // Raw markdown body
+	FilePath string // Empty for top-level comments
+	Line     int    // New-file line for inline comments; 0 otherwise
+	FromLine int    // Old-file line for comments on deleted lines; 0 otherwise
+	Resolved bool
+	AuthorID string // Account ID of the comment author
+}
+
+// ListComments fetches all non-deleted comments on a PR, following pagination.
+func (c *Client) ListComments(ctx context.Context, prID string) ([]ExistingComment, error) {
+	if c.isServer() {
+		return nil, errors.New("listing comments is only supported on Bitbucket Cloud")
+	}
+	if prID == "" {
+		return nil, errors.New("PR ID is required")
+	}
+	if c.RepoSlug == "" {
+		return nil, errors.New("repo slug is required")
+	}
+	type commentPage struct {
+		Values []struct {
+			ID      int  `json:"id"`
+			Deleted bool `json:"deleted"`
+			Content struct {
+				Raw string `json:"raw"`
+			} `json:"content"`
+			Inline *struct {
+				Path string `json:"path"`
+				To   *int   `json:"to"`
+				From *int   `json:"from"`
+			} `json:"inline"`
+			Resolution *struct{} `json:"resolution"`
+			User       struct {
+				AccountID string `json:"account_id"`
+			} `json:"user"`
+		} `json:"values"`
+		Next string `json:"next"`
+	}
+
+	var comments []ExistingComment
+	params := url.Values{}
+	params.Set("pagelen", "100")
+	pageURL := c.api().commentsURL(c, prID) + "?" + params.Encode()
+	for pageURL != "" {
+		req, err := http.NewRequestWithContext(ctx, "GET", pageURL, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create comment list request: %w", err)
+		}
+		c.setAuth(req)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to contact Bitbucket API: %w", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			apiErr := newAPIError(req.URL.String(), resp)
+			resp.Body.Close()
+			return nil, fmt.Errorf("failed to list comments: %w", apiErr)
+		}
+		var page commentPage
+		err = json.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode comment list: %w", err)
+		}
+		for _, v := range page.Values {
+			if v.Deleted {
+				continue
+			}
+			comment := ExistingComment{
+				ID:       fmt.Sprintf("%d", v.ID),
+				Text:     v.Content.Raw,
+				Resolved: v.Resolution != nil,
+				AuthorID: v.User.AccountID,
+			}
+			if v.Inline != nil {
+				comment.FilePath = v.Inline.Path
+				if v.Inline.To != nil {
+					comment.Line = *v.Inline.To
+				} else if v.Inline.From != nil {
+					comment.FromLine = *v.Inline.From
+				}
+			}
+			comments = append(comments, comment)
+		}
+		pageURL = page.Next
+	}
+	return comments, nil
+}
+
+// UpdateComment replaces the body of an existing PR comment.
+func (c *Client) UpdateComment(ctx context.Context, prID, commentID, text string) error {
+	if c.isServer() {
+		return errors.New("updating comments is only supported on Bitbucket Cloud")
+	}
+	if prID == "" || commentID == "" || text == "" {
+		return errors.New("missing required fields for comment update")
+	}
+	bodyBytes, err := json.Marshal(c.api().summaryCommentBody(text))
+	if err != nil {
+		return fmt.Errorf("failed to marshal comment update: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, "PUT", c.api().commentsURL(c, prID)+"/"+commentID, bytes.NewReader(bodyBytes))
+	if err != nil {
+		return fmt.Errorf("failed to create comment update request: %w", err)
+	}
+	c.setAuth(req)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to update comment: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to update comment: %w", newAPIError(req.URL.String(), resp))
+	}
+	return nil
+}
+
+// ResolveComment marks a PR comment thread as resolved.
+func (c *Client) ResolveComment(ctx context.Context, prID, commentID string) error {
+	if c.isServer() {
+		return errors.New("resolving comments is only supported on Bitbucket Cloud")
+	}
+	if prID == "" || commentID == "" {
+		return errors.New("missing required fields for comment resolution")
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", c.api().commentsURL(c, prID)+"/"+commentID+"/resolve", nil)
+	if err != nil {
+		return fmt.Errorf("failed to create comment resolve request: %w", err)
+	}
+	c.setAuth(req)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to resolve comment: %w", err)
+	}
+	defer resp.Body.Close()
+	// 409 means the comment is already resolved
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusConflict {
+		return fmt.Errorf("failed to resolve comment: %w", newAPIError(req.URL.String(), resp))
+	}
+	return nil
+}
+
+// DefaultPostConcurrency is the number of comments PostInlineComments posts in parallel by default.
+const DefaultPostConcurrency = 4
+
+// PostInlineComments posts the given inline comments using up to workers concurrent requests
+// (DefaultPostConcurrency if workers <= 0), giving each request its own timeout of callTimeout
+// (none if callTimeout <= 0). Every comment is attempted even if others fail; the returned slice
+// holds the error for each comment at its input index, or nil on success.
+func (c *Client) PostInlineComments(ctx context.Context, prID string, comments []PRComment, workers int, callTimeout time.Duration) []error {
+	if workers <= 0 {
+		workers = DefaultPostConcurrency
+	}
+	errs := make([]error, len(comments))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers && w < len(comments); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				callCtx, cancel := ctx, context.CancelFunc(func() {})
+				if callTimeout > 0 {
+					callCtx, cancel = context.WithTimeout(ctx, callTimeout)
+				}
+				errs[i] = c.PostInlineComment(callCtx, prID, comments[i])
+				cancel()
+			}
+		}()
+	}
+	for i := range comments {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return errs
+}
+
+// DeleteComment deletes a PR comment.
+func (c *Client) DeleteComment(ctx context.Context, prID, commentID string) error {
+	if c.isServer() {
+		return errors.New("deleting comments is only supported on Bitbucket Cloud")
+	}
+	if prID == "" || commentID == "" {
+		return errors.New("missing required fields for comment deletion")
+	}
+	req, err := http.NewRequestWithContext(ctx, "DELETE", c.api().commentsURL(c, prID)+"/"+commentID, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create comment delete request: %w", err)
+	}
+	c.setAuth(req)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to delete comment: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to delete comment: %w", newAPIError(req.URL.String(), resp))
+	}
+	return nil
+}
+
+// createComment posts a top-level comment and returns its ID.
+func (c *Client) createComment(ctx context.Context, prID, text string) (string, error) {
+	bodyBytes, err := json.Marshal(c.api().summaryCommentBody(text))
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal comment: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", c.api().commentsURL(c, prID), bytes.NewReader(bodyBytes))
+	if err != nil {
+		return "", fmt.Errorf("failed to create comment request: %w", err)
+	}
+	c.setAuth(req)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to post comment: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		return "", fmt.Errorf("failed to post comment: %w", newAPIError(req.URL.String(), resp))
+	}
+	var created struct {
+		ID int `json:"id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		return "", fmt.Errorf("failed to decode created comment: %w", err)
+	}
+	return strconv.Itoa(created.ID), nil
+}
@@ -0,0 +1,169 @@
+package bitbucket
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newCloudClient() *Client {
+	return &Client{
+		Email:     "user@example.com",
+		APIToken:  "token",
+		Workspace: "ws",
+		RepoSlug:  "repo",
+		BaseURL:   "https://api.bitbucket.org/2.0",
+	}
+}
+
+func TestListComments_Paged(t *testing.T) {
+	base := "https://api.bitbucket.org/2.0/repositories/ws/repo/pullrequests/7/comments"
+	mock := &pagedRoundTripper{pages: map[string]string{
+		base + "?pagelen=100": `{
+			"values": [
+				{"id": 1, "content": {"raw": "inline"}, "inline": {"path": "a.go", "to": 12}, "user": {"account_id": "557058:abcd"}},
+				{"id": 2, "content": {"raw": "deleted"}, "deleted": true}
+			],
+			"next": "` + base + `?page=2"
+		}`,
+		base + "?page=2": `{
+			"values": [
+				{"id": 3, "content": {"raw": "top-level"}, "resolution": {"type": "resolved"}}
+			]
+		}`,
+	}}
+	origTransport := http.DefaultClient.Transport
+	http.DefaultClient.Transport = mock
+	defer func() { http.DefaultClient.Transport = origTransport }()
+
+	comments, err := newCloudClient().ListComments(context.Background(), "7")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(comments) != 2 {
+		t.Fatalf("expected 2 non-deleted comments, got %d: %+v", len(comments), comments)
+	}
+	if c := comments[0]; c.ID != "1" || c.FilePath != "a.go" || c.Line != 12 || c.Resolved || c.AuthorID != "557058:abcd" {
+		t.Errorf("unexpected inline comment %+v", c)
+	}
+	if c := comments[1]; c.ID != "3" || c.FilePath != "" || !c.Resolved {
+		t.Errorf("unexpected top-level comment %+v", c)
+	}
+}
+
+func TestUpdateComment(t *testing.T) {
+	mock := &mockRoundTripper{responseCode: http.StatusOK, responseBody: `{}`}
+	origTransport := http.DefaultClient.Transport
+	http.DefaultClient.Transport = mock
+	defer func() { http.DefaultClient.Transport = origTransport }()
+
+	if err := newCloudClient().UpdateComment(context.Background(), "7", "3", "new text"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if mock.lastRequest.Method != "PUT" || mock.lastRequest.URL.Path != "/2.0/repositories/ws/repo/pullrequests/7/comments/3" {
+		t.Errorf("unexpected request %s %s", mock.lastRequest.Method, mock.lastRequest.URL.Path)
+	}
+	if string(mock.lastBody) != `{"content":{"raw":"new text"}}` {
+		t.Errorf("unexpected body %s", mock.lastBody)
+	}
+}
+
+func TestResolveComment(t *testing.T) {
+	mock := &mockRoundTripper{responseCode: http.StatusOK, responseBody: `{}`}
+	origTransport := http.DefaultClient.Transport
+	http.DefaultClient.Transport = mock
+	defer func() { http.DefaultClient.Transport = origTransport }()
+
+	client := newCloudClient()
+	if err := client.ResolveComment(context.Background(), "7", "3"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if mock.lastRequest.Method != "POST" || mock.lastRequest.URL.Path != "/2.0/repositories/ws/repo/pullrequests/7/comments/3/resolve" {
+		t.Errorf("unexpected request %s %s", mock.lastRequest.Method, mock.lastRequest.URL.Path)
+	}
+
+	// Already resolved is not an error
+	mock.responseCode = http.StatusConflict
+	if err := client.ResolveComment(context.Background(), "7", "3"); err != nil {
+		t.Errorf("expected 409 to be ignored, got %v", err)
+	}
+	mock.responseCode = http.StatusNotFound
+	if err := client.ResolveComment(context.Background(), "7", "3"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+// countingRoundTripper records requests concurrently and fails those whose body contains "fail".
+type countingRoundTripper struct {
+	mu       sync.Mutex
+	bodies   []string
+	inFlight int
+	maxSeen  int
+}
+
+func (m *countingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, _ := io.ReadAll(req.Body)
+	m.mu.Lock()
+	m.bodies = append(m.bodies, string(body))
+	m.inFlight++
+	if m.inFlight > m.maxSeen {
+		m.maxSeen = m.inFlight
+	}
+	m.mu.Unlock()
+
+	time.Sleep(5 * time.Millisecond)
+
+	m.mu.Lock()
+	m.inFlight--
+	m.mu.Unlock()
+	code := http.StatusCreated
+	if strings.Contains(string(body), "fail") {
+		code = http.StatusBadRequest
+	}
+	return &http.Response{
+		StatusCode: code,
+		Body:       io.NopCloser(strings.NewReader(`{}`)),
+		Header:     make(http.Header),
+	}, nil
+}
+
+func TestPostInlineComments_AttemptsAllDespiteFailures(t *testing.T) {
+	mock := &countingRoundTripper{}
+	origTransport := http.DefaultClient.Transport
+	http.DefaultClient.Transport = mock
+	defer func() { http.DefaultClient.Transport = origTransport }()
+
+	var comments []PRComment
+	for i := 1; i <= 10; i++ {
+		text := "ok"
+		if i%3 == 0 {
+			text = "fail"
+		}
+		comments = append(comments, PRComment{FilePath: "a.go", Line: i, Text: text})
+	}
+	errs := newCloudClient().PostInlineComments(context.Background(), "7", comments, 2, time.Second)
+
+	if len(mock.bodies) != len(comments) {
+		t.Errorf("expected all %d comments to be attempted, got %d", len(comments), len(mock.bodies))
+	}
+	if mock.maxSeen > 2 {
+		t.Errorf("expected at most 2 concurrent requests, saw %d", mock.maxSeen)
+	}
+	if len(errs) != len(comments) {
+		t.Fatalf("expected one result per comment, got %d", len(errs))
+	}
+	for i, err := range errs {
+		wantFail := comments[i].Text == "fail"
+		if wantFail && err == nil {
+			t.Errorf("comment %d: expected error", i)
+		}
+		if !wantFail && err != nil {
+			t.Errorf("comment %d: unexpected error %v", i, err)
+		}
+	}
+}
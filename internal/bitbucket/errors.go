@@ -0,0 +1,57 @@
+package bitbucket
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Sentinel errors matched by *APIError via errors.Is, so callers can react to the kind of
+// failure without inspecting status codes.
+var (
+	ErrNotFound     = errors.New("bitbucket resource not found")
+	ErrUnauthorized = errors.New("bitbucket credentials rejected")
+	ErrForbidden    = errors.New("bitbucket access denied")
+	ErrRateLimited  = errors.New("bitbucket rate limit exceeded")
+)
+
+// maxErrorBodyBytes caps how much of an error response body is kept on an APIError.
+const maxErrorBodyBytes = 4096
+
+// APIError describes a non-successful response from the Bitbucket API.
+type APIError struct {
+	StatusCode int
+	Body       string // Response body, truncated to maxErrorBodyBytes
+	Endpoint   string // Request URL
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("status %d from %s, response: %s", e.StatusCode, e.Endpoint, e.Body)
+}
+
+// Unwrap maps the status code onto one of the sentinel errors, or nil if none applies.
+func (e *APIError) Unwrap() error {
+	switch e.StatusCode {
+	case http.StatusNotFound:
+		return ErrNotFound
+	case http.StatusUnauthorized:
+		return ErrUnauthorized
+	case http.StatusForbidden:
+		return ErrForbidden
+	case http.StatusTooManyRequests:
+		return ErrRateLimited
+	default:
+		return nil
+	}
+}
+
+// newAPIError builds an *APIError from an unexpected response, consuming (part of) its body.
+func newAPIError(endpoint string, resp *http.Response) *APIError {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodyBytes))
+	return &APIError{
+		StatusCode: resp.StatusCode,
+		Body:       string(body),
+		Endpoint:   endpoint,
+	}
+}
@@ -0,0 +1,90 @@
+package bitbucket
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestAPIError_Is(t *testing.T) {
+	tests := []struct {
+		status int
+		want   error
+	}{
+		{http.StatusNotFound, ErrNotFound},
+		{http.StatusUnauthorized, ErrUnauthorized},
+		{http.StatusForbidden, ErrForbidden},
+		{http.StatusTooManyRequests, ErrRateLimited},
+	}
+	for _, tt := range tests {
+		err := &APIError{StatusCode: tt.status}
+		if !errors.Is(err, tt.want) {
+			t.Errorf("status %d: expected errors.Is(%v)", tt.status, tt.want)
+		}
+	}
+
+	err := &APIError{StatusCode: http.StatusInternalServerError}
+	for _, sentinel := range []error{ErrNotFound, ErrUnauthorized, ErrForbidden, ErrRateLimited} {
+		if errors.Is(err, sentinel) {
+			t.Errorf("status 500 should not match %v", sentinel)
+		}
+	}
+}
+
+func TestClientMethods_ReturnTypedErrors(t *testing.T) {
+	client := &Client{
+		Email:     "user@example.com",
+		APIToken:  "token",
+		Workspace: "ws",
+		RepoSlug:  "repo",
+		BaseURL:   "https://api.bitbucket.org/2.0",
+	}
+	mock := &mockRoundTripper{responseCode: http.StatusNotFound, responseBody: `{"error": "not found"}`}
+	origTransport := http.DefaultClient.Transport
+	http.DefaultClient.Transport = mock
+	defer func() { http.DefaultClient.Transport = origTransport }()
+
+	calls := map[string]func() error{
+		"GetPRMetadata": func() error { _, err := client.GetPRMetadata(context.Background(), "1"); return err },
+		"GetPRDiff":     func() error { _, err := client.GetPRDiff(context.Background(), "1"); return err },
+		"PostInline": func() error {
+			return client.PostInlineComment(context.Background(), "1", PRComment{FilePath: "a.go", Line: 1, Text: "x"})
+		},
+		"PostSummary": func() error { return client.PostSummaryComment(context.Background(), "1", "x") },
+	}
+	for name, call := range calls {
+		err := call()
+		if !errors.Is(err, ErrNotFound) {
+			t.Errorf("%s: expected ErrNotFound, got %v", name, err)
+		}
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) {
+			t.Fatalf("%s: expected *APIError, got %T", name, err)
+		}
+		if apiErr.StatusCode != http.StatusNotFound || apiErr.Body != `{"error": "not found"}` {
+			t.Errorf("%s: unexpected APIError %+v", name, apiErr)
+		}
+		if apiErr.Endpoint != mock.lastRequest.URL.String() {
+			t.Errorf("%s: expected endpoint %q, got %q", name, mock.lastRequest.URL.String(), apiErr.Endpoint)
+		}
+	}
+
+	mock.responseCode = http.StatusUnauthorized
+	if err := client.Authenticate(context.Background()); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("Authenticate: expected ErrUnauthorized, got %v", err)
+	}
+}
+
+func TestGetPRIDByBranch_NoOpenPR(t *testing.T) {
+	client := &Client{Workspace: "ws", RepoSlug: "repo", BaseURL: "https://api.bitbucket.org/2.0"}
+	mock := &mockRoundTripper{responseCode: http.StatusOK, responseBody: `{"values": []}`}
+	origTransport := http.DefaultClient.Transport
+	http.DefaultClient.Transport = mock
+	defer func() { http.DefaultClient.Transport = origTransport }()
+
+	_, err := client.GetPRIDByBranch(context.Background(), "feature")
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
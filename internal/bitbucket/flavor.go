@@ -0,0 +1,320 @@
+package bitbucket
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Supported Bitbucket deployment kinds.
+const (
+	KindCloud  = "cloud"  // Bitbucket Cloud (api.bitbucket.org/2.0)
+	KindServer = "server" // Bitbucket Server / Data Center (/rest/api/1.0)
+)
+
+// PullRequest holds the PR fields pullreview cares about, decoded from either API flavor.
+type PullRequest struct {
+	ID                int
+	Title             string
+	Description       string
+	State             string
+	SourceBranch      string
+	SourceCommit      string
+	DestinationBranch string
+	Participants      []Participant
+}
+
+// Participant is a user involved in a PR (reviewer, participant, or author on Server) and their review state.
+type Participant struct {
+	User     Account
+	Role     string // REVIEWER, PARTICIPANT, or AUTHOR
+	Approved bool
+	State    string // approved, changes_requested, or empty (Cloud); APPROVED, NEEDS_WORK, or UNAPPROVED (Server)
+}
+
+// apiFlavor encapsulates the URL construction and body encoding/decoding that differ
+// between Bitbucket Cloud and Bitbucket Server.
+type apiFlavor interface {
+	authURL(c *Client) string
+	prURL(c *Client, prID string) string
+	diffURL(c *Client, prID string) string
+	commentsURL(c *Client, prID string) string
+	prByBranchURL(c *Client, branch string) string
+	fileContentURL(c *Client, ref, path string) string
+	inlineCommentBody(cmt PRComment) map[string]interface{}
+	summaryCommentBody(text string) map[string]interface{}
+	decodePullRequest(data []byte) (*PullRequest, error)
+	decodeParticipants(data []byte) ([]Participant, error)
+}
+
+// isServer reports whether the client targets Bitbucket Server / Data Center.
+func (c *Client) isServer() bool {
+	return strings.ToLower(c.Kind) == KindServer
+}
+
+// api returns the flavor matching the client's Kind, defaulting to Cloud.
+func (c *Client) api() apiFlavor {
+	if c.isServer() {
+		return serverAPI{}
+	}
+	return cloudAPI{}
+}
+
+// escapePath escapes each segment of a repository file path for use in a URL.
+func escapePath(path string) string {
+	segments := strings.Split(strings.TrimPrefix(path, "/"), "/")
+	for i, seg := range segments {
+		segments[i] = url.PathEscape(seg)
+	}
+	return strings.Join(segments, "/")
+}
+
+// cloudAPI implements apiFlavor for Bitbucket Cloud.
+type cloudAPI struct{}
+
+func (cloudAPI) authURL(c *Client) string {
+	return c.BaseURL + "/user"
+}
+
+func (cloudAPI) prURL(c *Client, prID string) string {
+	return fmt.Sprintf("%s/repositories/%s/%s/pullrequests/%s", c.BaseURL, c.Workspace, c.RepoSlug, prID)
+}
+
+func (a cloudAPI) diffURL(c *Client, prID string) string {
+	return a.prURL(c, prID) + "/diff"
+}
+
+func (a cloudAPI) commentsURL(c *Client, prID string) string {
+	return a.prURL(c, prID) + "/comments"
+}
+
+func (cloudAPI) prByBranchURL(c *Client, branch string) string {
+	return fmt.Sprintf("%s/repositories/%s/%s/pullrequests?q=source.branch.name=\"%s\"&state=OPEN", c.BaseURL, c.Workspace, c.RepoSlug, branch)
+}
+
+func (cloudAPI) fileContentURL(c *Client, ref, path string) string {
+	return fmt.Sprintf("%s/repositories/%s/%s/src/%s/%s", c.BaseURL, c.Workspace, c.RepoSlug, url.PathEscape(ref), escapePath(path))
+}
+
+func (cloudAPI) inlineCommentBody(cmt PRComment) map[string]interface{} {
+	inline := map[string]interface{}{
+		"path": cmt.FilePath,
+	}
+	if cmt.Line > 0 {
+		inline["to"] = cmt.Line
+	} else {
+		inline["from"] = cmt.FromLine
+	}
+	return map[string]interface{}{
+		"content": map[string]string{
+			"raw": cmt.Text,
+		},
+		"inline": inline,
+	}
+}
+
+func (cloudAPI) summaryCommentBody(text string) map[string]interface{} {
+	return map[string]interface{}{
+		"content": map[string]string{
+			"raw": text,
+		},
+	}
+}
+
+func (cloudAPI) decodePullRequest(data []byte) (*PullRequest, error) {
+	var pr struct {
+		ID          int    `json:"id"`
+		Title       string `json:"title"`
+		Description string `json:"description"`
+		State       string `json:"state"`
+		Source      struct {
+			Branch struct {
+				Name string `json:"name"`
+			} `json:"branch"`
+			Commit struct {
+				Hash string `json:"hash"`
+			} `json:"commit"`
+		} `json:"source"`
+		Destination struct {
+			Branch struct {
+				Name string `json:"name"`
+			} `json:"branch"`
+		} `json:"destination"`
+	}
+	if err := json.Unmarshal(data, &pr); err != nil {
+		return nil, fmt.Errorf("failed to decode pull request: %w", err)
+	}
+	return &PullRequest{
+		ID:                pr.ID,
+		Title:             pr.Title,
+		Description:       pr.Description,
+		State:             pr.State,
+		SourceBranch:      pr.Source.Branch.Name,
+		SourceCommit:      pr.Source.Commit.Hash,
+		DestinationBranch: pr.Destination.Branch.Name,
+	}, nil
+}
+
+func (cloudAPI) decodeParticipants(data []byte) ([]Participant, error) {
+	var pr struct {
+		Participants []struct {
+			Role     string  `json:"role"`
+			Approved bool    `json:"approved"`
+			State    *string `json:"state"`
+			User     struct {
+				AccountID   string `json:"account_id"`
+				UUID        string `json:"uuid"`
+				Nickname    string `json:"nickname"`
+				DisplayName string `json:"display_name"`
+			} `json:"user"`
+		} `json:"participants"`
+	}
+	if err := json.Unmarshal(data, &pr); err != nil {
+		return nil, fmt.Errorf("failed to decode PR participants: %w", err)
+	}
+	var participants []Participant
+	for _, p := range pr.Participants {
+		participant := Participant{
+			User: Account{
+				AccountID:   p.User.AccountID,
+				UUID:        p.User.UUID,
+				Username:    p.User.Nickname,
+				DisplayName: p.User.DisplayName,
+			},
+			Role:     p.Role,
+			Approved: p.Approved,
+		}
+		if p.State != nil {
+			participant.State = *p.State
+		}
+		participants = append(participants, participant)
+	}
+	return participants, nil
+}
+
+// serverAPI implements apiFlavor for Bitbucket Server / Data Center, where the workspace
+// is the project key and BaseURL points at the /rest/api/1.0 root.
+type serverAPI struct{}
+
+func (serverAPI) repoURL(c *Client) string {
+	return fmt.Sprintf("%s/projects/%s/repos/%s", c.BaseURL, c.Workspace, c.RepoSlug)
+}
+
+func (a serverAPI) authURL(c *Client) string {
+	return a.repoURL(c)
+}
+
+func (a serverAPI) prURL(c *Client, prID string) string {
+	return fmt.Sprintf("%s/pull-requests/%s", a.repoURL(c), prID)
+}
+
+func (a serverAPI) diffURL(c *Client, prID string) string {
+	return a.prURL(c, prID) + ".diff"
+}
+
+func (a serverAPI) commentsURL(c *Client, prID string) string {
+	return a.prURL(c, prID) + "/comments"
+}
+
+func (a serverAPI) prByBranchURL(c *Client, branch string) string {
+	params := url.Values{}
+	params.Set("at", "refs/heads/"+branch)
+	params.Set("direction", "OUTGOING")
+	params.Set("state", "OPEN")
+	return fmt.Sprintf("%s/pull-requests?%s", a.repoURL(c), params.Encode())
+}
+
+func (a serverAPI) fileContentURL(c *Client, ref, path string) string {
+	params := url.Values{}
+	params.Set("at", ref)
+	return fmt.Sprintf("%s/raw/%s?%s", a.repoURL(c), escapePath(path), params.Encode())
+}
+
+func (serverAPI) inlineCommentBody(cmt PRComment) map[string]interface{} {
+	anchor := map[string]interface{}{
+		"path":     cmt.FilePath,
+		"line":     cmt.Line,
+		"lineType": "ADDED",
+		"fileType": "TO",
+		"diffType": "EFFECTIVE",
+	}
+	if cmt.Line <= 0 {
+		anchor["line"] = cmt.FromLine
+		anchor["lineType"] = "REMOVED"
+		anchor["fileType"] = "FROM"
+	}
+	return map[string]interface{}{
+		"text":   cmt.Text,
+		"anchor": anchor,
+	}
+}
+
+func (serverAPI) summaryCommentBody(text string) map[string]interface{} {
+	return map[string]interface{}{
+		"text": text,
+	}
+}
+
+func (serverAPI) decodePullRequest(data []byte) (*PullRequest, error) {
+	var pr struct {
+		ID          int    `json:"id"`
+		Title       string `json:"title"`
+		Description string `json:"description"`
+		State       string `json:"state"`
+		FromRef     struct {
+			DisplayID    string `json:"displayId"`
+			LatestCommit string `json:"latestCommit"`
+		} `json:"fromRef"`
+		ToRef struct {
+			DisplayID string `json:"displayId"`
+		} `json:"toRef"`
+	}
+	if err := json.Unmarshal(data, &pr); err != nil {
+		return nil, fmt.Errorf("failed to decode pull request: %w", err)
+	}
+	return &PullRequest{
+		ID:                pr.ID,
+		Title:             pr.Title,
+		Description:       pr.Description,
+		State:             pr.State,
+		SourceBranch:      pr.FromRef.DisplayID,
+		SourceCommit:      pr.FromRef.LatestCommit,
+		DestinationBranch: pr.ToRef.DisplayID,
+	}, nil
+}
+
+func (serverAPI) decodeParticipants(data []byte) ([]Participant, error) {
+	type serverParticipant struct {
+		Role     string `json:"role"`
+		Approved bool   `json:"approved"`
+		Status   string `json:"status"`
+		User     struct {
+			ID          int    `json:"id"`
+			Name        string `json:"name"`
+			DisplayName string `json:"displayName"`
+		} `json:"user"`
+	}
+	var pr struct {
+		Reviewers    []serverParticipant `json:"reviewers"`
+		Participants []serverParticipant `json:"participants"`
+	}
+	if err := json.Unmarshal(data, &pr); err != nil {
+		return nil, fmt.Errorf("failed to decode PR participants: %w", err)
+	}
+	var participants []Participant
+	for _, p := range append(pr.Reviewers, pr.Participants...) {
+		participants = append(participants, Participant{
+			User: Account{
+				AccountID:   strconv.Itoa(p.User.ID),
+				Username:    p.User.Name,
+				DisplayName: p.User.DisplayName,
+			},
+			Role:     p.Role,
+			Approved: p.Approved,
+			State:    p.Status,
+		})
+	}
+	return participants, nil
+}
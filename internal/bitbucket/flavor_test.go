@@ -0,0 +1,258 @@
+package bitbucket
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func newServerClient() *Client {
+	return &Client{
+		Email:     "user",
+		APIToken:  "token",
+		Workspace: "PROJ",
+		RepoSlug:  "repo",
+		BaseURL:   "https://bitbucket.example.com/rest/api/1.0",
+		Kind:      KindServer,
+	}
+}
+
+func TestServer_GetPRDiffURL(t *testing.T) {
+	mock := &mockRoundTripper{
+		responseCode: http.StatusOK,
+		responseBody: "diff --git a/foo.go b/foo.go\n",
+	}
+	origTransport := http.DefaultClient.Transport
+	http.DefaultClient.Transport = mock
+	defer func() { http.DefaultClient.Transport = origTransport }()
+
+	if _, err := newServerClient().GetPRDiff(context.Background(), "42"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	want := "https://bitbucket.example.com/rest/api/1.0/projects/PROJ/repos/repo/pull-requests/42.diff"
+	if got := mock.lastRequest.URL.String(); got != want {
+		t.Errorf("expected URL %s, got %s", want, got)
+	}
+}
+
+func TestServer_PostInlineCommentBody(t *testing.T) {
+	mock := &mockRoundTripper{
+		responseCode: http.StatusCreated,
+		responseBody: `{"id": 1}`,
+	}
+	origTransport := http.DefaultClient.Transport
+	http.DefaultClient.Transport = mock
+	defer func() { http.DefaultClient.Transport = origTransport }()
+
+	if err := newServerClient().PostInlineComment(context.Background(), "42", PRComment{FilePath: "foo.go", Line: 7, Text: "Server comment"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	want := "https://bitbucket.example.com/rest/api/1.0/projects/PROJ/repos/repo/pull-requests/42/comments"
+	if got := mock.lastRequest.URL.String(); got != want {
+		t.Errorf("expected URL %s, got %s", want, got)
+	}
+	var body struct {
+		Text   string `json:"text"`
+		Anchor struct {
+			Path     string `json:"path"`
+			Line     int    `json:"line"`
+			LineType string `json:"lineType"`
+			FileType string `json:"fileType"`
+		} `json:"anchor"`
+	}
+	if err := json.Unmarshal(mock.lastBody, &body); err != nil {
+		t.Fatalf("invalid request body: %v", err)
+	}
+	if body.Text != "Server comment" || body.Anchor.Path != "foo.go" || body.Anchor.Line != 7 {
+		t.Errorf("unexpected anchor body: %s", string(mock.lastBody))
+	}
+	if body.Anchor.LineType != "ADDED" || body.Anchor.FileType != "TO" {
+		t.Errorf("expected ADDED/TO anchor, got %s", string(mock.lastBody))
+	}
+	if bytes.Contains(mock.lastBody, []byte(`"inline"`)) {
+		t.Errorf("server body must not use the cloud inline shape: %s", string(mock.lastBody))
+	}
+}
+
+func TestServer_PostSummaryCommentBody(t *testing.T) {
+	mock := &mockRoundTripper{
+		responseCode: http.StatusCreated,
+		responseBody: `{"id": 2}`,
+	}
+	origTransport := http.DefaultClient.Transport
+	http.DefaultClient.Transport = mock
+	defer func() { http.DefaultClient.Transport = origTransport }()
+
+	if err := newServerClient().PostSummaryComment(context.Background(), "42", "Summary"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if string(mock.lastBody) != `{"text":"Summary"}` {
+		t.Errorf("unexpected summary body: %s", string(mock.lastBody))
+	}
+}
+
+func TestGetPullRequest_Server(t *testing.T) {
+	mock := &mockRoundTripper{
+		responseCode: http.StatusOK,
+		responseBody: `{"id": 42, "title": "Add feature", "description": "Details", "state": "OPEN",
+			"fromRef": {"displayId": "feature/x", "latestCommit": "abc123"},
+			"toRef": {"displayId": "main"}}`,
+	}
+	origTransport := http.DefaultClient.Transport
+	http.DefaultClient.Transport = mock
+	defer func() { http.DefaultClient.Transport = origTransport }()
+
+	pr, err := newServerClient().GetPullRequest(context.Background(), "42")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	want := "https://bitbucket.example.com/rest/api/1.0/projects/PROJ/repos/repo/pull-requests/42"
+	if got := mock.lastRequest.URL.String(); got != want {
+		t.Errorf("expected URL %s, got %s", want, got)
+	}
+	if pr.ID != 42 || pr.Title != "Add feature" || pr.SourceBranch != "feature/x" || pr.SourceCommit != "abc123" || pr.DestinationBranch != "main" {
+		t.Errorf("unexpected decoded PR: %+v", pr)
+	}
+}
+
+func TestGetPullRequest_Cloud(t *testing.T) {
+	mock := &mockRoundTripper{
+		responseCode: http.StatusOK,
+		responseBody: `{"id": 7, "title": "Fix bug", "description": "Desc", "state": "OPEN",
+			"source": {"branch": {"name": "bugfix"}, "commit": {"hash": "def456"}},
+			"destination": {"branch": {"name": "develop"}},
+			"participants": [{"role": "REVIEWER", "approved": true, "user": {"display_name": "Alice"}}]}`,
+	}
+	origTransport := http.DefaultClient.Transport
+	http.DefaultClient.Transport = mock
+	defer func() { http.DefaultClient.Transport = origTransport }()
+
+	client := &Client{
+		Email:     "user@example.com",
+		APIToken:  "token",
+		Workspace: "ws",
+		RepoSlug:  "repo",
+		BaseURL:   "https://api.bitbucket.org/2.0",
+	}
+	pr, err := client.GetPullRequest(context.Background(), "7")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if pr.ID != 7 || pr.SourceBranch != "bugfix" || pr.SourceCommit != "def456" || pr.DestinationBranch != "develop" {
+		t.Errorf("unexpected decoded PR: %+v", pr)
+	}
+	// Participants come from the same response, so callers need not fetch the PR again
+	if len(pr.Participants) != 1 || pr.Participants[0].User.DisplayName != "Alice" || !pr.Participants[0].Approved {
+		t.Errorf("expected the participants decoded with the PR, got %+v", pr.Participants)
+	}
+}
+
+func TestServer_PRByBranchURL(t *testing.T) {
+	got := serverAPI{}.prByBranchURL(newServerClient(), "feature/x")
+	want := "https://bitbucket.example.com/rest/api/1.0/projects/PROJ/repos/repo/pull-requests?at=refs%2Fheads%2Ffeature%2Fx&direction=OUTGOING&state=OPEN"
+	if got != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+}
+
+func TestServer_PostInlineCommentOnDeletedLine(t *testing.T) {
+	mock := &mockRoundTripper{responseCode: http.StatusCreated, responseBody: `{"id": 1}`}
+	origTransport := http.DefaultClient.Transport
+	http.DefaultClient.Transport = mock
+	defer func() { http.DefaultClient.Transport = origTransport }()
+
+	if err := newServerClient().PostInlineComment(context.Background(), "42", PRComment{FilePath: "foo.go", FromLine: 9, Text: "Removed check"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	var body struct {
+		Anchor struct {
+			Line     int    `json:"line"`
+			LineType string `json:"lineType"`
+			FileType string `json:"fileType"`
+		} `json:"anchor"`
+	}
+	if err := json.Unmarshal(mock.lastBody, &body); err != nil {
+		t.Fatalf("invalid request body: %v", err)
+	}
+	if body.Anchor.Line != 9 || body.Anchor.LineType != "REMOVED" || body.Anchor.FileType != "FROM" {
+		t.Errorf("expected REMOVED/FROM anchor on line 9, got %s", string(mock.lastBody))
+	}
+}
+
+func TestServer_FileContentURL(t *testing.T) {
+	got := serverAPI{}.fileContentURL(newServerClient(), "4f2c9e1a7b3d", "src/main.go")
+	want := "https://bitbucket.example.com/rest/api/1.0/projects/PROJ/repos/repo/raw/src/main.go?at=4f2c9e1a7b3d"
+	if got != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+}
+
+func TestGetPullRequestParticipants_Cloud(t *testing.T) {
+	mock := &mockRoundTripper{
+		responseCode: http.StatusOK,
+		responseBody: `{
+			"id": 7,
+			"participants": [
+				{"role": "REVIEWER", "approved": true, "state": "approved", "user": {"display_name": "Alice", "account_id": "557058:alice", "nickname": "alice"}},
+				{"role": "REVIEWER", "approved": false, "state": "changes_requested", "user": {"display_name": "Bob", "account_id": "557058:bob"}},
+				{"role": "PARTICIPANT", "approved": false, "state": null, "user": {"display_name": "Carol"}}
+			]
+		}`,
+	}
+	origTransport := http.DefaultClient.Transport
+	http.DefaultClient.Transport = mock
+	defer func() { http.DefaultClient.Transport = origTransport }()
+	client := &Client{Workspace: "ws", RepoSlug: "repo", BaseURL: "https://api.bitbucket.org/2.0"}
+
+	participants, err := client.GetPullRequestParticipants(context.Background(), "7")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(participants) != 3 {
+		t.Fatalf("expected 3 participants, got %d", len(participants))
+	}
+	if p := participants[1]; p.User.DisplayName != "Bob" || p.Approved || p.State != "changes_requested" || p.Role != "REVIEWER" {
+		t.Errorf("unexpected participant %+v", p)
+	}
+	if !IsApproved(participants) {
+		t.Error("expected PR to be approved")
+	}
+	approvers := Approvers(participants)
+	if len(approvers) != 1 || approvers[0].User.Username != "alice" {
+		t.Errorf("expected only alice to have approved, got %+v", approvers)
+	}
+	if IsApproved(participants[1:]) {
+		t.Error("expected PR without approvals to be unapproved")
+	}
+}
+
+func TestGetPullRequestParticipants_Server(t *testing.T) {
+	mock := &mockRoundTripper{
+		responseCode: http.StatusOK,
+		responseBody: `{
+			"id": 42,
+			"reviewers": [
+				{"role": "REVIEWER", "approved": false, "status": "NEEDS_WORK", "user": {"id": 3, "name": "bob", "displayName": "Bob"}}
+			],
+			"participants": [
+				{"role": "PARTICIPANT", "approved": true, "status": "APPROVED", "user": {"id": 5, "name": "carol", "displayName": "Carol"}}
+			]
+		}`,
+	}
+	origTransport := http.DefaultClient.Transport
+	http.DefaultClient.Transport = mock
+	defer func() { http.DefaultClient.Transport = origTransport }()
+
+	participants, err := newServerClient().GetPullRequestParticipants(context.Background(), "42")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(participants) != 2 || participants[0].State != "NEEDS_WORK" || participants[1].User.Username != "carol" {
+		t.Errorf("unexpected participants %+v", participants)
+	}
+	if !IsApproved(participants) {
+		t.Error("expected PR to be approved")
+	}
+}
@@ -0,0 +1,126 @@
+package bitbucket
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// ErrLocked indicates that another pullreview run holds the lock on a PR.
+var ErrLocked = errors.New("PR is locked by another pullreview run")
+
+// DefaultLockTTL is how long a lock is honored before it is considered abandoned
+// (e.g. the run holding it crashed before releasing it).
+const DefaultLockTTL = 15 * time.Minute
+
+// lockMarkerRe matches the hidden marker identifying a lock comment.
+var lockMarkerRe = regexp.MustCompile(`<!-- pullreview:lock owner=(\S+) acquired=(\S+) -->`)
+
+// LockedError reports who holds the lock on a PR. It matches ErrLocked via errors.Is.
+type LockedError struct {
+	Owner      string
+	AcquiredAt time.Time
+}
+
+func (e *LockedError) Error() string {
+	return fmt.Sprintf("%v (held by %s since %s)", ErrLocked, e.Owner, e.AcquiredAt.Format(time.RFC3339))
+}
+
+// Unwrap allows errors.Is(err, ErrLocked).
+func (e *LockedError) Unwrap() error {
+	return ErrLocked
+}
+
+// PRLock is a held lock on a PR, backed by a marked PR comment.
+type PRLock struct {
+	client    *Client
+	prID      string
+	commentID string
+}
+
+// lockHolder is an unexpired lock comment found on a PR.
+type lockHolder struct {
+	commentID  string
+	owner      string
+	acquiredAt time.Time
+}
+
+// AcquireLock takes the per-PR run lock by posting a marked comment, so concurrent runs on the
+// same PR do not post duplicate comments. If another unexpired lock exists, it returns a
+// *LockedError. When two runs race, the lock comment posted first (lowest ID) wins and the
+// other run removes its own comment and backs off. Locks older than ttl are ignored.
+func (c *Client) AcquireLock(ctx context.Context, prID, owner string, ttl time.Duration) (*PRLock, error) {
+	if ttl <= 0 {
+		ttl = DefaultLockTTL
+	}
+	holders, err := c.activeLocks(ctx, prID, ttl)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check PR lock: %w", err)
+	}
+	if len(holders) > 0 {
+		return nil, &LockedError{Owner: holders[0].owner, AcquiredAt: holders[0].acquiredAt}
+	}
+
+	commentID, err := c.createComment(ctx, prID, lockCommentText(owner, time.Now()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire PR lock: %w", err)
+	}
+
+	// Another run may have posted its lock between our check and our post
+	holders, err = c.activeLocks(ctx, prID, ttl)
+	if err != nil {
+		_ = c.DeleteComment(ctx, prID, commentID)
+		return nil, fmt.Errorf("failed to check PR lock: %w", err)
+	}
+	if len(holders) > 0 && holders[0].commentID != commentID {
+		_ = c.DeleteComment(ctx, prID, commentID)
+		return nil, &LockedError{Owner: holders[0].owner, AcquiredAt: holders[0].acquiredAt}
+	}
+	return &PRLock{client: c, prID: prID, commentID: commentID}, nil
+}
+
+// Release removes the lock comment.
+func (l *PRLock) Release(ctx context.Context) error {
+	if err := l.client.DeleteComment(ctx, l.prID, l.commentID); err != nil {
+		return fmt.Errorf("failed to release PR lock: %w", err)
+	}
+	return nil
+}
+
+// activeLocks returns the unexpired lock comments on a PR, oldest (lowest ID) first.
+func (c *Client) activeLocks(ctx context.Context, prID string, ttl time.Duration) ([]lockHolder, error) {
+	comments, err := c.ListComments(ctx, prID)
+	if err != nil {
+		return nil, err
+	}
+	var holders []lockHolder
+	for _, cmt := range comments {
+		m := lockMarkerRe.FindStringSubmatch(cmt.Text)
+		if m == nil {
+			continue
+		}
+		acquiredAt, err := time.Parse(time.RFC3339, m[2])
+		if err != nil || time.Since(acquiredAt) > ttl {
+			continue
+		}
+		owner, _ := url.QueryUnescape(m[1])
+		holders = append(holders, lockHolder{commentID: cmt.ID, owner: owner, acquiredAt: acquiredAt})
+	}
+	sort.Slice(holders, func(i, j int) bool {
+		a, _ := strconv.Atoi(holders[i].commentID)
+		b, _ := strconv.Atoi(holders[j].commentID)
+		return a < b
+	})
+	return holders, nil
+}
+
+// lockCommentText renders the body of a lock comment.
+func lockCommentText(owner string, acquiredAt time.Time) string {
+	return fmt.Sprintf("⏳ pullreview is reviewing this PR (run: %s). This comment is removed when the run finishes.\n\n<!-- pullreview:lock owner=%s acquired=%s -->",
+		owner, url.QueryEscape(owner), acquiredAt.UTC().Format(time.RFC3339))
+}
@@ -0,0 +1,146 @@
+package bitbucket
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeCommentStore is an in-memory Bitbucket Cloud comments endpoint supporting list, create, and delete.
+type fakeCommentStore struct {
+	nextID   int
+	comments map[int]string
+	// beforeCreate, if set, runs before a comment is created (to simulate a racing run)
+	beforeCreate func(s *fakeCommentStore)
+}
+
+func newFakeCommentStore() *fakeCommentStore {
+	return &fakeCommentStore{nextID: 100, comments: make(map[int]string)}
+}
+
+func (s *fakeCommentStore) add(text string) int {
+	s.nextID++
+	s.comments[s.nextID] = text
+	return s.nextID
+}
+
+func (s *fakeCommentStore) RoundTrip(req *http.Request) (*http.Response, error) {
+	respond := func(code int, body string) (*http.Response, error) {
+		return &http.Response{StatusCode: code, Body: io.NopCloser(bytes.NewBufferString(body)), Header: make(http.Header)}, nil
+	}
+	switch req.Method {
+	case "GET":
+		var values []map[string]interface{}
+		for id := 0; id <= s.nextID; id++ {
+			if text, ok := s.comments[id]; ok {
+				values = append(values, map[string]interface{}{"id": id, "content": map[string]string{"raw": text}})
+			}
+		}
+		body, _ := json.Marshal(map[string]interface{}{"values": values})
+		return respond(http.StatusOK, string(body))
+	case "POST":
+		if s.beforeCreate != nil {
+			s.beforeCreate(s)
+		}
+		var body struct {
+			Content struct {
+				Raw string `json:"raw"`
+			} `json:"content"`
+		}
+		_ = json.NewDecoder(req.Body).Decode(&body)
+		return respond(http.StatusCreated, fmt.Sprintf(`{"id": %d}`, s.add(body.Content.Raw)))
+	case "DELETE":
+		var id int
+		fmt.Sscanf(req.URL.Path[strings.LastIndex(req.URL.Path, "/")+1:], "%d", &id)
+		if _, ok := s.comments[id]; !ok {
+			return respond(http.StatusNotFound, `{}`)
+		}
+		delete(s.comments, id)
+		return respond(http.StatusNoContent, "")
+	}
+	return respond(http.StatusMethodNotAllowed, `{}`)
+}
+
+func useFakeCommentStore(t *testing.T) *fakeCommentStore {
+	t.Helper()
+	store := newFakeCommentStore()
+	origTransport := http.DefaultClient.Transport
+	http.DefaultClient.Transport = store
+	t.Cleanup(func() { http.DefaultClient.Transport = origTransport })
+	return store
+}
+
+func TestAcquireLock_AcquireAndRelease(t *testing.T) {
+	store := useFakeCommentStore(t)
+	store.add("an unrelated comment")
+	client := newCloudClient()
+
+	lock, err := client.AcquireLock(context.Background(), "7", "ci-job-1", time.Minute)
+	if err != nil {
+		t.Fatalf("expected lock to be acquired, got %v", err)
+	}
+	if len(store.comments) != 2 {
+		t.Fatalf("expected a lock comment to be posted, got %d comments", len(store.comments))
+	}
+	if err := lock.Release(context.Background()); err != nil {
+		t.Fatalf("expected release to succeed, got %v", err)
+	}
+	if len(store.comments) != 1 {
+		t.Errorf("expected the lock comment to be removed, got %d comments", len(store.comments))
+	}
+
+	// The lock can be taken again once released
+	if _, err := client.AcquireLock(context.Background(), "7", "ci-job-2", time.Minute); err != nil {
+		t.Errorf("expected lock to be re-acquirable, got %v", err)
+	}
+}
+
+func TestAcquireLock_AlreadyLocked(t *testing.T) {
+	store := useFakeCommentStore(t)
+	store.add(lockCommentText("ci-job-1", time.Now()))
+
+	_, err := newCloudClient().AcquireLock(context.Background(), "7", "ci-job-2", time.Minute)
+	if !errors.Is(err, ErrLocked) {
+		t.Fatalf("expected ErrLocked, got %v", err)
+	}
+	var locked *LockedError
+	if !errors.As(err, &locked) || locked.Owner != "ci-job-1" {
+		t.Errorf("expected lock owner ci-job-1, got %+v", locked)
+	}
+	if len(store.comments) != 1 {
+		t.Errorf("expected no new lock comment, got %d comments", len(store.comments))
+	}
+}
+
+func TestAcquireLock_ExpiredLockIgnored(t *testing.T) {
+	store := useFakeCommentStore(t)
+	store.add(lockCommentText("crashed-job", time.Now().Add(-time.Hour)))
+
+	if _, err := newCloudClient().AcquireLock(context.Background(), "7", "ci-job-2", time.Minute); err != nil {
+		t.Errorf("expected expired lock to be ignored, got %v", err)
+	}
+}
+
+func TestAcquireLock_LosesRace(t *testing.T) {
+	store := useFakeCommentStore(t)
+	store.beforeCreate = func(s *fakeCommentStore) {
+		s.beforeCreate = nil
+		s.add(lockCommentText("ci-job-1", time.Now()))
+	}
+
+	_, err := newCloudClient().AcquireLock(context.Background(), "7", "ci-job-2", time.Minute)
+	var locked *LockedError
+	if !errors.As(err, &locked) || locked.Owner != "ci-job-1" {
+		t.Fatalf("expected to lose the race to ci-job-1, got %v", err)
+	}
+	if len(store.comments) != 1 {
+		t.Errorf("expected the losing lock comment to be removed, got %d comments", len(store.comments))
+	}
+}
@@ -0,0 +1,66 @@
+package config
+
+import (
+	"fmt"
+	"io"
+)
+
+// Check is the outcome of one configuration check.
+type Check struct {
+	Name   string
+	Err    error  // Why the check failed, or nil if it passed or was skipped
+	Detail string // What was found if it passed, or why it was skipped
+	Skip   bool   // The check was not run
+}
+
+// Checklist collects the results of configuration checks in the order they ran.
+type Checklist struct {
+	Checks []Check
+}
+
+// Record adds a check that passed if err is nil and failed otherwise, and reports whether it
+// passed. detail is only shown for passed checks.
+func (l *Checklist) Record(name, detail string, err error) bool {
+	l.Checks = append(l.Checks, Check{Name: name, Err: err, Detail: detail})
+	return err == nil
+}
+
+// Skip adds a check that was not run, with the reason.
+func (l *Checklist) Skip(name, reason string) {
+	l.Checks = append(l.Checks, Check{Name: name, Detail: reason, Skip: true})
+}
+
+// Failed returns the number of failed checks.
+func (l *Checklist) Failed() int {
+	n := 0
+	for _, c := range l.Checks {
+		if c.Err != nil {
+			n++
+		}
+	}
+	return n
+}
+
+// Err returns nil if no check failed, and otherwise an error counting the failures.
+func (l *Checklist) Err() error {
+	if n := l.Failed(); n > 0 {
+		return fmt.Errorf("%d of %d config checks failed", n, len(l.Checks))
+	}
+	return nil
+}
+
+// Write prints one line per check: a mark, the name, and the detail or error.
+func (l *Checklist) Write(w io.Writer) {
+	for _, c := range l.Checks {
+		switch {
+		case c.Skip:
+			fmt.Fprintf(w, "⏭️  %s (skipped: %s)\n", c.Name, c.Detail)
+		case c.Err != nil:
+			fmt.Fprintf(w, "❌ %s: %v\n", c.Name, c.Err)
+		case c.Detail != "":
+			fmt.Fprintf(w, "✅ %s (%s)\n", c.Name, c.Detail)
+		default:
+			fmt.Fprintf(w, "✅ %s\n", c.Name)
+		}
+	}
+}
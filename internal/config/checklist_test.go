@@ -0,0 +1,52 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestChecklist_AllPassed(t *testing.T) {
+	var l Checklist
+	if !l.Record("Required settings", "", nil) {
+		t.Error("expected Record to report a passed check")
+	}
+	l.Record("Bitbucket credentials", "authenticated as Jane", nil)
+	l.Skip("LLM test call", "use --llm to enable")
+	if l.Failed() != 0 || l.Err() != nil {
+		t.Errorf("expected no failures, got %d (%v)", l.Failed(), l.Err())
+	}
+
+	var out bytes.Buffer
+	l.Write(&out)
+	want := "✅ Required settings\n" +
+		"✅ Bitbucket credentials (authenticated as Jane)\n" +
+		"⏭️  LLM test call (skipped: use --llm to enable)\n"
+	if out.String() != want {
+		t.Errorf("unexpected checklist:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
+
+func TestChecklist_Failures(t *testing.T) {
+	var l Checklist
+	l.Record("Required settings", "", nil)
+	if l.Record("Prompt file", "ignored detail", errors.New("missing (DIFF_CONTENT_HERE)")) {
+		t.Error("expected Record to report a failed check")
+	}
+	l.Record("Bitbucket credentials", "", errors.New("401 Unauthorized"))
+	l.Skip("LLM test call", "configuration is invalid")
+
+	if l.Failed() != 2 {
+		t.Errorf("expected 2 failures, got %d", l.Failed())
+	}
+	if err := l.Err(); err == nil || err.Error() != "2 of 4 config checks failed" {
+		t.Errorf("unexpected error: %v", err)
+	}
+	var out bytes.Buffer
+	l.Write(&out)
+	if !strings.Contains(out.String(), "❌ Prompt file: missing (DIFF_CONTENT_HERE)\n") ||
+		strings.Contains(out.String(), "ignored detail") {
+		t.Errorf("expected the failure's error instead of its detail, got:\n%s", out.String())
+	}
+}
@@ -5,39 +5,136 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"pullreview/internal/review"
 	"pullreview/internal/utils"
+	"strconv"
 	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
+// Code hosts selectable with the provider setting.
+const (
+	ProviderBitbucket = "bitbucket"
+	ProviderGitHub    = "github"
+	ProviderGitLab    = "gitlab"
+)
+
 // Config holds all configuration for the pullreview tool.
 type Config struct {
+	Provider string `yaml:"provider"` // Code host the PRs live on: bitbucket (default), github, or gitlab
+
 	Bitbucket struct {
 		Email string `yaml:"email"` // Bitbucket Cloud account email
 
 		APIToken string `yaml:"api_token"` // Bitbucket Cloud API token
 
+		APITokenFile string `yaml:"api_token_file"` // File holding the API token (overrides api_token)
+
+		AccessToken string `yaml:"access_token"` // OAuth2 access token (used as a Bearer token instead of email + api_token)
+
+		AccessTokenFile string `yaml:"access_token_file"` // File holding the OAuth2 access token (overrides access_token)
+
 		Workspace string `yaml:"workspace"` // Bitbucket Cloud workspace
 
 		RepoSlug string `yaml:"repo_slug"` // Bitbucket repository slug (inferred from git if missing)
-		BaseURL  string `yaml:"base_url"`  // Bitbucket API base URL (optional, defaults to https://api.bitbucket.org/2.0)
+		BaseURL  string `yaml:"base_url"`  // Bitbucket API base URL (optional for cloud, defaults to https://api.bitbucket.org/2.0)
+
+		Kind string `yaml:"kind"` // Bitbucket deployment: cloud (default) or server (Bitbucket Server / Data Center)
 
 	} `yaml:"bitbucket"`
 
+	GitHub struct {
+		Token string `yaml:"token"` // GitHub personal access token (or GITHUB_TOKEN)
+
+		TokenFile string `yaml:"token_file"` // File holding the token (overrides token)
+
+		Owner string `yaml:"owner"` // Repository owner (user or organization)
+
+		Repo string `yaml:"repo"` // Repository name
+
+		BaseURL string `yaml:"base_url"` // REST API base URL (defaults to https://api.github.com; GitHub Enterprise uses https://<host>/api/v3)
+
+	} `yaml:"github"`
+
+	GitLab struct {
+		Token string `yaml:"token"` // GitLab personal, project, or group access token (or GITLAB_TOKEN)
+
+		TokenFile string `yaml:"token_file"` // File holding the token (overrides token)
+
+		Project string `yaml:"project"` // Project ID or full path (e.g. group/subgroup/project)
+
+		BaseURL string `yaml:"base_url"` // REST API base URL (defaults to https://gitlab.com/api/v4; self-managed uses https://<host>/api/v4)
+
+	} `yaml:"gitlab"`
+
 	LLM struct {
 		Provider string `yaml:"provider"` // LLM provider name (e.g., openai)
 
 		APIKey string `yaml:"api_key"` // LLM API key
 
+		APIKeyFile string `yaml:"api_key_file"` // File holding the LLM API key (overrides api_key)
+
 		Endpoint string `yaml:"endpoint"` // LLM API endpoint
 
 		Model string `yaml:"model"` // LLM model name (e.g., arcee-ai/trinity-large-preview:free)
 
+		Deployment string `yaml:"deployment"` // Azure OpenAI deployment name (defaults to model)
+
+		APIVersion string `yaml:"api_version"` // Azure OpenAI API version (e.g., 2024-02-01)
+
+		PricePer1KTokens float64 `yaml:"price_per_1k_tokens"` // Optional price per 1,000 tokens, used to estimate review cost
+
+		MaxDiffBytes int `yaml:"max_diff_bytes"` // Split diffs larger than this into per-file chunks (0 disables chunking)
+
+		Timeout time.Duration `yaml:"timeout"` // Deadline for each LLM request, e.g. 90s or 5m (defaults to 5m)
+
+		CacheDir string `yaml:"cache_dir"` // Directory for cached LLM responses (caching is off if empty)
+
+		CacheTTL time.Duration `yaml:"cache_ttl"` // How long cached responses stay valid (defaults to 24h)
+
+		Providers []LLMProvider `yaml:"providers"` // Fallback providers, tried in order when the one before fails hard
+
 	} `yaml:"llm"`
 
+	Webhook struct {
+		ListenAddr string `yaml:"listen_addr"` // Address `pullreview serve` listens on (defaults to :8080)
+
+		Secret string `yaml:"secret"` // Shared secret used to verify Bitbucket webhook signatures
+
+		SecretFile string `yaml:"secret_file"` // File holding the webhook secret (overrides secret)
+
+	} `yaml:"webhook"`
+
 	PromptFile string `yaml:"prompt_file"` // Path to the prompt template file
 
+	MinSeverity string `yaml:"min_severity"` // Only post findings at or above this severity (critical, high, medium, low, info)
+
+	MaxInlineComments int `yaml:"max_inline_comments"` // Post at most this many inline comments, most severe first (0 means unlimited)
+
+	LineTolerance int `yaml:"line_tolerance"` // Snap inline comments up to this many lines to the nearest added line (0 means exact lines only)
+
+	OversizedDiffBytes int `yaml:"oversized_diff_bytes"` // Diffs larger than this are handled per oversized_diff (0 means no limit)
+
+	OversizedDiff string `yaml:"oversized_diff"` // What to do with diffs over oversized_diff_bytes: skip (default), chunk, or truncate
+
+	HistoryFile string `yaml:"history_file"` // JSON-lines file each PR review is recorded to (no history if empty)
+
+}
+
+// LLMProvider is an entry of llm.providers: an LLM to fall back on when the primary one (and
+// any earlier entries) fail with an auth, quota, or server error.
+type LLMProvider struct {
+	Provider   string `yaml:"provider"`
+	APIKey     string `yaml:"api_key"`
+	APIKeyFile string `yaml:"api_key_file"`
+	Endpoint   string `yaml:"endpoint"`
+	Model      string `yaml:"model"`
+	Deployment string `yaml:"deployment"`
+	APIVersion string `yaml:"api_version"`
+
+	PricePer1KTokens float64 `yaml:"price_per_1k_tokens"` // Optional price per 1,000 tokens for this provider
 }
 
 // LoadConfigWithOverrides loads configuration from a YAML file, then applies overrides from
@@ -45,6 +142,19 @@ type Config struct {
 
 // Returns a validated Config or an error if required fields are missing.
 func LoadConfigWithOverrides(cfgFile, email, apiToken, repoSlug string) (*Config, error) {
+	return load(cfgFile, email, apiToken, repoSlug, true)
+}
+
+// LoadLocalConfig loads configuration like LoadConfigWithOverrides for reviewing a local diff.
+// Nothing is fetched from or posted to a code host then, so the Bitbucket, GitHub or GitLab
+// credentials and repository are not required.
+func LoadLocalConfig(cfgFile string) (*Config, error) {
+	return load(cfgFile, "", "", "", false)
+}
+
+// load implements LoadConfigWithOverrides and LoadLocalConfig; requireHost controls whether the
+// code host settings are validated.
+func load(cfgFile, email, apiToken, repoSlug string, requireHost bool) (*Config, error) {
 
 	cfg := &Config{}
 
@@ -66,6 +176,52 @@ func LoadConfigWithOverrides(cfgFile, email, apiToken, repoSlug string) (*Config
 		}
 	}
 
+	// 1a. Read secrets kept in files (e.g. Docker/Kubernetes secrets), overriding inline values.
+	// Relative paths are relative to the config file, not the working directory.
+	type secretFile struct {
+		key  string
+		path string
+		dst  *string
+	}
+	secretFiles := []secretFile{
+		{"bitbucket.api_token_file", cfg.Bitbucket.APITokenFile, &cfg.Bitbucket.APIToken},
+		{"bitbucket.access_token_file", cfg.Bitbucket.AccessTokenFile, &cfg.Bitbucket.AccessToken},
+		{"llm.api_key_file", cfg.LLM.APIKeyFile, &cfg.LLM.APIKey},
+		{"webhook.secret_file", cfg.Webhook.SecretFile, &cfg.Webhook.Secret},
+		{"github.token_file", cfg.GitHub.TokenFile, &cfg.GitHub.Token},
+		{"gitlab.token_file", cfg.GitLab.TokenFile, &cfg.GitLab.Token},
+	}
+	for i := range cfg.LLM.Providers {
+		p := &cfg.LLM.Providers[i]
+		secretFiles = append(secretFiles, secretFile{fmt.Sprintf("llm.providers[%d].api_key_file", i), p.APIKeyFile, &p.APIKey})
+	}
+	for _, sf := range secretFiles {
+		if sf.path == "" {
+			continue
+		}
+		path := sf.path
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(filepath.Dir(cfgFile), path)
+		}
+		v, err := readSecretFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("could not read %s: %w", sf.key, err)
+		}
+		*sf.dst = v
+	}
+
+	// 1b. Without a top-level llm.provider, the first llm.providers entry is the primary (and
+	// receives the LLM_* env overrides below)
+	if strings.TrimSpace(cfg.LLM.Provider) == "" && len(cfg.LLM.Providers) > 0 {
+		p := cfg.LLM.Providers[0]
+		cfg.LLM.Provider, cfg.LLM.APIKey, cfg.LLM.Endpoint = p.Provider, p.APIKey, p.Endpoint
+		cfg.LLM.Model, cfg.LLM.Deployment, cfg.LLM.APIVersion = p.Model, p.Deployment, p.APIVersion
+		if cfg.LLM.PricePer1KTokens == 0 {
+			cfg.LLM.PricePer1KTokens = p.PricePer1KTokens
+		}
+		cfg.LLM.Providers = cfg.LLM.Providers[1:]
+	}
+
 	// 2. Override with environment variables if set (but only if not set by CLI flags)
 	if v := os.Getenv("BITBUCKET_EMAIL"); v != "" && email == "" {
 		cfg.Bitbucket.Email = v
@@ -73,6 +229,9 @@ func LoadConfigWithOverrides(cfgFile, email, apiToken, repoSlug string) (*Config
 	if v := os.Getenv("BITBUCKET_API_TOKEN"); v != "" && apiToken == "" {
 		cfg.Bitbucket.APIToken = v
 	}
+	if v := os.Getenv("BITBUCKET_ACCESS_TOKEN"); v != "" {
+		cfg.Bitbucket.AccessToken = v
+	}
 
 	if v := os.Getenv("BITBUCKET_WORKSPACE"); v != "" {
 
@@ -87,6 +246,40 @@ func LoadConfigWithOverrides(cfgFile, email, apiToken, repoSlug string) (*Config
 		cfg.Bitbucket.BaseURL = v
 
 	}
+	if v := os.Getenv("BITBUCKET_KIND"); v != "" {
+		cfg.Bitbucket.Kind = v
+	}
+
+	if v := os.Getenv("PULLREVIEW_PROVIDER"); v != "" {
+		cfg.Provider = v
+	}
+	if v := os.Getenv("GITHUB_TOKEN"); v != "" {
+		cfg.GitHub.Token = v
+	}
+	// GITHUB_REPOSITORY and GITHUB_API_URL are set by GitHub Actions
+	if v := os.Getenv("GITHUB_REPOSITORY"); v != "" {
+		owner, repo, ok := strings.Cut(v, "/")
+		if !ok {
+			return nil, fmt.Errorf("invalid GITHUB_REPOSITORY %q (want owner/repo)", v)
+		}
+		cfg.GitHub.Owner, cfg.GitHub.Repo = owner, repo
+	}
+	if v := os.Getenv("GITHUB_API_URL"); v != "" {
+		cfg.GitHub.BaseURL = v
+	}
+	if v := os.Getenv("GITLAB_TOKEN"); v != "" {
+		cfg.GitLab.Token = v
+	}
+	// CI_PROJECT_ID and CI_API_V4_URL are set by GitLab CI; GITLAB_PROJECT takes precedence
+	if v := os.Getenv("CI_PROJECT_ID"); v != "" {
+		cfg.GitLab.Project = v
+	}
+	if v := os.Getenv("GITLAB_PROJECT"); v != "" {
+		cfg.GitLab.Project = v
+	}
+	if v := os.Getenv("CI_API_V4_URL"); v != "" {
+		cfg.GitLab.BaseURL = v
+	}
 
 	if v := os.Getenv("LLM_API_KEY"); v != "" {
 		cfg.LLM.APIKey = v
@@ -100,9 +293,91 @@ func LoadConfigWithOverrides(cfgFile, email, apiToken, repoSlug string) (*Config
 	if v := os.Getenv("LLM_MODEL"); v != "" {
 		cfg.LLM.Model = v
 	}
+	if v := os.Getenv("LLM_DEPLOYMENT"); v != "" {
+		cfg.LLM.Deployment = v
+	}
+	if v := os.Getenv("LLM_API_VERSION"); v != "" {
+		cfg.LLM.APIVersion = v
+	}
+	if v := os.Getenv("LLM_PRICE_PER_1K_TOKENS"); v != "" {
+		price, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid LLM_PRICE_PER_1K_TOKENS %q: %w", v, err)
+		}
+		cfg.LLM.PricePer1KTokens = price
+	}
+	if v := os.Getenv("LLM_MAX_DIFF_BYTES"); v != "" {
+		maxBytes, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid LLM_MAX_DIFF_BYTES %q: %w", v, err)
+		}
+		cfg.LLM.MaxDiffBytes = maxBytes
+	}
+	if v := os.Getenv("LLM_TIMEOUT"); v != "" {
+		timeout, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid LLM_TIMEOUT %q: %w", v, err)
+		}
+		cfg.LLM.Timeout = timeout
+	}
+	if v := os.Getenv("LLM_CACHE_DIR"); v != "" {
+		cfg.LLM.CacheDir = v
+	}
+	if v := os.Getenv("LLM_CACHE_TTL"); v != "" {
+		ttl, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid LLM_CACHE_TTL %q: %w", v, err)
+		}
+		cfg.LLM.CacheTTL = ttl
+	}
 	if v := os.Getenv("PULLREVIEW_PROMPT_FILE"); v != "" {
 		cfg.PromptFile = v
 	}
+	if v := os.Getenv("PULLREVIEW_WEBHOOK_LISTEN_ADDR"); v != "" {
+		cfg.Webhook.ListenAddr = v
+	}
+	if v := os.Getenv("PULLREVIEW_WEBHOOK_SECRET"); v != "" {
+		cfg.Webhook.Secret = v
+	}
+	if v := os.Getenv("PULLREVIEW_MIN_SEVERITY"); v != "" {
+		cfg.MinSeverity = v
+	}
+	if v := os.Getenv("PULLREVIEW_MAX_INLINE_COMMENTS"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid PULLREVIEW_MAX_INLINE_COMMENTS %q: %w", v, err)
+		}
+		if n < 0 {
+			return nil, fmt.Errorf("invalid PULLREVIEW_MAX_INLINE_COMMENTS %q (must not be negative)", v)
+		}
+		cfg.MaxInlineComments = n
+	}
+	if v := os.Getenv("PULLREVIEW_LINE_TOLERANCE"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid PULLREVIEW_LINE_TOLERANCE %q: %w", v, err)
+		}
+		if n < 0 {
+			return nil, fmt.Errorf("invalid PULLREVIEW_LINE_TOLERANCE %q (must not be negative)", v)
+		}
+		cfg.LineTolerance = n
+	}
+	if v := os.Getenv("PULLREVIEW_OVERSIZED_DIFF_BYTES"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid PULLREVIEW_OVERSIZED_DIFF_BYTES %q: %w", v, err)
+		}
+		if n < 0 {
+			return nil, fmt.Errorf("invalid PULLREVIEW_OVERSIZED_DIFF_BYTES %q (must not be negative)", v)
+		}
+		cfg.OversizedDiffBytes = n
+	}
+	if v := os.Getenv("PULLREVIEW_OVERSIZED_DIFF"); v != "" {
+		cfg.OversizedDiff = v
+	}
+	if v := os.Getenv("PULLREVIEW_HISTORY_FILE"); v != "" {
+		cfg.HistoryFile = v
+	}
 
 	// 3. Override with CLI flags if provided (highest precedence)
 	if email != "" {
@@ -115,9 +390,40 @@ func LoadConfigWithOverrides(cfgFile, email, apiToken, repoSlug string) (*Config
 		cfg.Bitbucket.RepoSlug = repoSlug
 	}
 
-	// 4. Set default for BaseURL if not set
+	// 4. Set the default code host, then defaults for Kind and BaseURL if not set (Bitbucket
+	// Server has no default host)
+	cfg.Provider = strings.ToLower(strings.TrimSpace(cfg.Provider))
+	if cfg.Provider == "" {
+		cfg.Provider = ProviderBitbucket
+	}
+	switch cfg.Provider {
+	case ProviderBitbucket, ProviderGitHub, ProviderGitLab:
+	default:
+		return nil, fmt.Errorf("invalid provider %q (must be %s, %s, or %s)", cfg.Provider, ProviderBitbucket, ProviderGitHub, ProviderGitLab)
+	}
+
+	if cfg.MaxInlineComments < 0 {
+		return nil, fmt.Errorf("invalid max_inline_comments %d (must not be negative)", cfg.MaxInlineComments)
+	}
+	if cfg.LineTolerance < 0 {
+		return nil, fmt.Errorf("invalid line_tolerance %d (must not be negative)", cfg.LineTolerance)
+	}
+	if cfg.OversizedDiffBytes < 0 {
+		return nil, fmt.Errorf("invalid oversized_diff_bytes %d (must not be negative)", cfg.OversizedDiffBytes)
+	}
+	if err := review.ValidateOversizedPolicy(cfg.OversizedDiff); err != nil {
+		return nil, err
+	}
+
+	cfg.Bitbucket.Kind = strings.ToLower(strings.TrimSpace(cfg.Bitbucket.Kind))
+	if cfg.Bitbucket.Kind == "" {
+		cfg.Bitbucket.Kind = "cloud"
+	}
+	if cfg.Bitbucket.Kind != "cloud" && cfg.Bitbucket.Kind != "server" {
+		return nil, fmt.Errorf("invalid bitbucket.kind %q (must be cloud or server)", cfg.Bitbucket.Kind)
+	}
 
-	if strings.TrimSpace(cfg.Bitbucket.BaseURL) == "" {
+	if strings.TrimSpace(cfg.Bitbucket.BaseURL) == "" && cfg.Bitbucket.Kind == "cloud" {
 
 		cfg.Bitbucket.BaseURL = "https://api.bitbucket.org/2.0"
 
@@ -139,6 +445,11 @@ func LoadConfigWithOverrides(cfgFile, email, apiToken, repoSlug string) (*Config
 			cfg.LLM.Model = "gpt-4.1" // Default model for Copilot
 		}
 	}
+	for i := range cfg.LLM.Providers {
+		if p := &cfg.LLM.Providers[i]; strings.ToLower(p.Provider) == "copilot" && strings.TrimSpace(p.Model) == "" {
+			p.Model = "gpt-4.1"
+		}
+	}
 
 	// 5b. Set default for PromptFile if not set (look for prompt.md next to executable)
 	if strings.TrimSpace(cfg.PromptFile) == "" {
@@ -150,26 +461,24 @@ func LoadConfigWithOverrides(cfgFile, email, apiToken, repoSlug string) (*Config
 
 	// 6. Validate required fields
 	var missing []string
-	if strings.TrimSpace(cfg.Bitbucket.Email) == "" {
-		missing = append(missing, "bitbucket.email")
-	}
-	if strings.TrimSpace(cfg.Bitbucket.APIToken) == "" {
-		missing = append(missing, "bitbucket.api_token")
-	}
-
-	if strings.TrimSpace(cfg.Bitbucket.Workspace) == "" {
-		missing = append(missing, "bitbucket.workspace")
-	}
-
-	if strings.TrimSpace(cfg.Bitbucket.RepoSlug) == "" {
-		missing = append(missing, "bitbucket.repo_slug (could not infer from git remote)")
-	}
-	if strings.TrimSpace(cfg.LLM.Provider) == "" {
-		missing = append(missing, "llm.provider")
+	switch {
+	case !requireHost:
+	case cfg.Provider == ProviderGitHub:
+		missing = append(missing, missingGitHubValues(cfg)...)
+	case cfg.Provider == ProviderGitLab:
+		missing = append(missing, missingGitLabValues(cfg)...)
+	default:
+		missing = append(missing, missingBitbucketValues(cfg)...)
 	}
-	// API key is only required for non-Copilot providers
-	if strings.ToLower(cfg.LLM.Provider) != "copilot" && strings.TrimSpace(cfg.LLM.APIKey) == "" {
-		missing = append(missing, "llm.api_key")
+	missing = append(missing, missingLLMValues("llm.", LLMProvider{
+		Provider:   cfg.LLM.Provider,
+		APIKey:     cfg.LLM.APIKey,
+		Endpoint:   cfg.LLM.Endpoint,
+		Model:      cfg.LLM.Model,
+		Deployment: cfg.LLM.Deployment,
+	})...)
+	for i, p := range cfg.LLM.Providers {
+		missing = append(missing, missingLLMValues(fmt.Sprintf("llm.providers[%d].", i), p)...)
 	}
 
 	if strings.TrimSpace(cfg.PromptFile) == "" {
@@ -195,6 +504,92 @@ func LoadConfigWithOverrides(cfgFile, email, apiToken, repoSlug string) (*Config
 
 }
 
+// readSecretFile returns the contents of a secret file without trailing newlines, which
+// editors and `echo` commonly add.
+func readSecretFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// missingBitbucketValues returns the required bitbucket settings cfg lacks.
+func missingBitbucketValues(cfg *Config) []string {
+	var missing []string
+	// Email and API token are only required when not using an OAuth access token
+	if strings.TrimSpace(cfg.Bitbucket.AccessToken) == "" {
+		if strings.TrimSpace(cfg.Bitbucket.Email) == "" {
+			missing = append(missing, "bitbucket.email")
+		}
+		if strings.TrimSpace(cfg.Bitbucket.APIToken) == "" {
+			missing = append(missing, "bitbucket.api_token (or bitbucket.access_token)")
+		}
+	}
+
+	if strings.TrimSpace(cfg.Bitbucket.Workspace) == "" {
+		missing = append(missing, "bitbucket.workspace")
+	}
+	if strings.TrimSpace(cfg.Bitbucket.BaseURL) == "" {
+		missing = append(missing, "bitbucket.base_url (required for Bitbucket Server)")
+	}
+
+	if strings.TrimSpace(cfg.Bitbucket.RepoSlug) == "" {
+		missing = append(missing, "bitbucket.repo_slug (could not infer from git remote)")
+	}
+	return missing
+}
+
+// missingGitHubValues returns the required github settings cfg lacks.
+func missingGitHubValues(cfg *Config) []string {
+	var missing []string
+	if strings.TrimSpace(cfg.GitHub.Token) == "" {
+		missing = append(missing, "github.token")
+	}
+	if strings.TrimSpace(cfg.GitHub.Owner) == "" {
+		missing = append(missing, "github.owner")
+	}
+	if strings.TrimSpace(cfg.GitHub.Repo) == "" {
+		missing = append(missing, "github.repo")
+	}
+	return missing
+}
+
+// missingGitLabValues returns the required gitlab settings cfg lacks.
+func missingGitLabValues(cfg *Config) []string {
+	var missing []string
+	if strings.TrimSpace(cfg.GitLab.Token) == "" {
+		missing = append(missing, "gitlab.token")
+	}
+	if strings.TrimSpace(cfg.GitLab.Project) == "" {
+		missing = append(missing, "gitlab.project")
+	}
+	return missing
+}
+
+// missingLLMValues returns the required settings p lacks, each prefixed with prefix.
+func missingLLMValues(prefix string, p LLMProvider) []string {
+	var missing []string
+	if strings.TrimSpace(p.Provider) == "" {
+		missing = append(missing, prefix+"provider")
+	}
+	// API key is not required for Copilot or local Ollama models
+	provider := strings.ToLower(p.Provider)
+	if provider != "copilot" && provider != "ollama" && strings.TrimSpace(p.APIKey) == "" {
+		missing = append(missing, prefix+"api_key")
+	}
+	// Azure OpenAI URLs are built from the resource endpoint and a deployment
+	if provider == "azure" {
+		if strings.TrimSpace(p.Endpoint) == "" {
+			missing = append(missing, prefix+"endpoint (Azure OpenAI resource URL)")
+		}
+		if strings.TrimSpace(p.Deployment) == "" && strings.TrimSpace(p.Model) == "" {
+			missing = append(missing, prefix+"deployment")
+		}
+	}
+	return missing
+}
+
 // inferRepoSlug tries to infer the Bitbucket repo slug from the git remote URL.
 func inferRepoSlug(repoPath string) (string, error) {
 	return utils.GetRepoSlugFromGitRemote(repoPath)
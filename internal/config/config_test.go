@@ -3,7 +3,9 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 )
 
 // NOTE: These tests mutate environment variables and must NOT use t.Parallel().
@@ -285,3 +287,821 @@ prompt_file: ` + promptFile + `
 		t.Errorf("expected env override base_url 'https://custom.bitbucket.org/api', got '%s'", cfg.Bitbucket.BaseURL)
 	}
 }
+
+func TestLoadConfigWithOverrides_ServerKindRequiresBaseURL(t *testing.T) {
+	// Unset all relevant env vars for test isolation
+	os.Unsetenv("BITBUCKET_EMAIL")
+	os.Unsetenv("BITBUCKET_API_TOKEN")
+	os.Unsetenv("BITBUCKET_WORKSPACE")
+	os.Unsetenv("BITBUCKET_BASE_URL")
+	os.Unsetenv("BITBUCKET_KIND")
+	os.Unsetenv("LLM_PROVIDER")
+	os.Unsetenv("LLM_API_KEY")
+	os.Unsetenv("LLM_ENDPOINT")
+	os.Unsetenv("PULLREVIEW_PROMPT_FILE")
+
+	tmpDir := t.TempDir()
+	promptFile := writeTempPromptFile(t, tmpDir)
+
+	yaml := `
+bitbucket:
+  kind: server
+  email: user@example.com
+  api_token: token1
+  workspace: PROJ
+  repo_slug: repo
+llm:
+  provider: openai
+  api_key: key1
+prompt_file: ` + promptFile + `
+`
+	cfgFile := writeTempConfigFile(t, yaml)
+	_, err := LoadConfigWithOverrides(cfgFile, "", "", "")
+	if err == nil || !strings.Contains(err.Error(), "bitbucket.base_url") {
+		t.Fatalf("expected missing base_url error for server kind, got %v", err)
+	}
+
+	os.Setenv("BITBUCKET_BASE_URL", "https://bitbucket.example.com/rest/api/1.0")
+	defer os.Unsetenv("BITBUCKET_BASE_URL")
+	cfg, err := LoadConfigWithOverrides(cfgFile, "", "", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Bitbucket.Kind != "server" {
+		t.Errorf("expected kind 'server', got '%s'", cfg.Bitbucket.Kind)
+	}
+}
+
+func TestLoadConfigWithOverrides_DefaultsToCloudKind(t *testing.T) {
+	os.Unsetenv("BITBUCKET_BASE_URL")
+	os.Unsetenv("BITBUCKET_KIND")
+	tmpDir := t.TempDir()
+	promptFile := writeTempPromptFile(t, tmpDir)
+
+	yaml := `
+bitbucket:
+  email: user@example.com
+  api_token: token1
+  workspace: ws1
+  repo_slug: repo
+llm:
+  provider: openai
+  api_key: key1
+prompt_file: ` + promptFile + `
+`
+	cfg, err := LoadConfigWithOverrides(writeTempConfigFile(t, yaml), "", "", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Bitbucket.Kind != "cloud" {
+		t.Errorf("expected default kind 'cloud', got '%s'", cfg.Bitbucket.Kind)
+	}
+	if cfg.Bitbucket.BaseURL != "https://api.bitbucket.org/2.0" {
+		t.Errorf("expected default cloud base_url, got '%s'", cfg.Bitbucket.BaseURL)
+	}
+
+	os.Setenv("BITBUCKET_KIND", "gitea")
+	defer os.Unsetenv("BITBUCKET_KIND")
+	if _, err := LoadConfigWithOverrides(writeTempConfigFile(t, yaml), "", "", ""); err == nil {
+		t.Error("expected error for invalid bitbucket.kind")
+	}
+}
+
+func TestLoadConfigWithOverrides_AccessTokenReplacesEmailAndToken(t *testing.T) {
+	os.Unsetenv("BITBUCKET_EMAIL")
+	os.Unsetenv("BITBUCKET_API_TOKEN")
+	os.Unsetenv("BITBUCKET_BASE_URL")
+	os.Unsetenv("BITBUCKET_KIND")
+	tmpDir := t.TempDir()
+	promptFile := writeTempPromptFile(t, tmpDir)
+
+	yaml := `
+bitbucket:
+  workspace: ws1
+  repo_slug: repo
+llm:
+  provider: openai
+  api_key: key1
+prompt_file: ` + promptFile + `
+`
+	cfgFile := writeTempConfigFile(t, yaml)
+	if _, err := LoadConfigWithOverrides(cfgFile, "", "", ""); err == nil {
+		t.Fatal("expected error when neither api_token nor access_token is set")
+	}
+
+	os.Setenv("BITBUCKET_ACCESS_TOKEN", "oauth-token")
+	defer os.Unsetenv("BITBUCKET_ACCESS_TOKEN")
+	cfg, err := LoadConfigWithOverrides(cfgFile, "", "", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Bitbucket.AccessToken != "oauth-token" {
+		t.Errorf("expected access token from env, got '%s'", cfg.Bitbucket.AccessToken)
+	}
+}
+
+func TestLoadConfigWithOverrides_OllamaDoesNotRequireAPIKey(t *testing.T) {
+	os.Unsetenv("LLM_API_KEY")
+	os.Unsetenv("LLM_PROVIDER")
+	tmpDir := t.TempDir()
+	promptFile := writeTempPromptFile(t, tmpDir)
+
+	yaml := `
+bitbucket:
+  email: user@example.com
+  api_token: token1
+  workspace: ws1
+  repo_slug: repo
+llm:
+  provider: ollama
+prompt_file: ` + promptFile + `
+`
+	cfg, err := LoadConfigWithOverrides(writeTempConfigFile(t, yaml), "", "", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.LLM.Provider != "ollama" {
+		t.Errorf("expected provider 'ollama', got '%s'", cfg.LLM.Provider)
+	}
+}
+
+func TestLoadConfigWithOverrides_AzureSettings(t *testing.T) {
+	os.Unsetenv("LLM_PROVIDER")
+	os.Unsetenv("LLM_ENDPOINT")
+	os.Unsetenv("LLM_MODEL")
+	os.Unsetenv("LLM_DEPLOYMENT")
+	os.Setenv("LLM_API_VERSION", "2024-06-01")
+	defer os.Unsetenv("LLM_API_VERSION")
+	tmpDir := t.TempDir()
+	promptFile := writeTempPromptFile(t, tmpDir)
+
+	yaml := `
+bitbucket:
+  email: user@example.com
+  api_token: token1
+  workspace: ws1
+  repo_slug: repo
+llm:
+  provider: azure
+  api_key: key1
+  endpoint: https://my-resource.openai.azure.com
+  deployment: gpt-4o-review
+prompt_file: ` + promptFile + `
+`
+	cfg, err := LoadConfigWithOverrides(writeTempConfigFile(t, yaml), "", "", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.LLM.Deployment != "gpt-4o-review" {
+		t.Errorf("expected deployment 'gpt-4o-review', got '%s'", cfg.LLM.Deployment)
+	}
+	if cfg.LLM.APIVersion != "2024-06-01" {
+		t.Errorf("expected env api_version '2024-06-01', got '%s'", cfg.LLM.APIVersion)
+	}
+
+	noDeployment := strings.Replace(yaml, "  deployment: gpt-4o-review\n", "", 1)
+	_, err = LoadConfigWithOverrides(writeTempConfigFile(t, noDeployment), "", "", "")
+	if err == nil || !strings.Contains(err.Error(), "llm.deployment") {
+		t.Errorf("expected missing llm.deployment error, got: %v", err)
+	}
+}
+
+func TestLoadConfigWithOverrides_PricePer1KTokens(t *testing.T) {
+	os.Unsetenv("LLM_PROVIDER")
+	os.Setenv("LLM_PRICE_PER_1K_TOKENS", "0.0025")
+	defer os.Unsetenv("LLM_PRICE_PER_1K_TOKENS")
+	tmpDir := t.TempDir()
+	promptFile := writeTempPromptFile(t, tmpDir)
+
+	yaml := `
+bitbucket:
+  email: user@example.com
+  api_token: token1
+  workspace: ws1
+  repo_slug: repo
+llm:
+  provider: openai
+  api_key: key1
+  price_per_1k_tokens: 0.01
+prompt_file: ` + promptFile + `
+`
+	cfg, err := LoadConfigWithOverrides(writeTempConfigFile(t, yaml), "", "", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.LLM.PricePer1KTokens != 0.0025 {
+		t.Errorf("expected env price 0.0025 to override YAML, got %v", cfg.LLM.PricePer1KTokens)
+	}
+
+	os.Setenv("LLM_PRICE_PER_1K_TOKENS", "cheap")
+	if _, err := LoadConfigWithOverrides(writeTempConfigFile(t, yaml), "", "", ""); err == nil {
+		t.Error("expected error for non-numeric LLM_PRICE_PER_1K_TOKENS")
+	}
+}
+
+func TestLoadConfigWithOverrides_LLMTimeout(t *testing.T) {
+	os.Unsetenv("LLM_PROVIDER")
+	os.Unsetenv("LLM_TIMEOUT")
+	tmpDir := t.TempDir()
+	promptFile := writeTempPromptFile(t, tmpDir)
+
+	yaml := `
+bitbucket:
+  email: user@example.com
+  api_token: token1
+  workspace: ws1
+  repo_slug: repo
+llm:
+  provider: openai
+  api_key: key1
+  timeout: 90s
+prompt_file: ` + promptFile + `
+`
+	cfg, err := LoadConfigWithOverrides(writeTempConfigFile(t, yaml), "", "", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.LLM.Timeout != 90*time.Second {
+		t.Errorf("expected timeout 90s from YAML, got %s", cfg.LLM.Timeout)
+	}
+
+	os.Setenv("LLM_TIMEOUT", "10m")
+	defer os.Unsetenv("LLM_TIMEOUT")
+	cfg, err = LoadConfigWithOverrides(writeTempConfigFile(t, yaml), "", "", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.LLM.Timeout != 10*time.Minute {
+		t.Errorf("expected env timeout 10m, got %s", cfg.LLM.Timeout)
+	}
+
+	os.Setenv("LLM_TIMEOUT", "forever")
+	if _, err := LoadConfigWithOverrides(writeTempConfigFile(t, yaml), "", "", ""); err == nil {
+		t.Error("expected error for invalid LLM_TIMEOUT")
+	}
+}
+
+func TestLoadConfigWithOverrides_CacheSettings(t *testing.T) {
+	os.Unsetenv("LLM_PROVIDER")
+	os.Unsetenv("LLM_CACHE_DIR")
+	os.Setenv("LLM_CACHE_TTL", "2h")
+	defer os.Unsetenv("LLM_CACHE_TTL")
+	tmpDir := t.TempDir()
+	promptFile := writeTempPromptFile(t, tmpDir)
+
+	yaml := `
+bitbucket:
+  email: user@example.com
+  api_token: token1
+  workspace: ws1
+  repo_slug: repo
+llm:
+  provider: openai
+  api_key: key1
+  cache_dir: /tmp/pullreview-cache
+  cache_ttl: 30m
+prompt_file: ` + promptFile + `
+`
+	cfg, err := LoadConfigWithOverrides(writeTempConfigFile(t, yaml), "", "", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.LLM.CacheDir != "/tmp/pullreview-cache" {
+		t.Errorf("expected cache_dir from YAML, got '%s'", cfg.LLM.CacheDir)
+	}
+	if cfg.LLM.CacheTTL != 2*time.Hour {
+		t.Errorf("expected env cache TTL 2h to override YAML, got %s", cfg.LLM.CacheTTL)
+	}
+}
+
+func TestLoadConfigWithOverrides_MinSeverity(t *testing.T) {
+	os.Unsetenv("LLM_PROVIDER")
+	os.Unsetenv("PULLREVIEW_PROMPT_FILE")
+	os.Unsetenv("PULLREVIEW_MIN_SEVERITY")
+	tmpDir := t.TempDir()
+	promptFile := writeTempPromptFile(t, tmpDir)
+
+	yaml := `
+bitbucket:
+  email: user@example.com
+  api_token: token1
+  workspace: ws1
+  repo_slug: repo
+llm:
+  provider: openai
+  api_key: key1
+prompt_file: ` + promptFile + `
+min_severity: low
+`
+	cfgFile := writeTempConfigFile(t, yaml)
+	cfg, err := LoadConfigWithOverrides(cfgFile, "", "", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.MinSeverity != "low" {
+		t.Errorf("expected min_severity from YAML, got '%s'", cfg.MinSeverity)
+	}
+
+	os.Setenv("PULLREVIEW_MIN_SEVERITY", "high")
+	defer os.Unsetenv("PULLREVIEW_MIN_SEVERITY")
+	cfg, err = LoadConfigWithOverrides(cfgFile, "", "", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.MinSeverity != "high" {
+		t.Errorf("expected env min severity to override YAML, got '%s'", cfg.MinSeverity)
+	}
+}
+
+func TestLoadConfigWithOverrides_MaxInlineComments(t *testing.T) {
+	os.Unsetenv("LLM_PROVIDER")
+	os.Unsetenv("PULLREVIEW_PROMPT_FILE")
+	os.Setenv("PULLREVIEW_MAX_INLINE_COMMENTS", "10")
+	defer os.Unsetenv("PULLREVIEW_MAX_INLINE_COMMENTS")
+	tmpDir := t.TempDir()
+	promptFile := writeTempPromptFile(t, tmpDir)
+
+	yaml := `
+bitbucket:
+  email: user@example.com
+  api_token: token1
+  workspace: ws1
+  repo_slug: repo
+llm:
+  provider: openai
+  api_key: key1
+prompt_file: ` + promptFile + `
+max_inline_comments: 25
+`
+	cfgFile := writeTempConfigFile(t, yaml)
+	cfg, err := LoadConfigWithOverrides(cfgFile, "", "", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.MaxInlineComments != 10 {
+		t.Errorf("expected env max inline comments 10 to override YAML, got %d", cfg.MaxInlineComments)
+	}
+
+	os.Setenv("PULLREVIEW_MAX_INLINE_COMMENTS", "many")
+	if _, err := LoadConfigWithOverrides(cfgFile, "", "", ""); err == nil {
+		t.Error("expected an error for a non-numeric PULLREVIEW_MAX_INLINE_COMMENTS")
+	}
+
+	os.Setenv("PULLREVIEW_MAX_INLINE_COMMENTS", "-1")
+	if _, err := LoadConfigWithOverrides(cfgFile, "", "", ""); err == nil {
+		t.Error("expected an error for a negative PULLREVIEW_MAX_INLINE_COMMENTS")
+	}
+
+	os.Unsetenv("PULLREVIEW_MAX_INLINE_COMMENTS")
+	cfgFile = writeTempConfigFile(t, strings.Replace(yaml, "max_inline_comments: 25", "max_inline_comments: -5", 1))
+	if _, err := LoadConfigWithOverrides(cfgFile, "", "", ""); err == nil {
+		t.Error("expected an error for a negative max_inline_comments")
+	}
+}
+
+func TestLoadConfigWithOverrides_LineTolerance(t *testing.T) {
+	for _, k := range []string{"LLM_PROVIDER", "PULLREVIEW_PROMPT_FILE", "PULLREVIEW_LINE_TOLERANCE"} {
+		t.Setenv(k, "")
+	}
+	promptFile := writeTempPromptFile(t, t.TempDir())
+
+	yaml := `
+bitbucket:
+  email: user@example.com
+  api_token: token1
+  workspace: ws1
+  repo_slug: repo
+llm:
+  provider: openai
+  api_key: key1
+prompt_file: ` + promptFile + `
+line_tolerance: 2
+`
+	cfgFile := writeTempConfigFile(t, yaml)
+	t.Setenv("PULLREVIEW_LINE_TOLERANCE", "0")
+	cfg, err := LoadConfigWithOverrides(cfgFile, "", "", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.LineTolerance != 0 {
+		t.Errorf("expected env line tolerance 0 to override YAML, got %d", cfg.LineTolerance)
+	}
+
+	t.Setenv("PULLREVIEW_LINE_TOLERANCE", "-1")
+	if _, err := LoadConfigWithOverrides(cfgFile, "", "", ""); err == nil {
+		t.Error("expected an error for a negative PULLREVIEW_LINE_TOLERANCE")
+	}
+
+	t.Setenv("PULLREVIEW_LINE_TOLERANCE", "")
+	cfgFile = writeTempConfigFile(t, strings.Replace(yaml, "line_tolerance: 2", "line_tolerance: -3", 1))
+	if _, err := LoadConfigWithOverrides(cfgFile, "", "", ""); err == nil {
+		t.Error("expected an error for a negative line_tolerance")
+	}
+}
+
+func TestLoadConfigWithOverrides_OversizedDiffBytes(t *testing.T) {
+	for _, k := range []string{"LLM_PROVIDER", "PULLREVIEW_PROMPT_FILE", "PULLREVIEW_OVERSIZED_DIFF_BYTES", "PULLREVIEW_OVERSIZED_DIFF"} {
+		t.Setenv(k, "")
+	}
+	promptFile := writeTempPromptFile(t, t.TempDir())
+
+	yaml := `
+bitbucket:
+  email: user@example.com
+  api_token: token1
+  workspace: ws1
+  repo_slug: repo
+llm:
+  provider: openai
+  api_key: key1
+  max_diff_bytes: 1000
+prompt_file: ` + promptFile + `
+oversized_diff_bytes: 5000
+oversized_diff: chunk
+`
+	cfgFile := writeTempConfigFile(t, yaml)
+	cfg, err := LoadConfigWithOverrides(cfgFile, "", "", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.OversizedDiffBytes != 5000 || cfg.OversizedDiff != "chunk" || cfg.LLM.MaxDiffBytes != 1000 {
+		t.Errorf("unexpected diff limits: max %d, policy %q, chunk %d", cfg.OversizedDiffBytes, cfg.OversizedDiff, cfg.LLM.MaxDiffBytes)
+	}
+
+	t.Setenv("PULLREVIEW_OVERSIZED_DIFF_BYTES", "200")
+	t.Setenv("PULLREVIEW_OVERSIZED_DIFF", "truncate")
+	cfg, err = LoadConfigWithOverrides(cfgFile, "", "", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.OversizedDiffBytes != 200 || cfg.OversizedDiff != "truncate" {
+		t.Errorf("expected env overrides, got max %d, policy %q", cfg.OversizedDiffBytes, cfg.OversizedDiff)
+	}
+
+	t.Setenv("PULLREVIEW_OVERSIZED_DIFF_BYTES", "big")
+	if _, err := LoadConfigWithOverrides(cfgFile, "", "", ""); err == nil {
+		t.Error("expected an error for a non-numeric PULLREVIEW_OVERSIZED_DIFF_BYTES")
+	}
+	t.Setenv("PULLREVIEW_OVERSIZED_DIFF_BYTES", "-1")
+	if _, err := LoadConfigWithOverrides(cfgFile, "", "", ""); err == nil {
+		t.Error("expected an error for a negative PULLREVIEW_OVERSIZED_DIFF_BYTES")
+	}
+	t.Setenv("PULLREVIEW_OVERSIZED_DIFF_BYTES", "")
+	t.Setenv("PULLREVIEW_OVERSIZED_DIFF", "split")
+	if _, err := LoadConfigWithOverrides(cfgFile, "", "", ""); err == nil {
+		t.Error("expected an error for an unknown PULLREVIEW_OVERSIZED_DIFF")
+	}
+
+	t.Setenv("PULLREVIEW_OVERSIZED_DIFF", "")
+	cfgFile = writeTempConfigFile(t, strings.Replace(yaml, "oversized_diff_bytes: 5000", "oversized_diff_bytes: -5000", 1))
+	if _, err := LoadConfigWithOverrides(cfgFile, "", "", ""); err == nil {
+		t.Error("expected an error for a negative oversized_diff_bytes")
+	}
+	cfgFile = writeTempConfigFile(t, strings.Replace(yaml, "oversized_diff: chunk", "oversized_diff: split", 1))
+	if _, err := LoadConfigWithOverrides(cfgFile, "", "", ""); err == nil {
+		t.Error("expected an error for an unknown oversized_diff")
+	}
+}
+
+func TestLoadConfigWithOverrides_WebhookSettings(t *testing.T) {
+	os.Unsetenv("LLM_PROVIDER")
+	os.Unsetenv("PULLREVIEW_PROMPT_FILE")
+	os.Unsetenv("PULLREVIEW_WEBHOOK_LISTEN_ADDR")
+	os.Setenv("PULLREVIEW_WEBHOOK_SECRET", "from-env")
+	defer os.Unsetenv("PULLREVIEW_WEBHOOK_SECRET")
+	tmpDir := t.TempDir()
+	promptFile := writeTempPromptFile(t, tmpDir)
+
+	yaml := `
+bitbucket:
+  email: user@example.com
+  api_token: token1
+  workspace: ws1
+  repo_slug: repo
+llm:
+  provider: openai
+  api_key: key1
+webhook:
+  listen_addr: ":9090"
+  secret: from-yaml
+prompt_file: ` + promptFile + `
+`
+	cfg, err := LoadConfigWithOverrides(writeTempConfigFile(t, yaml), "", "", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Webhook.ListenAddr != ":9090" {
+		t.Errorf("expected listen_addr from YAML, got '%s'", cfg.Webhook.ListenAddr)
+	}
+	if cfg.Webhook.Secret != "from-env" {
+		t.Errorf("expected env webhook secret to override YAML, got '%s'", cfg.Webhook.Secret)
+	}
+}
+
+func TestLoadConfigWithOverrides_FallbackProviders(t *testing.T) {
+	os.Unsetenv("LLM_PROVIDER")
+	os.Unsetenv("LLM_ENDPOINT")
+	os.Unsetenv("LLM_MODEL")
+	os.Unsetenv("PULLREVIEW_PROMPT_FILE")
+	os.Setenv("LLM_API_KEY", "env-key")
+	defer os.Unsetenv("LLM_API_KEY")
+	tmpDir := t.TempDir()
+	promptFile := writeTempPromptFile(t, tmpDir)
+
+	// Without a top-level provider the first entry becomes the primary
+	yaml := `
+bitbucket:
+  email: user@example.com
+  api_token: token1
+  workspace: ws1
+  repo_slug: repo
+llm:
+  providers:
+    - provider: openrouter
+      model: free-model
+    - provider: anthropic
+      api_key: key2
+    - provider: copilot
+prompt_file: ` + promptFile + `
+`
+	cfg, err := LoadConfigWithOverrides(writeTempConfigFile(t, yaml), "", "", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.LLM.Provider != "openrouter" || cfg.LLM.Model != "free-model" || cfg.LLM.APIKey != "env-key" {
+		t.Errorf("expected the first entry as primary with the env API key, got %q %q %q", cfg.LLM.Provider, cfg.LLM.Model, cfg.LLM.APIKey)
+	}
+	if len(cfg.LLM.Providers) != 2 || cfg.LLM.Providers[0].Provider != "anthropic" || cfg.LLM.Providers[1].Model != "gpt-4.1" {
+		t.Errorf("expected anthropic and copilot (with default model) as fallbacks, got %+v", cfg.LLM.Providers)
+	}
+
+	// Fallbacks are validated like the primary
+	yaml = `
+bitbucket:
+  email: user@example.com
+  api_token: token1
+  workspace: ws1
+  repo_slug: repo
+llm:
+  provider: openai
+  providers:
+    - provider: gemini
+prompt_file: ` + promptFile + `
+`
+	_, err = LoadConfigWithOverrides(writeTempConfigFile(t, yaml), "", "", "")
+	if err == nil || !strings.Contains(err.Error(), "llm.providers[0].api_key") {
+		t.Errorf("expected a missing fallback API key error, got: %v", err)
+	}
+}
+
+func TestLoadConfigWithOverrides_SecretFiles(t *testing.T) {
+	os.Unsetenv("BITBUCKET_API_TOKEN")
+	os.Unsetenv("LLM_PROVIDER")
+	os.Unsetenv("LLM_API_KEY")
+	os.Unsetenv("PULLREVIEW_PROMPT_FILE")
+	os.Unsetenv("PULLREVIEW_WEBHOOK_SECRET")
+	tmpDir := t.TempDir()
+	promptFile := writeTempPromptFile(t, tmpDir)
+	writeSecret := func(name, content string) string {
+		path := filepath.Join(tmpDir, name)
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatalf("failed to write secret file: %v", err)
+		}
+		return path
+	}
+	tokenFile := writeSecret("bb_token", "file-token\n")
+	keyFile := writeSecret("llm_key", "file-key\r\n")
+	fallbackKeyFile := writeSecret("fallback_key", "fallback-key")
+	secretFile := writeSecret("webhook_secret", "  spaced secret\n\n")
+
+	yaml := `
+bitbucket:
+  email: user@example.com
+  api_token: inline-token
+  api_token_file: ` + tokenFile + `
+  workspace: ws1
+  repo_slug: repo
+llm:
+  provider: openai
+  api_key: inline-key
+  api_key_file: ` + keyFile + `
+  providers:
+    - provider: anthropic
+      api_key_file: ` + fallbackKeyFile + `
+webhook:
+  secret_file: ` + secretFile + `
+prompt_file: ` + promptFile + `
+`
+	cfgFile := writeTempConfigFile(t, yaml)
+	cfg, err := LoadConfigWithOverrides(cfgFile, "", "", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Bitbucket.APIToken != "file-token" {
+		t.Errorf("expected api_token_file to override api_token, got '%s'", cfg.Bitbucket.APIToken)
+	}
+	if cfg.LLM.APIKey != "file-key" {
+		t.Errorf("expected api_key_file to override api_key with newlines trimmed, got '%s'", cfg.LLM.APIKey)
+	}
+	if cfg.LLM.Providers[0].APIKey != "fallback-key" {
+		t.Errorf("expected the fallback's api_key_file to be read, got '%s'", cfg.LLM.Providers[0].APIKey)
+	}
+	if cfg.Webhook.Secret != "  spaced secret" {
+		t.Errorf("expected only trailing newlines to be trimmed, got '%s'", cfg.Webhook.Secret)
+	}
+
+	// Env vars and CLI flags still take precedence over secret files
+	os.Setenv("LLM_API_KEY", "env-key")
+	defer os.Unsetenv("LLM_API_KEY")
+	cfg, err = LoadConfigWithOverrides(cfgFile, "", "cli-token", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.LLM.APIKey != "env-key" || cfg.Bitbucket.APIToken != "cli-token" {
+		t.Errorf("expected env and CLI to override secret files, got key '%s' and token '%s'", cfg.LLM.APIKey, cfg.Bitbucket.APIToken)
+	}
+
+	// A missing secret file is an error, not a silent fallback to the inline value
+	os.Remove(keyFile)
+	_, err = LoadConfigWithOverrides(cfgFile, "", "", "")
+	if err == nil || !strings.Contains(err.Error(), "llm.api_key_file") {
+		t.Errorf("expected an error naming llm.api_key_file, got: %v", err)
+	}
+}
+
+func TestLoadConfigWithOverrides_SecretFileRelativeToConfig(t *testing.T) {
+	for _, k := range []string{"LLM_PROVIDER", "LLM_API_KEY", "PULLREVIEW_PROMPT_FILE"} {
+		t.Setenv(k, "")
+	}
+	cfgDir := t.TempDir()
+	promptFile := writeTempPromptFile(t, cfgDir)
+	if err := os.MkdirAll(filepath.Join(cfgDir, "secrets"), 0o700); err != nil {
+		t.Fatalf("failed to create secrets dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(cfgDir, "secrets", "llm_key"), []byte("relative-key\n"), 0o600); err != nil {
+		t.Fatalf("failed to write secret file: %v", err)
+	}
+	cfgFile := filepath.Join(cfgDir, "pullreview.yaml")
+	yaml := `
+bitbucket:
+  email: user@example.com
+  api_token: token1
+  workspace: ws1
+  repo_slug: repo
+llm:
+  provider: openai
+  api_key_file: secrets/llm_key
+prompt_file: ` + promptFile + `
+`
+	if err := os.WriteFile(cfgFile, []byte(yaml), 0o644); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+
+	// Run from elsewhere, so a cwd-relative lookup would fail
+	t.Chdir(t.TempDir())
+	cfg, err := LoadConfigWithOverrides(cfgFile, "", "", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.LLM.APIKey != "relative-key" {
+		t.Errorf("expected api_key_file to be read next to the config file, got '%s'", cfg.LLM.APIKey)
+	}
+}
+
+func TestLoadConfigWithOverrides_GitHubProvider(t *testing.T) {
+	for _, k := range []string{"BITBUCKET_EMAIL", "BITBUCKET_API_TOKEN", "BITBUCKET_WORKSPACE", "LLM_PROVIDER", "LLM_API_KEY", "PULLREVIEW_PROMPT_FILE", "PULLREVIEW_PROVIDER", "GITHUB_TOKEN", "GITHUB_API_URL"} {
+		t.Setenv(k, "")
+	}
+	tmpDir := t.TempDir()
+	promptFile := writeTempPromptFile(t, tmpDir)
+	tokenFile := filepath.Join(tmpDir, "gh_token")
+	if err := os.WriteFile(tokenFile, []byte("file-token\n"), 0600); err != nil {
+		t.Fatalf("failed to write token file: %v", err)
+	}
+
+	// No bitbucket settings are needed for GitHub
+	yaml := `
+provider: GitHub
+github:
+  token_file: ` + tokenFile + `
+  owner: octo
+  repo: app
+llm:
+  provider: openai
+  api_key: key
+prompt_file: ` + promptFile + `
+`
+	cfgFile := writeTempConfigFile(t, yaml)
+	cfg, err := LoadConfigWithOverrides(cfgFile, "", "", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Provider != ProviderGitHub || cfg.GitHub.Token != "file-token" || cfg.GitHub.Owner != "octo" || cfg.GitHub.Repo != "app" {
+		t.Errorf("unexpected github config: provider %q, %+v", cfg.Provider, cfg.GitHub)
+	}
+
+	// GitHub Actions variables override the file
+	t.Setenv("GITHUB_REPOSITORY", "other/service")
+	t.Setenv("GITHUB_API_URL", "https://ghe.example.com/api/v3")
+	cfg, err = LoadConfigWithOverrides(cfgFile, "", "", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.GitHub.Owner != "other" || cfg.GitHub.Repo != "service" || cfg.GitHub.BaseURL != "https://ghe.example.com/api/v3" {
+		t.Errorf("expected env overrides, got %+v", cfg.GitHub)
+	}
+
+	t.Setenv("GITHUB_REPOSITORY", "")
+	missing := writeTempConfigFile(t, "provider: github\nllm:\n  provider: openai\n  api_key: key\nprompt_file: "+promptFile+"\n")
+	if _, err := LoadConfigWithOverrides(missing, "", "", ""); err == nil || !strings.Contains(err.Error(), "github.token") || strings.Contains(err.Error(), "bitbucket") {
+		t.Errorf("expected only github settings reported missing, got %v", err)
+	}
+
+	invalid := writeTempConfigFile(t, "provider: gerrit\n")
+	if _, err := LoadConfigWithOverrides(invalid, "", "", ""); err == nil || !strings.Contains(err.Error(), "invalid provider") {
+		t.Errorf("expected invalid provider error, got %v", err)
+	}
+}
+
+func TestLoadConfigWithOverrides_GitLabProvider(t *testing.T) {
+	for _, k := range []string{"BITBUCKET_EMAIL", "BITBUCKET_API_TOKEN", "BITBUCKET_WORKSPACE", "LLM_PROVIDER", "LLM_API_KEY", "PULLREVIEW_PROMPT_FILE", "PULLREVIEW_PROVIDER", "GITLAB_TOKEN", "GITLAB_PROJECT", "CI_PROJECT_ID", "CI_API_V4_URL"} {
+		t.Setenv(k, "")
+	}
+	tmpDir := t.TempDir()
+	promptFile := writeTempPromptFile(t, tmpDir)
+	tokenFile := filepath.Join(tmpDir, "gl_token")
+	if err := os.WriteFile(tokenFile, []byte("file-token\n"), 0600); err != nil {
+		t.Fatalf("failed to write token file: %v", err)
+	}
+
+	// No bitbucket settings are needed for GitLab
+	yaml := `
+provider: gitlab
+gitlab:
+  token_file: ` + tokenFile + `
+  project: group/app
+llm:
+  provider: openai
+  api_key: key
+prompt_file: ` + promptFile + `
+`
+	cfgFile := writeTempConfigFile(t, yaml)
+	cfg, err := LoadConfigWithOverrides(cfgFile, "", "", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Provider != ProviderGitLab || cfg.GitLab.Token != "file-token" || cfg.GitLab.Project != "group/app" {
+		t.Errorf("unexpected gitlab config: provider %q, %+v", cfg.Provider, cfg.GitLab)
+	}
+
+	// GitLab CI variables override the file, and GITLAB_PROJECT overrides CI_PROJECT_ID
+	t.Setenv("CI_PROJECT_ID", "42")
+	t.Setenv("CI_API_V4_URL", "https://gitlab.example.com/api/v4")
+	cfg, err = LoadConfigWithOverrides(cfgFile, "", "", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.GitLab.Project != "42" || cfg.GitLab.BaseURL != "https://gitlab.example.com/api/v4" {
+		t.Errorf("expected CI overrides, got %+v", cfg.GitLab)
+	}
+	t.Setenv("GITLAB_PROJECT", "group/other")
+	cfg, err = LoadConfigWithOverrides(cfgFile, "", "", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.GitLab.Project != "group/other" {
+		t.Errorf("expected GITLAB_PROJECT to win, got %q", cfg.GitLab.Project)
+	}
+
+	t.Setenv("GITLAB_PROJECT", "")
+	t.Setenv("CI_PROJECT_ID", "")
+	missing := writeTempConfigFile(t, "provider: gitlab\nllm:\n  provider: openai\n  api_key: key\nprompt_file: "+promptFile+"\n")
+	if _, err := LoadConfigWithOverrides(missing, "", "", ""); err == nil || !strings.Contains(err.Error(), "gitlab.project") || strings.Contains(err.Error(), "bitbucket") {
+		t.Errorf("expected only gitlab settings reported missing, got %v", err)
+	}
+}
+
+func TestLoadLocalConfig_NoCodeHost(t *testing.T) {
+	for _, k := range []string{"BITBUCKET_EMAIL", "BITBUCKET_API_TOKEN", "BITBUCKET_WORKSPACE", "BITBUCKET_REPO_SLUG",
+		"LLM_PROVIDER", "LLM_API_KEY", "PULLREVIEW_PROMPT_FILE"} {
+		t.Setenv(k, "")
+	}
+	promptFile := writeTempPromptFile(t, t.TempDir())
+	cfgFile := writeTempConfigFile(t, `
+llm:
+  provider: openai
+  api_key: key1
+prompt_file: `+promptFile+`
+`)
+	if _, err := LoadConfigWithOverrides(cfgFile, "", "", ""); err == nil || !strings.Contains(err.Error(), "bitbucket.email") {
+		t.Errorf("expected missing Bitbucket settings, got %v", err)
+	}
+	if _, err := LoadLocalConfig(cfgFile); err != nil {
+		t.Errorf("LoadLocalConfig should not require Bitbucket settings: %v", err)
+	}
+
+	// The LLM settings are still required
+	if _, err := LoadLocalConfig(writeTempConfigFile(t, "prompt_file: "+promptFile+"\n")); err == nil || !strings.Contains(err.Error(), "llm.provider") {
+		t.Errorf("expected missing LLM settings, got %v", err)
+	}
+}
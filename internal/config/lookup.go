@@ -0,0 +1,56 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// DefaultConfigName is the config file name looked up in the current directory and next to
+// the executable.
+const DefaultConfigName = "pullreview.yaml"
+
+// FindConfigFile resolves the config file to load. An explicit path (from --config) always
+// wins and is returned as-is, even if it does not exist, so loading it reports the error.
+// Otherwise the first existing file in this order is returned:
+//
+//  1. ./pullreview.yaml
+//  2. $XDG_CONFIG_HOME/pullreview/config.yaml (the user config directory if unset)
+//  3. pullreview.yaml next to the executable
+//
+// An empty path means no config file was found and settings come from env vars and flags.
+func FindConfigFile(explicit string) string {
+	if explicit != "" {
+		return explicit
+	}
+	exeDir := ""
+	if exePath, err := os.Executable(); err == nil {
+		exeDir = filepath.Dir(exePath)
+	}
+	return findConfigFile(configSearchPaths(exeDir))
+}
+
+// configSearchPaths returns the candidate config files in lookup order.
+func configSearchPaths(exeDir string) []string {
+	paths := []string{DefaultConfigName}
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		configHome, _ = os.UserConfigDir()
+	}
+	if configHome != "" {
+		paths = append(paths, filepath.Join(configHome, "pullreview", "config.yaml"))
+	}
+	if exeDir != "" {
+		paths = append(paths, filepath.Join(exeDir, DefaultConfigName))
+	}
+	return paths
+}
+
+// findConfigFile returns the first of paths that is an existing regular file, or "".
+func findConfigFile(paths []string) string {
+	for _, p := range paths {
+		if info, err := os.Stat(p); err == nil && info.Mode().IsRegular() {
+			return p
+		}
+	}
+	return ""
+}
@@ -0,0 +1,77 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeFile creates path (and its parent directories) with placeholder content.
+func writeFile(t *testing.T, path string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("failed to create %s: %v", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, []byte("prompt_file: prompt.md\n"), 0644); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+}
+
+func TestFindConfigFile_Precedence(t *testing.T) {
+	workDir := t.TempDir()
+	xdgHome := t.TempDir()
+	exeDir := t.TempDir()
+	t.Chdir(workDir)
+	t.Setenv("XDG_CONFIG_HOME", xdgHome)
+
+	cwdConfig := filepath.Join(workDir, DefaultConfigName)
+	xdgConfig := filepath.Join(xdgHome, "pullreview", "config.yaml")
+	exeConfig := filepath.Join(exeDir, DefaultConfigName)
+	find := func() string {
+		got := findConfigFile(configSearchPaths(exeDir))
+		if got == "" {
+			return ""
+		}
+		abs, err := filepath.Abs(got)
+		if err != nil {
+			t.Fatalf("filepath.Abs(%q) failed: %v", got, err)
+		}
+		return abs
+	}
+
+	if got := find(); got != "" {
+		t.Errorf("expected no config file, got %q", got)
+	}
+	writeFile(t, exeConfig)
+	if got := find(); got != exeConfig {
+		t.Errorf("expected the executable's config %q, got %q", exeConfig, got)
+	}
+	writeFile(t, xdgConfig)
+	if got := find(); got != xdgConfig {
+		t.Errorf("expected the XDG config %q to win over the executable's, got %q", xdgConfig, got)
+	}
+	writeFile(t, cwdConfig)
+	if got := find(); got != cwdConfig {
+		t.Errorf("expected the current directory's config %q to win, got %q", cwdConfig, got)
+	}
+	if got := FindConfigFile("custom.yaml"); got != "custom.yaml" {
+		t.Errorf("expected an explicit --config to win, got %q", got)
+	}
+}
+
+func TestFindConfigFile_SkipsDirectories(t *testing.T) {
+	workDir := t.TempDir()
+	exeDir := t.TempDir()
+	t.Chdir(workDir)
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	// A directory named pullreview.yaml must not shadow the real config further down the list
+	if err := os.Mkdir(filepath.Join(workDir, DefaultConfigName), 0755); err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+	exeConfig := filepath.Join(exeDir, DefaultConfigName)
+	writeFile(t, exeConfig)
+	if got := findConfigFile(configSearchPaths(exeDir)); got != exeConfig {
+		t.Errorf("expected %q, got %q", exeConfig, got)
+	}
+}
@@ -0,0 +1,235 @@
+// Package github posts pullreview results to GitHub pull requests through the GitHub REST API.
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"pullreview/internal/provider"
+)
+
+// DefaultBaseURL is the GitHub.com REST API. GitHub Enterprise Server uses
+// https://<host>/api/v3.
+const DefaultBaseURL = "https://api.github.com"
+
+// apiVersion is the REST API version requested with every call.
+const apiVersion = "2022-11-28"
+
+// Client provides the posting layer for GitHub pull requests.
+type Client struct {
+	Token   string // Personal access token or GITHUB_TOKEN, sent as a bearer token
+	Owner   string // Repository owner (user or organization)
+	Repo    string // Repository name
+	BaseURL string
+
+	mu      sync.Mutex
+	prCache map[string]pullRequest // PR number -> head commit and description
+}
+
+var _ provider.Provider = (*Client)(nil)
+
+// NewClient creates a GitHub API client for owner/repo. An empty baseURL means GitHub.com.
+func NewClient(token, owner, repo, baseURL string) *Client {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	return &Client{
+		Token:   token,
+		Owner:   strings.TrimSpace(owner),
+		Repo:    strings.TrimSpace(repo),
+		BaseURL: strings.TrimRight(baseURL, "/"),
+	}
+}
+
+// Validate checks that the client has what every call needs.
+func (c *Client) Validate() error {
+	switch {
+	case c.Token == "":
+		return errors.New("github token is required")
+	case c.Owner == "" || c.Repo == "":
+		return errors.New("github owner and repo are required")
+	case strings.ContainsAny(c.Owner+c.Repo, "/ "):
+		return fmt.Errorf("github owner %q and repo %q must be bare names, not a path or URL", c.Owner, c.Repo)
+	}
+	return nil
+}
+
+func (c *Client) pullURL(prID string) string {
+	return fmt.Sprintf("%s/repos/%s/%s/pulls/%s", c.BaseURL, c.Owner, c.Repo, prID)
+}
+
+// newRequest builds an authenticated API request; accept overrides the default JSON media type.
+func (c *Client) newRequest(ctx context.Context, method, url, accept string, body any) (*http.Request, error) {
+	var r io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		r = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, r)
+	if err != nil {
+		return nil, err
+	}
+	if accept == "" {
+		accept = "application/vnd.github+json"
+	}
+	req.Header.Set("Accept", accept)
+	req.Header.Set("Authorization", "Bearer "+c.Token)
+	req.Header.Set("X-GitHub-Api-Version", apiVersion)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return req, nil
+}
+
+// GetPRDiff fetches the unified diff for the PR with the given number. GitHub refuses to
+// render diffs that are too large; that surfaces as an *APIError with status 406.
+func (c *Client) GetPRDiff(ctx context.Context, prID string) (string, error) {
+	if prID == "" {
+		return "", errors.New("PR number is required")
+	}
+	req, err := c.newRequest(ctx, "GET", c.pullURL(prID), "application/vnd.github.diff", nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create PR diff request: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to contact GitHub API: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to fetch PR diff: %w", newAPIError(req.URL.String(), resp))
+	}
+	diff, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read PR diff: %w", err)
+	}
+	return string(diff), nil
+}
+
+// pullRequest is what the client needs from a PR beyond its diff.
+type pullRequest struct {
+	HeadSHA string // Head commit, which review comments must be anchored to
+	Title   string
+	Body    string
+}
+
+// pullRequest returns the PR's head commit, title and body. They are fetched once per PR.
+func (c *Client) pullRequest(ctx context.Context, prID string) (pullRequest, error) {
+	c.mu.Lock()
+	pr, ok := c.prCache[prID]
+	c.mu.Unlock()
+	if ok {
+		return pr, nil
+	}
+	req, err := c.newRequest(ctx, "GET", c.pullURL(prID), "", nil)
+	if err != nil {
+		return pullRequest{}, fmt.Errorf("failed to create PR request: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return pullRequest{}, fmt.Errorf("failed to contact GitHub API: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return pullRequest{}, fmt.Errorf("failed to fetch PR: %w", newAPIError(req.URL.String(), resp))
+	}
+	var data struct {
+		Title string `json:"title"`
+		Body  string `json:"body"`
+		Head  struct {
+			SHA string `json:"sha"`
+		} `json:"head"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		return pullRequest{}, fmt.Errorf("failed to decode PR: %w", err)
+	}
+	if data.Head.SHA == "" {
+		return pullRequest{}, errors.New("PR response has no head commit")
+	}
+	pr = pullRequest{HeadSHA: data.Head.SHA, Title: data.Title, Body: data.Body}
+	c.mu.Lock()
+	if c.prCache == nil {
+		c.prCache = make(map[string]pullRequest)
+	}
+	c.prCache[prID] = pr
+	c.mu.Unlock()
+	return pr, nil
+}
+
+// GetPRInfo returns the title and description (body) of the PR with the given number.
+func (c *Client) GetPRInfo(ctx context.Context, prID string) (provider.PRInfo, error) {
+	if prID == "" {
+		return provider.PRInfo{}, errors.New("PR number is required")
+	}
+	pr, err := c.pullRequest(ctx, prID)
+	if err != nil {
+		return provider.PRInfo{}, err
+	}
+	return provider.PRInfo{Title: pr.Title, Description: pr.Body}, nil
+}
+
+// PostInlineComment posts a review comment on a line of the PR diff: the new file's Line, or
+// for deleted lines the old file's FromLine (the diff's left side).
+func (c *Client) PostInlineComment(ctx context.Context, prID string, cmt provider.Comment) error {
+	if prID == "" || cmt.FilePath == "" || (cmt.Line <= 0 && cmt.FromLine <= 0) || cmt.Text == "" {
+		return errors.New("missing required fields for inline comment")
+	}
+	pr, err := c.pullRequest(ctx, prID)
+	if err != nil {
+		return fmt.Errorf("failed to post inline comment: %w", err)
+	}
+	body := map[string]any{
+		"body":      cmt.Text,
+		"commit_id": pr.HeadSHA,
+		"path":      cmt.FilePath,
+		"line":      cmt.Line,
+		"side":      "RIGHT",
+	}
+	if cmt.Line <= 0 {
+		body["line"], body["side"] = cmt.FromLine, "LEFT"
+	}
+	req, err := c.newRequest(ctx, "POST", c.pullURL(prID)+"/comments", "", body)
+	if err != nil {
+		return fmt.Errorf("failed to create inline comment request: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post inline comment: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		return fmt.Errorf("failed to post inline comment: %w", newAPIError(req.URL.String(), resp))
+	}
+	return nil
+}
+
+// PostSummaryComment posts a top-level comment on the PR's conversation.
+func (c *Client) PostSummaryComment(ctx context.Context, prID, text string) error {
+	if prID == "" || text == "" {
+		return errors.New("missing required fields for summary comment")
+	}
+	url := fmt.Sprintf("%s/repos/%s/%s/issues/%s/comments", c.BaseURL, c.Owner, c.Repo, prID)
+	req, err := c.newRequest(ctx, "POST", url, "", map[string]string{"body": text})
+	if err != nil {
+		return fmt.Errorf("failed to create summary comment request: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post summary comment: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		return fmt.Errorf("failed to post summary comment: %w", newAPIError(req.URL.String(), resp))
+	}
+	return nil
+}
@@ -0,0 +1,207 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+
+	"pullreview/internal/provider"
+)
+
+// recordedRequest is a request seen by routeRoundTripper.
+type recordedRequest struct {
+	Method string
+	URL    string
+	Header http.Header
+	Body   map[string]any
+}
+
+// routeRoundTripper answers requests by "METHOD URL" with a status code and body, recording
+// each request.
+type routeRoundTripper struct {
+	routes   map[string]route
+	requests []recordedRequest
+}
+
+type route struct {
+	code int
+	body string
+}
+
+func (m *routeRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	rec := recordedRequest{Method: req.Method, URL: req.URL.String(), Header: req.Header}
+	if req.Body != nil {
+		data, _ := io.ReadAll(req.Body)
+		_ = json.Unmarshal(data, &rec.Body)
+	}
+	m.requests = append(m.requests, rec)
+	r, ok := m.routes[req.Method+" "+req.URL.String()]
+	if !ok {
+		r = route{http.StatusNotFound, `{"message": "Not Found"}`}
+	}
+	return &http.Response{
+		StatusCode: r.code,
+		Body:       io.NopCloser(bytes.NewBufferString(r.body)),
+		Header:     make(http.Header),
+	}, nil
+}
+
+func withTransport(t *testing.T, rt http.RoundTripper) {
+	t.Helper()
+	orig := http.DefaultClient.Transport
+	http.DefaultClient.Transport = rt
+	t.Cleanup(func() { http.DefaultClient.Transport = orig })
+}
+
+const pullURL = "https://api.github.com/repos/octo/app/pulls/12"
+
+func TestGetPRDiff(t *testing.T) {
+	diff := "diff --git a/main.go b/main.go\n--- a/main.go\n+++ b/main.go\n@@ -1 +1 @@\n-a\n+b\n"
+	mock := &routeRoundTripper{routes: map[string]route{"GET " + pullURL: {http.StatusOK, diff}}}
+	withTransport(t, mock)
+
+	c := NewClient("tok", "octo", "app", "")
+	got, err := c.GetPRDiff(context.Background(), "12")
+	if err != nil {
+		t.Fatalf("GetPRDiff failed: %v", err)
+	}
+	if got != diff {
+		t.Errorf("unexpected diff: %q", got)
+	}
+	h := mock.requests[0].Header
+	if h.Get("Accept") != "application/vnd.github.diff" || h.Get("Authorization") != "Bearer tok" || h.Get("X-GitHub-Api-Version") == "" {
+		t.Errorf("unexpected headers: %v", h)
+	}
+}
+
+func TestGetPRDiff_TooLarge(t *testing.T) {
+	withTransport(t, &routeRoundTripper{routes: map[string]route{
+		"GET " + pullURL: {http.StatusNotAcceptable, `{"message": "Sorry, the diff exceeded the maximum number of files"}`},
+	}})
+	_, err := NewClient("tok", "octo", "app", "").GetPRDiff(context.Background(), "12")
+	var apiErr *APIError
+	if !errors.Is(err, ErrDiffTooLarge) || !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotAcceptable {
+		t.Errorf("expected ErrDiffTooLarge APIError, got %v", err)
+	}
+}
+
+func TestGetPRInfo(t *testing.T) {
+	mock := &routeRoundTripper{routes: map[string]route{
+		"GET " + pullURL:                {http.StatusOK, `{"number": 12, "title": "Add cache", "body": "Caches responses.", "head": {"sha": "abc123"}}`},
+		"POST " + pullURL + "/comments": {http.StatusCreated, `{"id": 1}`},
+	}}
+	withTransport(t, mock)
+
+	c := NewClient("tok", "octo", "app", "")
+	ctx := context.Background()
+	info, err := c.GetPRInfo(ctx, "12")
+	if err != nil {
+		t.Fatalf("GetPRInfo failed: %v", err)
+	}
+	if info.Title != "Add cache" || info.Description != "Caches responses." {
+		t.Errorf("unexpected PR info %+v", info)
+	}
+	// The PR fetched for its description also provides the head commit for comments
+	if err := c.PostInlineComment(ctx, "12", provider.Comment{FilePath: "main.go", Line: 7, Text: "x"}); err != nil {
+		t.Fatalf("PostInlineComment failed: %v", err)
+	}
+	if len(mock.requests) != 2 || mock.requests[1].Body["commit_id"] != "abc123" {
+		t.Errorf("expected one PR fetch and one post, got %+v", mock.requests)
+	}
+}
+
+func TestPostInlineComment(t *testing.T) {
+	mock := &routeRoundTripper{routes: map[string]route{
+		"GET " + pullURL:                {http.StatusOK, `{"number": 12, "head": {"sha": "abc123"}}`},
+		"POST " + pullURL + "/comments": {http.StatusCreated, `{"id": 1}`},
+	}}
+	withTransport(t, mock)
+
+	c := NewClient("tok", "octo", "app", "")
+	ctx := context.Background()
+	if err := c.PostInlineComment(ctx, "12", provider.Comment{FilePath: "main.go", Line: 7, Text: "Check the error."}); err != nil {
+		t.Fatalf("PostInlineComment failed: %v", err)
+	}
+	if err := c.PostInlineComment(ctx, "12", provider.Comment{FilePath: "old.go", FromLine: 3, Text: "Why remove this?"}); err != nil {
+		t.Fatalf("PostInlineComment (deleted line) failed: %v", err)
+	}
+
+	// The head commit is fetched once and reused
+	if len(mock.requests) != 3 || mock.requests[0].Method != "GET" {
+		t.Fatalf("expected one PR fetch and two posts, got %+v", mock.requests)
+	}
+	want := []map[string]any{
+		{"body": "Check the error.", "commit_id": "abc123", "path": "main.go", "line": float64(7), "side": "RIGHT"},
+		{"body": "Why remove this?", "commit_id": "abc123", "path": "old.go", "line": float64(3), "side": "LEFT"},
+	}
+	for i, w := range want {
+		got := mock.requests[i+1].Body
+		if len(got) != len(w) {
+			t.Errorf("comment %d: unexpected body %v", i, got)
+			continue
+		}
+		for k, v := range w {
+			if got[k] != v {
+				t.Errorf("comment %d: %s = %v, want %v", i, k, got[k], v)
+			}
+		}
+	}
+}
+
+func TestPostInlineComment_Errors(t *testing.T) {
+	withTransport(t, &routeRoundTripper{routes: map[string]route{
+		"GET " + pullURL:                {http.StatusOK, `{"head": {"sha": "abc123"}}`},
+		"POST " + pullURL + "/comments": {http.StatusUnprocessableEntity, `{"message": "line must be part of the diff"}`},
+	}})
+	c := NewClient("tok", "octo", "app", "")
+	if err := c.PostInlineComment(context.Background(), "12", provider.Comment{FilePath: "main.go", Text: "x"}); err == nil {
+		t.Error("expected error for a comment without a line")
+	}
+	err := c.PostInlineComment(context.Background(), "12", provider.Comment{FilePath: "main.go", Line: 99, Text: "x"})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("expected 422 APIError, got %v", err)
+	}
+}
+
+func TestPostSummaryComment(t *testing.T) {
+	issueURL := "https://ghe.example.com/api/v3/repos/octo/app/issues/12/comments"
+	mock := &routeRoundTripper{routes: map[string]route{"POST " + issueURL: {http.StatusCreated, `{"id": 2}`}}}
+	withTransport(t, mock)
+
+	c := NewClient("tok", "octo", "app", "https://ghe.example.com/api/v3/")
+	if err := c.PostSummaryComment(context.Background(), "12", "Looks good overall."); err != nil {
+		t.Fatalf("PostSummaryComment failed: %v", err)
+	}
+	req := mock.requests[0]
+	if len(req.Body) != 1 || req.Body["body"] != "Looks good overall." {
+		t.Errorf("unexpected body: %v", req.Body)
+	}
+	if req.Header.Get("Content-Type") != "application/json" || req.Header.Get("Accept") != "application/vnd.github+json" {
+		t.Errorf("unexpected headers: %v", req.Header)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		client  *Client
+		wantErr bool
+	}{
+		{"valid", NewClient("tok", "octo", "app", ""), false},
+		{"no token", NewClient("", "octo", "app", ""), true},
+		{"no repo", NewClient("tok", "octo", "", ""), true},
+		{"repo is a path", NewClient("tok", "octo", "octo/app", ""), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.client.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
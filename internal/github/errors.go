@@ -0,0 +1,57 @@
+package github
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Sentinel errors matched by *APIError via errors.Is.
+var (
+	ErrNotFound     = errors.New("github resource not found")
+	ErrUnauthorized = errors.New("github credentials rejected")
+	ErrForbidden    = errors.New("github access denied or rate limited")
+	ErrDiffTooLarge = errors.New("PR diff is too large for GitHub to render")
+)
+
+// maxErrorBodyBytes caps how much of an error response body is kept on an APIError.
+const maxErrorBodyBytes = 4096
+
+// APIError describes a non-successful response from the GitHub API.
+type APIError struct {
+	StatusCode int
+	Body       string // Response body, truncated to maxErrorBodyBytes
+	Endpoint   string // Request URL
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("status %d from %s, response: %s", e.StatusCode, e.Endpoint, e.Body)
+}
+
+// Unwrap maps the status code onto one of the sentinel errors, or nil if none applies.
+// GitHub reports exhausted rate limits as 403 (or 429), so both map to ErrForbidden.
+func (e *APIError) Unwrap() error {
+	switch e.StatusCode {
+	case http.StatusNotFound:
+		return ErrNotFound
+	case http.StatusUnauthorized:
+		return ErrUnauthorized
+	case http.StatusForbidden, http.StatusTooManyRequests:
+		return ErrForbidden
+	case http.StatusNotAcceptable:
+		return ErrDiffTooLarge
+	default:
+		return nil
+	}
+}
+
+// newAPIError builds an *APIError from an unexpected response, consuming (part of) its body.
+func newAPIError(endpoint string, resp *http.Response) *APIError {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodyBytes))
+	return &APIError{
+		StatusCode: resp.StatusCode,
+		Body:       string(body),
+		Endpoint:   endpoint,
+	}
+}
@@ -0,0 +1,296 @@
+// Package gitlab posts pullreview results to GitLab merge requests through the GitLab REST API.
+package gitlab
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"pullreview/internal/provider"
+)
+
+// DefaultBaseURL is the GitLab.com REST API. Self-managed instances use
+// https://<host>/api/v4.
+const DefaultBaseURL = "https://gitlab.com/api/v4"
+
+// diffsPerPage is the page size requested from the merge request diffs endpoint.
+const diffsPerPage = 100
+
+// Client provides the posting layer for GitLab merge requests. Merge requests are identified
+// by their IID, the number shown in the GitLab UI.
+type Client struct {
+	Token   string // Personal, project, or group access token, sent as PRIVATE-TOKEN
+	Project string // Project ID or full path (e.g. "group/subgroup/project")
+	BaseURL string
+
+	mu      sync.Mutex
+	mrCache map[string]*mergeRequest // IID -> diff refs and renamed paths, for inline positions
+}
+
+var _ provider.Provider = (*Client)(nil)
+
+// mergeRequest holds what inline comment positions need beyond the comment itself, and the
+// title and description for the review prompt.
+type mergeRequest struct {
+	BaseSHA     string
+	StartSHA    string
+	HeadSHA     string
+	OldPaths    map[string]string // New path -> old path, for renamed files
+	Title       string
+	Description string
+}
+
+// NewClient creates a GitLab API client for project. An empty baseURL means GitLab.com.
+func NewClient(token, project, baseURL string) *Client {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	return &Client{
+		Token:   token,
+		Project: strings.Trim(strings.TrimSpace(project), "/"),
+		BaseURL: strings.TrimRight(baseURL, "/"),
+	}
+}
+
+// Validate checks that the client has what every call needs.
+func (c *Client) Validate() error {
+	switch {
+	case c.Token == "":
+		return errors.New("gitlab token is required")
+	case c.Project == "":
+		return errors.New("gitlab project is required")
+	case strings.Contains(c.Project, "://"):
+		return fmt.Errorf("gitlab project %q must be an ID or path like group/project, not a URL", c.Project)
+	}
+	return nil
+}
+
+// mrURL returns the API URL of the merge request; the project path is escaped as one segment.
+func (c *Client) mrURL(iid string) string {
+	return fmt.Sprintf("%s/projects/%s/merge_requests/%s", c.BaseURL, url.PathEscape(c.Project), iid)
+}
+
+// do sends an authenticated request with an optional JSON body and returns the response if
+// its status is want; otherwise it returns an *APIError.
+func (c *Client) do(ctx context.Context, method, url string, body any, want int) (*http.Response, error) {
+	var r io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		r = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, r)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("PRIVATE-TOKEN", c.Token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to contact GitLab API: %w", err)
+	}
+	if resp.StatusCode != want {
+		defer resp.Body.Close()
+		return nil, newAPIError(req.URL.String(), resp)
+	}
+	return resp, nil
+}
+
+// fileDiff is an entry of the merge request diffs endpoint.
+type fileDiff struct {
+	OldPath     string `json:"old_path"`
+	NewPath     string `json:"new_path"`
+	Diff        string `json:"diff"`
+	NewFile     bool   `json:"new_file"`
+	RenamedFile bool   `json:"renamed_file"`
+	DeletedFile bool   `json:"deleted_file"`
+}
+
+// GetPRDiff fetches the merge request's changes and assembles them into a git-style unified
+// diff, since GitLab returns the hunks of each file separately.
+func (c *Client) GetPRDiff(ctx context.Context, iid string) (string, error) {
+	if iid == "" {
+		return "", errors.New("merge request IID is required")
+	}
+	var files []fileDiff
+	for page := "1"; page != ""; {
+		url := fmt.Sprintf("%s/diffs?per_page=%d&page=%s", c.mrURL(iid), diffsPerPage, page)
+		resp, err := c.do(ctx, "GET", url, nil, http.StatusOK)
+		if err != nil {
+			return "", fmt.Errorf("failed to fetch merge request diff: %w", err)
+		}
+		var batch []fileDiff
+		err = json.NewDecoder(resp.Body).Decode(&batch)
+		resp.Body.Close()
+		if err != nil {
+			return "", fmt.Errorf("failed to decode merge request diff: %w", err)
+		}
+		files = append(files, batch...)
+		page = resp.Header.Get("X-Next-Page")
+	}
+
+	oldPaths := make(map[string]string)
+	var b strings.Builder
+	for _, f := range files {
+		if f.RenamedFile {
+			oldPaths[f.NewPath] = f.OldPath
+		}
+		b.WriteString(formatFileDiff(f))
+	}
+	c.mu.Lock()
+	c.cached(iid).OldPaths = oldPaths
+	c.mu.Unlock()
+	return b.String(), nil
+}
+
+// cached returns the cache entry for the merge request, creating it if needed. c.mu must be held.
+func (c *Client) cached(iid string) *mergeRequest {
+	if c.mrCache == nil {
+		c.mrCache = make(map[string]*mergeRequest)
+	}
+	mr := c.mrCache[iid]
+	if mr == nil {
+		mr = &mergeRequest{}
+		c.mrCache[iid] = mr
+	}
+	return mr
+}
+
+// formatFileDiff renders one file of the merge request as a git-style diff section.
+func formatFileDiff(f fileDiff) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "diff --git a/%s b/%s\n", f.OldPath, f.NewPath)
+	oldName, newName := "a/"+f.OldPath, "b/"+f.NewPath
+	switch {
+	case f.NewFile:
+		b.WriteString("new file mode 100644\n")
+		oldName = "/dev/null"
+	case f.DeletedFile:
+		b.WriteString("deleted file mode 100644\n")
+		newName = "/dev/null"
+	case f.RenamedFile:
+		fmt.Fprintf(&b, "rename from %s\nrename to %s\n", f.OldPath, f.NewPath)
+	}
+	if f.Diff != "" {
+		fmt.Fprintf(&b, "--- %s\n+++ %s\n", oldName, newName)
+		b.WriteString(f.Diff)
+		if !strings.HasSuffix(f.Diff, "\n") {
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// mergeRequestInfo returns the merge request's diff refs, which inline positions must carry,
+// along with its title and description. They are fetched once per merge request.
+func (c *Client) mergeRequestInfo(ctx context.Context, iid string) (*mergeRequest, error) {
+	c.mu.Lock()
+	mr := c.cached(iid)
+	cachedRefs := mr.HeadSHA != ""
+	c.mu.Unlock()
+	if cachedRefs {
+		return mr, nil
+	}
+	resp, err := c.do(ctx, "GET", c.mrURL(iid), nil, http.StatusOK)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch merge request: %w", err)
+	}
+	defer resp.Body.Close()
+	var data struct {
+		Title       string `json:"title"`
+		Description string `json:"description"`
+		DiffRefs    struct {
+			BaseSHA  string `json:"base_sha"`
+			StartSHA string `json:"start_sha"`
+			HeadSHA  string `json:"head_sha"`
+		} `json:"diff_refs"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		return nil, fmt.Errorf("failed to decode merge request: %w", err)
+	}
+	if data.DiffRefs.HeadSHA == "" {
+		return nil, errors.New("merge request has no diff refs")
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	mr.BaseSHA, mr.StartSHA, mr.HeadSHA = data.DiffRefs.BaseSHA, data.DiffRefs.StartSHA, data.DiffRefs.HeadSHA
+	mr.Title, mr.Description = data.Title, data.Description
+	return mr, nil
+}
+
+// GetPRInfo returns the title and description of the merge request with the given IID.
+func (c *Client) GetPRInfo(ctx context.Context, iid string) (provider.PRInfo, error) {
+	if iid == "" {
+		return provider.PRInfo{}, errors.New("merge request IID is required")
+	}
+	mr, err := c.mergeRequestInfo(ctx, iid)
+	if err != nil {
+		return provider.PRInfo{}, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return provider.PRInfo{Title: mr.Title, Description: mr.Description}, nil
+}
+
+// PostInlineComment starts a discussion on a line of the merge request diff: the new file's
+// Line, or for deleted lines the old file's FromLine.
+func (c *Client) PostInlineComment(ctx context.Context, iid string, cmt provider.Comment) error {
+	if iid == "" || cmt.FilePath == "" || (cmt.Line <= 0 && cmt.FromLine <= 0) || cmt.Text == "" {
+		return errors.New("missing required fields for inline comment")
+	}
+	mr, err := c.mergeRequestInfo(ctx, iid)
+	if err != nil {
+		return fmt.Errorf("failed to post inline comment: %w", err)
+	}
+	c.mu.Lock()
+	refs := *mr
+	c.mu.Unlock()
+	oldPath := refs.OldPaths[cmt.FilePath]
+	if oldPath == "" {
+		oldPath = cmt.FilePath
+	}
+	position := map[string]any{
+		"position_type": "text",
+		"base_sha":      refs.BaseSHA,
+		"start_sha":     refs.StartSHA,
+		"head_sha":      refs.HeadSHA,
+		"old_path":      oldPath,
+		"new_path":      cmt.FilePath,
+	}
+	if cmt.Line > 0 {
+		position["new_line"] = cmt.Line
+	} else {
+		position["old_line"] = cmt.FromLine
+	}
+	body := map[string]any{"body": cmt.Text, "position": position}
+	resp, err := c.do(ctx, "POST", c.mrURL(iid)+"/discussions", body, http.StatusCreated)
+	if err != nil {
+		return fmt.Errorf("failed to post inline comment: %w", err)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// PostSummaryComment posts a note on the merge request's overview.
+func (c *Client) PostSummaryComment(ctx context.Context, iid, text string) error {
+	if iid == "" || text == "" {
+		return errors.New("missing required fields for summary comment")
+	}
+	resp, err := c.do(ctx, "POST", c.mrURL(iid)+"/notes", map[string]string{"body": text}, http.StatusCreated)
+	if err != nil {
+		return fmt.Errorf("failed to post summary comment: %w", err)
+	}
+	resp.Body.Close()
+	return nil
+}
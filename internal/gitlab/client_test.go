@@ -0,0 +1,218 @@
+package gitlab
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+
+	"pullreview/internal/provider"
+)
+
+// recordedRequest is a request seen by routeRoundTripper.
+type recordedRequest struct {
+	Method string
+	URL    string
+	Header http.Header
+	Body   map[string]any
+}
+
+// routeRoundTripper answers requests by "METHOD URL", recording each request.
+type routeRoundTripper struct {
+	routes   map[string]route
+	requests []recordedRequest
+}
+
+type route struct {
+	code     int
+	body     string
+	nextPage string // X-Next-Page header, for paginated responses
+}
+
+func (m *routeRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	rec := recordedRequest{Method: req.Method, URL: req.URL.String(), Header: req.Header}
+	if req.Body != nil {
+		data, _ := io.ReadAll(req.Body)
+		_ = json.Unmarshal(data, &rec.Body)
+	}
+	m.requests = append(m.requests, rec)
+	r, ok := m.routes[req.Method+" "+req.URL.String()]
+	if !ok {
+		r = route{code: http.StatusNotFound, body: `{"message": "404 Not Found"}`}
+	}
+	header := make(http.Header)
+	header.Set("X-Next-Page", r.nextPage)
+	return &http.Response{
+		StatusCode: r.code,
+		Body:       io.NopCloser(bytes.NewBufferString(r.body)),
+		Header:     header,
+	}, nil
+}
+
+func withTransport(t *testing.T, rt http.RoundTripper) {
+	t.Helper()
+	orig := http.DefaultClient.Transport
+	http.DefaultClient.Transport = rt
+	t.Cleanup(func() { http.DefaultClient.Transport = orig })
+}
+
+// mrURL is merge request !5 of group/sub/app, with the project path escaped as one segment.
+const mrURL = "https://gitlab.com/api/v4/projects/group%2Fsub%2Fapp/merge_requests/5"
+
+func TestGetPRDiff(t *testing.T) {
+	page1, _ := json.Marshal([]fileDiff{
+		{OldPath: "main.go", NewPath: "main.go", Diff: "@@ -1,2 +1,2 @@\n package main\n-var a = 1\n+var a = 2\n"},
+		{OldPath: "new.go", NewPath: "new.go", NewFile: true, Diff: "@@ -0,0 +1 @@\n+package main"},
+	})
+	page2, _ := json.Marshal([]fileDiff{
+		{OldPath: "old/name.go", NewPath: "new/name.go", RenamedFile: true},
+		{OldPath: "gone.go", NewPath: "gone.go", DeletedFile: true, Diff: "@@ -1 +0,0 @@\n-package gone\n"},
+	})
+	mock := &routeRoundTripper{routes: map[string]route{
+		"GET " + mrURL + "/diffs?per_page=100&page=1": {code: http.StatusOK, body: string(page1), nextPage: "2"},
+		"GET " + mrURL + "/diffs?per_page=100&page=2": {code: http.StatusOK, body: string(page2)},
+	}}
+	withTransport(t, mock)
+
+	c := NewClient("tok", "group/sub/app", "")
+	got, err := c.GetPRDiff(context.Background(), "5")
+	if err != nil {
+		t.Fatalf("GetPRDiff failed: %v", err)
+	}
+	want := "diff --git a/main.go b/main.go\n--- a/main.go\n+++ b/main.go\n@@ -1,2 +1,2 @@\n package main\n-var a = 1\n+var a = 2\n" +
+		"diff --git a/new.go b/new.go\nnew file mode 100644\n--- /dev/null\n+++ b/new.go\n@@ -0,0 +1 @@\n+package main\n" +
+		"diff --git a/old/name.go b/new/name.go\nrename from old/name.go\nrename to new/name.go\n" +
+		"diff --git a/gone.go b/gone.go\ndeleted file mode 100644\n--- a/gone.go\n+++ /dev/null\n@@ -1 +0,0 @@\n-package gone\n"
+	if got != want {
+		t.Errorf("unexpected diff:\n%s\nwant:\n%s", got, want)
+	}
+	if len(mock.requests) != 2 || mock.requests[0].Header.Get("PRIVATE-TOKEN") != "tok" {
+		t.Errorf("expected two authenticated page requests, got %+v", mock.requests)
+	}
+}
+
+func TestPostInlineComment_Positions(t *testing.T) {
+	diffs, _ := json.Marshal([]fileDiff{{OldPath: "old/name.go", NewPath: "new/name.go", RenamedFile: true, Diff: "@@ -1 +1 @@\n-a\n+b\n"}})
+	mock := &routeRoundTripper{routes: map[string]route{
+		"GET " + mrURL + "/diffs?per_page=100&page=1": {code: http.StatusOK, body: string(diffs)},
+		"GET " + mrURL:                   {code: http.StatusOK, body: `{"iid": 5, "diff_refs": {"base_sha": "base1", "start_sha": "start1", "head_sha": "head1"}}`},
+		"POST " + mrURL + "/discussions": {code: http.StatusCreated, body: `{"id": "d1"}`},
+	}}
+	withTransport(t, mock)
+
+	c := NewClient("tok", "group/sub/app", "")
+	ctx := context.Background()
+	if _, err := c.GetPRDiff(ctx, "5"); err != nil {
+		t.Fatalf("GetPRDiff failed: %v", err)
+	}
+	if err := c.PostInlineComment(ctx, "5", provider.Comment{FilePath: "new/name.go", Line: 1, Text: "Renamed and changed."}); err != nil {
+		t.Fatalf("PostInlineComment failed: %v", err)
+	}
+	if err := c.PostInlineComment(ctx, "5", provider.Comment{FilePath: "main.go", FromLine: 4, Text: "Why delete this?"}); err != nil {
+		t.Fatalf("PostInlineComment (deleted line) failed: %v", err)
+	}
+
+	// One diff page, one MR fetch (cached for the second comment), two discussions
+	if len(mock.requests) != 4 {
+		t.Fatalf("expected 4 requests, got %+v", mock.requests)
+	}
+	wants := []map[string]any{
+		{"position_type": "text", "base_sha": "base1", "start_sha": "start1", "head_sha": "head1",
+			"old_path": "old/name.go", "new_path": "new/name.go", "new_line": float64(1)},
+		{"position_type": "text", "base_sha": "base1", "start_sha": "start1", "head_sha": "head1",
+			"old_path": "main.go", "new_path": "main.go", "old_line": float64(4)},
+	}
+	for i, want := range wants {
+		body := mock.requests[i+2].Body
+		position, _ := body["position"].(map[string]any)
+		if len(position) != len(want) {
+			t.Errorf("discussion %d: unexpected position %v", i, position)
+			continue
+		}
+		for k, v := range want {
+			if position[k] != v {
+				t.Errorf("discussion %d: position.%s = %v, want %v", i, k, position[k], v)
+			}
+		}
+	}
+	if mock.requests[2].Body["body"] != "Renamed and changed." {
+		t.Errorf("unexpected discussion body: %v", mock.requests[2].Body)
+	}
+}
+
+func TestGetPRInfo(t *testing.T) {
+	mock := &routeRoundTripper{routes: map[string]route{
+		"GET " + mrURL: {code: http.StatusOK, body: `{"iid": 5, "title": "Add cache", "description": "Caches responses.", "diff_refs": {"base_sha": "b", "start_sha": "s", "head_sha": "h"}}`},
+	}}
+	withTransport(t, mock)
+
+	c := NewClient("tok", "group/sub/app", "")
+	info, err := c.GetPRInfo(context.Background(), "5")
+	if err != nil {
+		t.Fatalf("GetPRInfo failed: %v", err)
+	}
+	if info.Title != "Add cache" || info.Description != "Caches responses." {
+		t.Errorf("unexpected merge request info %+v", info)
+	}
+	// Cached along with the diff refs
+	if _, err := c.GetPRInfo(context.Background(), "5"); err != nil || len(mock.requests) != 1 {
+		t.Errorf("expected a single merge request fetch, got %d (err %v)", len(mock.requests), err)
+	}
+}
+
+func TestPostInlineComment_Errors(t *testing.T) {
+	withTransport(t, &routeRoundTripper{routes: map[string]route{
+		"GET " + mrURL: {code: http.StatusForbidden, body: `{"message": "403 Forbidden"}`},
+	}})
+	c := NewClient("tok", "group/sub/app", "")
+	if err := c.PostInlineComment(context.Background(), "5", provider.Comment{FilePath: "main.go", Text: "x"}); err == nil {
+		t.Error("expected error for a comment without a line")
+	}
+	err := c.PostInlineComment(context.Background(), "5", provider.Comment{FilePath: "main.go", Line: 1, Text: "x"})
+	var apiErr *APIError
+	if !errors.Is(err, ErrForbidden) || !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusForbidden {
+		t.Errorf("expected ErrForbidden APIError, got %v", err)
+	}
+}
+
+func TestPostSummaryComment(t *testing.T) {
+	notesURL := "https://gitlab.example.com/api/v4/projects/42/merge_requests/5/notes"
+	mock := &routeRoundTripper{routes: map[string]route{"POST " + notesURL: {code: http.StatusCreated, body: `{"id": 7}`}}}
+	withTransport(t, mock)
+
+	c := NewClient("tok", "42", "https://gitlab.example.com/api/v4/")
+	if err := c.PostSummaryComment(context.Background(), "5", "Overall fine."); err != nil {
+		t.Fatalf("PostSummaryComment failed: %v", err)
+	}
+	req := mock.requests[0]
+	if len(req.Body) != 1 || req.Body["body"] != "Overall fine." {
+		t.Errorf("unexpected note body: %v", req.Body)
+	}
+	if req.Header.Get("Content-Type") != "application/json" || req.Header.Get("PRIVATE-TOKEN") != "tok" {
+		t.Errorf("unexpected headers: %v", req.Header)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		client  *Client
+		wantErr bool
+	}{
+		{"path", NewClient("tok", "group/app", ""), false},
+		{"numeric ID", NewClient("tok", "42", ""), false},
+		{"no token", NewClient("", "group/app", ""), true},
+		{"no project", NewClient("tok", " / ", ""), true},
+		{"URL", NewClient("tok", "https://gitlab.com/group/app", ""), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.client.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+)
+
+const (
+	defaultAnthropicEndpoint = "https://api.anthropic.com/v1/messages"
+	defaultAnthropicModel    = "claude-3-5-sonnet-latest"
+	anthropicVersion         = "2023-06-01"
+)
+
+// sendAnthropic sends the prompt to Anthropic's Messages API and returns the response text.
+// The instructions go in the top-level system field and the prompt as the user message.
+func (c *Client) sendAnthropic(ctx context.Context, system, prompt string) (*ReviewResponse, error) {
+	if c.APIKey == "" {
+		return nil, errors.New("missing Anthropic API key")
+	}
+	endpoint := c.Endpoint
+	if endpoint == "" {
+		endpoint = defaultAnthropicEndpoint
+	}
+	model := c.model()
+
+	c.logRequest(endpoint, model)
+
+	reqBody := map[string]interface{}{
+		"model": model,
+		"messages": []map[string]string{
+			{"role": "user", "content": prompt},
+		},
+		"temperature": 0.2,
+		"max_tokens":  2048,
+	}
+	if system != "" {
+		reqBody["system"] = system
+	}
+	resp, err := c.postJSON(ctx, "Anthropic", endpoint, reqBody, map[string]string{
+		"x-api-key":         c.APIKey,
+		"anthropic-version": anthropicVersion,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var anthropicResp struct {
+		Content []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"content"`
+		Usage struct {
+			InputTokens  int `json:"input_tokens"`
+			OutputTokens int `json:"output_tokens"`
+		} `json:"usage"`
+	}
+	err = c.decodeJSONResponse("Anthropic", resp, &anthropicResp, func(body []byte, apiErr *APIError) {
+		var errorResponse struct {
+			Error struct {
+				Type    string `json:"type"`
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		_ = json.Unmarshal(body, &errorResponse)
+		apiErr.Message, apiErr.Type = errorResponse.Error.Message, errorResponse.Error.Type
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(anthropicResp.Content) == 0 {
+		return nil, errors.New("no content returned from Anthropic API")
+	}
+	usage := &Usage{
+		PromptTokens:     anthropicResp.Usage.InputTokens,
+		CompletionTokens: anthropicResp.Usage.OutputTokens,
+		TotalTokens:      anthropicResp.Usage.InputTokens + anthropicResp.Usage.OutputTokens,
+	}
+	return &ReviewResponse{Content: anthropicResp.Content[0].Text, Usage: usage}, nil
+}
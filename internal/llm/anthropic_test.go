@@ -0,0 +1,214 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestSendReviewPrompt_Anthropic(t *testing.T) {
+	client := &Client{
+		Provider: "anthropic",
+		APIKey:   "sk-ant-test",
+	}
+	withMockHTTPClient(func(req *http.Request) *http.Response {
+		if req.URL.String() != defaultAnthropicEndpoint {
+			t.Errorf("expected default endpoint, got %s", req.URL.String())
+		}
+		if req.Header.Get("x-api-key") != "sk-ant-test" {
+			t.Errorf("expected x-api-key header, got %q", req.Header.Get("x-api-key"))
+		}
+		if req.Header.Get("anthropic-version") != anthropicVersion {
+			t.Errorf("expected anthropic-version header, got %q", req.Header.Get("anthropic-version"))
+		}
+		if req.Header.Get("Authorization") != "" {
+			t.Error("Anthropic requests must not send a bearer token")
+		}
+		body, _ := io.ReadAll(req.Body)
+		var reqBody struct {
+			Model       string  `json:"model"`
+			Temperature float64 `json:"temperature"`
+			MaxTokens   int     `json:"max_tokens"`
+			Messages    []struct {
+				Role    string `json:"role"`
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		if err := json.Unmarshal(body, &reqBody); err != nil {
+			t.Fatalf("invalid request body: %v", err)
+		}
+		if reqBody.Model != defaultAnthropicModel {
+			t.Errorf("expected default model %q, got %q", defaultAnthropicModel, reqBody.Model)
+		}
+		if reqBody.MaxTokens != 2048 || reqBody.Temperature != 0.2 {
+			t.Errorf("expected max_tokens 2048 and temperature 0.2, got %d and %v", reqBody.MaxTokens, reqBody.Temperature)
+		}
+		if len(reqBody.Messages) != 1 || reqBody.Messages[0].Role != "user" || reqBody.Messages[0].Content != "review this" {
+			t.Errorf("unexpected messages %+v", reqBody.Messages)
+		}
+		resp := `{
+			"id": "msg_01",
+			"type": "message",
+			"role": "assistant",
+			"content": [{"type": "text", "text": "Claude review"}],
+			"stop_reason": "end_turn"
+		}`
+		return &http.Response{
+			StatusCode: 200,
+			Body:       io.NopCloser(bytes.NewBufferString(resp)),
+			Header:     make(http.Header),
+		}
+	}, func() {
+		resp, err := client.SendReviewPrompt("review this")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if resp != "Claude review" {
+			t.Errorf("Expected 'Claude review', got '%s'", resp)
+		}
+	})
+}
+
+func TestSendReviewWithSystem_Anthropic(t *testing.T) {
+	client := &Client{Provider: "anthropic", APIKey: "sk-ant-test"}
+	withMockHTTPClient(func(req *http.Request) *http.Response {
+		body, _ := io.ReadAll(req.Body)
+		var reqBody struct {
+			System   string `json:"system"`
+			Messages []struct {
+				Role    string `json:"role"`
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		if err := json.Unmarshal(body, &reqBody); err != nil {
+			t.Fatalf("invalid request body: %v", err)
+		}
+		if reqBody.System != "You are a reviewer.\n" {
+			t.Errorf("expected the instructions as the system prompt, got %q", reqBody.System)
+		}
+		if len(reqBody.Messages) != 1 || reqBody.Messages[0].Content != "+diff" {
+			t.Errorf("expected only the diff in the user message, got %+v", reqBody.Messages)
+		}
+		return &http.Response{
+			StatusCode: 200,
+			Body:       io.NopCloser(bytes.NewBufferString(`{"content": [{"type": "text", "text": "ok"}]}`)),
+			Header:     make(http.Header),
+		}
+	}, func() {
+		if _, err := client.SendReviewWithSystem(context.Background(), "You are a reviewer.\n", "+diff"); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	})
+}
+
+func TestSendReviewWithSystem_PrependedForOtherProviders(t *testing.T) {
+	client := &Client{Provider: "openai", APIKey: "dummy", Endpoint: "http://example.com"}
+	withMockHTTPClient(func(req *http.Request) *http.Response {
+		body, _ := io.ReadAll(req.Body)
+		if strings.Contains(string(body), `"system"`) || !strings.Contains(string(body), `"content":"You are a reviewer.\n+diff"`) {
+			t.Errorf("expected the instructions ahead of the prompt in the user message, got %s", body)
+		}
+		return &http.Response{
+			StatusCode: 200,
+			Body:       io.NopCloser(bytes.NewBufferString(`{"choices":[{"message":{"content":"ok"}}]}`)),
+			Header:     make(http.Header),
+		}
+	}, func() {
+		if _, err := client.SendReviewWithSystem(context.Background(), "You are a reviewer.\n", "+diff"); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	})
+}
+
+func TestSendReviewPrompt_AnthropicModelAndEndpoint(t *testing.T) {
+	client := &Client{
+		Provider: "anthropic",
+		APIKey:   "sk-ant-test",
+		Endpoint: "http://proxy.example.com/v1/messages",
+		Model:    "claude-3-opus-latest",
+	}
+	withMockHTTPClient(func(req *http.Request) *http.Response {
+		if req.URL.String() != "http://proxy.example.com/v1/messages" {
+			t.Errorf("expected configured endpoint, got %s", req.URL.String())
+		}
+		body, _ := io.ReadAll(req.Body)
+		if !strings.Contains(string(body), `"model":"claude-3-opus-latest"`) {
+			t.Errorf("expected configured model in body, got %s", body)
+		}
+		return &http.Response{
+			StatusCode: 200,
+			Body:       io.NopCloser(bytes.NewBufferString(`{"content":[{"type":"text","text":"ok"}]}`)),
+			Header:     make(http.Header),
+		}
+	}, func() {
+		if _, err := client.SendReviewPrompt("review this"); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	})
+}
+
+func TestSendReviewPrompt_AnthropicErrorResponse(t *testing.T) {
+	client := &Client{Provider: "anthropic", APIKey: "bad"}
+	withMockHTTPClient(func(req *http.Request) *http.Response {
+		resp := `{"type":"error","error":{"type":"authentication_error","message":"invalid x-api-key"}}`
+		return &http.Response{
+			StatusCode: 401,
+			Body:       io.NopCloser(bytes.NewBufferString(resp)),
+			Header:     make(http.Header),
+		}
+	}, func() {
+		_, err := client.SendReviewPrompt("review this")
+		if err == nil || !strings.Contains(err.Error(), "invalid x-api-key") || !strings.Contains(err.Error(), "authentication_error") {
+			t.Errorf("Expected Anthropic API error, got: %v", err)
+		}
+	})
+}
+
+func TestSendReviewPrompt_AnthropicEmptyContent(t *testing.T) {
+	client := &Client{Provider: "anthropic", APIKey: "sk-ant-test"}
+	withMockHTTPClient(func(req *http.Request) *http.Response {
+		return &http.Response{
+			StatusCode: 200,
+			Body:       io.NopCloser(bytes.NewBufferString(`{"content":[]}`)),
+			Header:     make(http.Header),
+		}
+	}, func() {
+		_, err := client.SendReviewPrompt("review this")
+		if err == nil || !strings.Contains(err.Error(), "no content returned from Anthropic API") {
+			t.Errorf("Expected empty content error, got: %v", err)
+		}
+	})
+}
+
+func TestSendReviewPrompt_AnthropicMissingAPIKey(t *testing.T) {
+	client := &Client{Provider: "anthropic"}
+	_, err := client.SendReviewPrompt("review this")
+	if err == nil || !strings.Contains(err.Error(), "missing Anthropic API key") {
+		t.Errorf("Expected missing API key error, got: %v", err)
+	}
+}
+
+func TestSendReview_AnthropicUsage(t *testing.T) {
+	client := &Client{Provider: "anthropic", APIKey: "sk-ant-test"}
+	withMockHTTPClient(func(req *http.Request) *http.Response {
+		resp := `{"content":[{"type":"text","text":"ok"}],"usage":{"input_tokens":900,"output_tokens":100}}`
+		return &http.Response{
+			StatusCode: 200,
+			Body:       io.NopCloser(bytes.NewBufferString(resp)),
+			Header:     make(http.Header),
+		}
+	}, func() {
+		resp, err := client.SendReview(context.Background(), "review this")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		want := Usage{PromptTokens: 900, CompletionTokens: 100, TotalTokens: 1000}
+		if resp.Usage == nil || *resp.Usage != want {
+			t.Errorf("expected usage %+v, got %+v", want, resp.Usage)
+		}
+	})
+}
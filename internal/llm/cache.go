@@ -0,0 +1,105 @@
+package llm
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DefaultCacheTTL is how long cached responses stay valid when no TTL is configured.
+const DefaultCacheTTL = 24 * time.Hour
+
+// Cache stores LLM responses on disk, keyed by provider, model, and prompt, so re-running a
+// review of an unchanged PR does not spend API quota.
+type Cache struct {
+	Dir string
+	TTL time.Duration
+
+	now func() time.Time // Overridden in tests
+}
+
+// cacheEntry is the on-disk form of a cached response.
+type cacheEntry struct {
+	CreatedAt time.Time `json:"created_at"`
+	Content   string    `json:"content"`
+	Usage     *Usage    `json:"usage,omitempty"`
+}
+
+// NewCache returns a cache storing entries in dir that expire after ttl (DefaultCacheTTL if ttl <= 0).
+func NewCache(dir string, ttl time.Duration) *Cache {
+	if ttl <= 0 {
+		ttl = DefaultCacheTTL
+	}
+	return &Cache{Dir: dir, TTL: ttl, now: time.Now}
+}
+
+// CacheKey returns the SHA-256 hex digest identifying a prompt sent to a provider and model at
+// an endpoint (and, for Azure OpenAI, a deployment), so different servers and deployments of
+// the same model do not share entries.
+func CacheKey(provider, endpoint, deployment, model, prompt string) string {
+	h := sha256.New()
+	// NUL separators keep ("a", "bc") and ("ab", "c") distinct
+	h.Write([]byte(strings.Join([]string{strings.ToLower(provider), endpoint, deployment, model, prompt}, "\x00")))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.Dir, key+".json")
+}
+
+// Get returns the cached response for key. Missing, unreadable, and expired entries are
+// misses; expired entries are removed.
+func (c *Cache) Get(key string) (*ReviewResponse, bool) {
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return nil, false
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, false
+	}
+	if c.now().Sub(entry.CreatedAt) > c.TTL {
+		_ = os.Remove(c.path(key))
+		return nil, false
+	}
+	return &ReviewResponse{Content: entry.Content, Usage: entry.Usage, Cached: true}, true
+}
+
+// Put stores resp under key, creating the cache directory if needed. The entry is written to
+// a temporary file and renamed so concurrent readers never see a partial entry.
+func (c *Cache) Put(key string, resp *ReviewResponse) error {
+	if resp == nil {
+		return errors.New("cannot cache a nil response")
+	}
+	if err := os.MkdirAll(c.Dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	data, err := json.Marshal(cacheEntry{CreatedAt: c.now(), Content: resp.Content, Usage: resp.Usage})
+	if err != nil {
+		return fmt.Errorf("failed to marshal cache entry: %w", err)
+	}
+	tmp, err := os.CreateTemp(c.Dir, key+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), c.path(key)); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	return nil
+}
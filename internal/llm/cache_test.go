@@ -0,0 +1,139 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSendReview_CacheHitAndMiss(t *testing.T) {
+	cache := NewCache(t.TempDir(), time.Hour)
+	client := &Client{
+		Provider: "openai",
+		APIKey:   "dummy",
+		Endpoint: "http://example.com",
+		Cache:    cache,
+	}
+	calls := 0
+	withMockHTTPClient(func(req *http.Request) *http.Response {
+		calls++
+		resp := `{"choices":[{"message":{"content":"Fresh review"}}],"usage":{"prompt_tokens":5,"completion_tokens":2,"total_tokens":7}}`
+		return &http.Response{
+			StatusCode: 200,
+			Body:       io.NopCloser(bytes.NewBufferString(resp)),
+			Header:     make(http.Header),
+		}
+	}, func() {
+		for i := 0; i < 2; i++ {
+			resp, err := client.SendReview(context.Background(), "same prompt")
+			if err != nil {
+				t.Fatalf("call %d: unexpected error: %v", i+1, err)
+			}
+			if resp.Content != "Fresh review" || resp.Usage == nil || resp.Usage.TotalTokens != 7 || resp.Cached != (i == 1) {
+				t.Errorf("call %d: unexpected response %+v", i+1, resp)
+			}
+		}
+		if calls != 1 {
+			t.Errorf("expected the second call to be served from cache, got %d API calls", calls)
+		}
+
+		// A different prompt or model is a miss
+		if _, err := client.SendReview(context.Background(), "other prompt"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		client.Model = "gpt-4o"
+		if _, err := client.SendReview(context.Background(), "same prompt"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if calls != 3 {
+			t.Errorf("expected misses for a new prompt and a new model, got %d API calls", calls)
+		}
+	})
+}
+
+func TestSendReview_ErrorsAreNotCached(t *testing.T) {
+	dir := t.TempDir()
+	client := &Client{
+		Provider: "openai",
+		APIKey:   "dummy",
+		Endpoint: "http://example.com",
+		Cache:    NewCache(dir, time.Hour),
+	}
+	withMockHTTPClient(func(req *http.Request) *http.Response {
+		return &http.Response{
+			StatusCode: 400,
+			Body:       io.NopCloser(bytes.NewBufferString(`{"error":{"message":"bad request"}}`)),
+			Header:     make(http.Header),
+		}
+	}, func() {
+		if _, err := client.SendReview(context.Background(), "prompt"); err == nil {
+			t.Fatal("expected error")
+		}
+	})
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 0 {
+		t.Errorf("expected no cache entries after a failed call, got %d", len(entries))
+	}
+}
+
+func TestCache_TTLExpiry(t *testing.T) {
+	dir := t.TempDir()
+	cache := NewCache(dir, time.Hour)
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	cache.now = func() time.Time { return now }
+
+	key := CacheKey("openai", "", "", "gpt-4o", "prompt")
+	if _, ok := cache.Get(key); ok {
+		t.Fatal("expected miss on an empty cache")
+	}
+	if err := cache.Put(key, &ReviewResponse{Content: "cached"}); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+
+	now = now.Add(59 * time.Minute)
+	if resp, ok := cache.Get(key); !ok || resp.Content != "cached" {
+		t.Fatalf("expected hit before expiry, got %+v %v", resp, ok)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if _, ok := cache.Get(key); ok {
+		t.Error("expected miss after TTL expiry")
+	}
+	if _, err := os.Stat(filepath.Join(dir, key+".json")); !os.IsNotExist(err) {
+		t.Errorf("expected expired entry to be removed, stat err: %v", err)
+	}
+}
+
+func TestCacheKey(t *testing.T) {
+	base := CacheKey("openai", "", "", "gpt-4o", "prompt")
+	if len(base) != 64 {
+		t.Errorf("expected a SHA-256 hex digest, got %q", base)
+	}
+	if CacheKey("OpenAI", "", "", "gpt-4o", "prompt") != base {
+		t.Error("expected provider name to be case-insensitive")
+	}
+	for _, other := range []string{
+		CacheKey("anthropic", "", "", "gpt-4o", "prompt"),
+		CacheKey("openai", "", "", "gpt-4o-mini", "prompt"),
+		CacheKey("openai", "", "", "gpt-4o", "prompt2"),
+		CacheKey("openai", "", "", "gpt-4", "oprompt"),
+		CacheKey("openai", "https://proxy.example.com/v1", "", "gpt-4o", "prompt"),
+		CacheKey("azure", "https://a.example.com", "prod", "gpt-4o", "prompt"),
+		CacheKey("azure", "https://a.example.com", "staging", "gpt-4o", "prompt"),
+	} {
+		if other == base {
+			t.Errorf("expected distinct keys, got collision %q", other)
+		}
+	}
+}
+
+func TestNewCache_DefaultTTL(t *testing.T) {
+	if c := NewCache(t.TempDir(), 0); c.TTL != DefaultCacheTTL {
+		t.Errorf("expected default TTL %s, got %s", DefaultCacheTTL, c.TTL)
+	}
+}
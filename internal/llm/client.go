@@ -1,15 +1,16 @@
 package llm
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
 	"net/http"
-	"os"
+	"net/url"
 	"pullreview/internal/copilot"
+	"pullreview/internal/logging"
 	"strings"
+	"time"
 )
 
 var verboseMode bool
@@ -21,8 +22,42 @@ type Client struct {
 	APIKey   string
 	Endpoint string
 	Model    string // LLM model name (e.g., arcee-ai/trinity-large-preview:free)
+
+	// Azure OpenAI only: the deployment to call (defaults to Model) and the API version.
+	Deployment string
+	APIVersion string
+
+	// PricePer1KTokens is the price of this provider per 1,000 tokens, for cost estimates (0 if
+	// unknown).
+	PricePer1KTokens float64
+
+	// MaxAttempts is the number of attempts made for a request that fails with 429 or 5xx
+	// (DefaultMaxAttempts if zero).
+	MaxAttempts int
+
+	// Timeout bounds each review request, including retries (DefaultTimeout if zero).
+	Timeout time.Duration
+
+	// Stream requests a streamed response from OpenAI-compatible providers. OnChunk, if set, is
+	// called with each piece of content as it arrives. Providers that do not stream are handled
+	// as if Stream were false.
+	Stream  bool
+	OnChunk func(string)
+
+	// Cache, if set, is consulted before calling the provider and stores successful responses.
+	Cache *Cache
+
+	// Fallbacks are tried in order when this provider fails hard (see SendReview). Their own
+	// Fallbacks are ignored.
+	Fallbacks []*Client
+
+	// Logger receives progress and debug messages (a console logger honouring SetVerbose if nil).
+	Logger logging.Logger
 }
 
+// DefaultTimeout is the request deadline used when Client.Timeout is unset.
+const DefaultTimeout = 5 * time.Minute
+
 // NewClient creates a new LLM API client.
 
 func NewClient(provider, apiKey, endpoint string) *Client {
@@ -46,65 +81,257 @@ type ReviewRequest struct {
 // ReviewResponse represents the output from an LLM review.
 type ReviewResponse struct {
 	Content string
+	Usage   *Usage // Token usage, or nil if the provider did not report it
+
+	// Provider and Model identify the client that served the response, which may be one of
+	// the Fallbacks, and PricePer1KTokens is that client's price.
+	Provider         string
+	Model            string
+	PricePer1KTokens float64
+
+	// Cached reports whether the response came from the Cache rather than the provider, so no
+	// tokens were spent on it.
+	Cached bool
+
+	// Streamed reports whether the content was delivered through OnChunk as it arrived.
+	Streamed bool
 }
 
-// SendReviewPrompt sends the review prompt to the configured LLM provider and returns the response.
+// Usage is the number of tokens consumed by a request.
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// Cost returns the estimated cost of the request given a price per 1,000 tokens.
+func (u Usage) Cost(pricePer1K float64) float64 {
+	return float64(u.TotalTokens) / 1000 * pricePer1K
+}
+
+// SendReviewPrompt sends the review prompt to the configured LLM provider and returns the response text.
 func (c *Client) SendReviewPrompt(prompt string) (string, error) {
+	resp, err := c.SendReview(context.Background(), prompt)
+	if err != nil {
+		return "", err
+	}
+	return resp.Content, nil
+}
+
+// SendReview sends the review prompt to the configured LLM provider and returns the response
+// text together with token usage, when the provider reports it. The request is abandoned when
+// ctx is done or the client's Timeout elapses, whichever comes first. If the provider fails
+// hard, the prompt is sent to each of the Fallbacks in turn until one succeeds.
+func (c *Client) SendReview(ctx context.Context, prompt string) (*ReviewResponse, error) {
+	return c.SendReviewWithSystem(ctx, "", prompt)
+}
+
+// SendReviewWithSystem is SendReview with instructions kept apart from the prompt: Anthropic
+// receives them as its system prompt, and every other provider ahead of the prompt, as if
+// they had been part of it.
+func (c *Client) SendReviewWithSystem(ctx context.Context, system, prompt string) (*ReviewResponse, error) {
+	resp, err := c.sendReview(ctx, system, prompt)
+	if err == nil || len(c.Fallbacks) == 0 {
+		return resp, err
+	}
+	prev := c
+	for _, fb := range c.Fallbacks {
+		if !shouldFallback(ctx, err) {
+			return nil, err
+		}
+		c.logger().Error("Provider %q failed: %v; falling back to %q", prev.Provider, err, fb.Provider)
+		resp, err = fb.sendReview(ctx, system, prompt)
+		if err == nil {
+			return resp, nil
+		}
+		prev = fb
+	}
+	return nil, fmt.Errorf("all %d LLM providers failed, last error: %w", len(c.Fallbacks)+1, err)
+}
+
+// sendReview sends the prompt to this client's provider only.
+func (c *Client) sendReview(ctx context.Context, system, prompt string) (*ReviewResponse, error) {
 	// Always print provider and model to stdout before sending the prompt
-	model := c.Model
-	if model == "" {
-		model = "gpt-3.5-turbo"
+	c.logger().Info("Using provider %q with model %q", c.Provider, c.model())
+
+	var cacheKey string
+	if c.Cache != nil {
+		keyPrompt := prompt
+		if system != "" {
+			keyPrompt = system + "\x00" + prompt
+		}
+		cacheKey = CacheKey(c.Provider, c.Endpoint, c.Deployment, c.model(), keyPrompt)
+		if resp, ok := c.Cache.Get(cacheKey); ok {
+			c.logger().Info("Using cached response")
+			resp.Provider, resp.Model, resp.PricePer1KTokens = c.Provider, c.model(), c.PricePer1KTokens
+			return resp, nil
+		}
+	}
+
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	resp, err := c.send(ctx, system, prompt)
+	if err != nil {
+		return nil, err
 	}
-	fmt.Fprintf(os.Stdout, "[llm] Using provider %q with model %q\n", c.Provider, model)
+	resp.Provider, resp.Model, resp.PricePer1KTokens = c.Provider, c.model(), c.PricePer1KTokens
+	if c.Cache != nil {
+		if err := c.Cache.Put(cacheKey, resp); err != nil {
+			c.logger().Error("Warning: failed to cache response: %v", err)
+		}
+	}
+	return resp, nil
+}
 
+// send dispatches the prompt to the configured provider. Only Anthropic takes the system
+// instructions separately; the others get them prepended to the prompt.
+func (c *Client) send(ctx context.Context, system, prompt string) (*ReviewResponse, error) {
+	if strings.ToLower(c.Provider) == "anthropic" {
+		return c.sendAnthropic(ctx, system, prompt)
+	}
+	prompt = system + prompt
 	switch strings.ToLower(c.Provider) {
-	case "openai", "openrouter":
-		return c.sendOpenAI(prompt)
+	case "openai", "openrouter", "azure":
+		return c.sendOpenAI(ctx, prompt)
+	case "gemini":
+		return c.sendGemini(ctx, prompt)
+	case "ollama":
+		return c.sendOllama(ctx, prompt)
 	case "copilot":
-		return c.sendCopilot(prompt)
+		return c.sendCopilot(ctx, prompt)
+	default:
+		return nil, fmt.Errorf("unsupported LLM provider: %s", c.Provider)
+	}
+}
+
+// model returns the configured model, or the provider's default if none is set.
+func (c *Client) model() string {
+	if c.Model != "" {
+		return c.Model
+	}
+	switch strings.ToLower(c.Provider) {
+	case "anthropic":
+		return defaultAnthropicModel
+	case "gemini":
+		return defaultGeminiModel
+	case "ollama":
+		return defaultOllamaModel
 	default:
-		return "", fmt.Errorf("unsupported LLM provider: %s", c.Provider)
+		return "gpt-3.5-turbo"
 	}
 }
 
 // sendCopilot sends the prompt to GitHub Copilot via the SDK and returns the response.
-func (c *Client) sendCopilot(prompt string) (string, error) {
+func (c *Client) sendCopilot(ctx context.Context, prompt string) (*ReviewResponse, error) {
 	// Set verbose mode on the copilot package to match our setting
 	copilot.SetVerbose(verboseMode)
 
-	// Create a Copilot client with the configured model
+	// Create a Copilot client with the configured model. The SDK takes a timeout rather than a
+	// context, so translate the deadline.
 	copilotClient := copilot.NewClient(c.Model)
+	if deadline, ok := ctx.Deadline(); ok {
+		copilotClient.Timeout = time.Until(deadline)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 
-	if verboseMode {
-		fmt.Fprintf(os.Stderr, "[llm] Provider: %s\n", c.Provider)
-		fmt.Fprintf(os.Stderr, "[llm] Model: %s\n", c.Model)
+	c.logger().Debug("Provider: %s", c.Provider)
+	c.logger().Debug("Model: %s", c.Model)
+
+	content, err := copilotClient.SendReviewPrompt(prompt)
+	if err != nil {
+		return nil, err
+	}
+	return &ReviewResponse{Content: content}, nil
+}
+
+// openAIName returns the display name of the OpenAI-compatible API the client talks to.
+func (c *Client) openAIName() string {
+	switch strings.ToLower(c.Provider) {
+	case "openai":
+		return "OpenAI"
+	case "azure":
+		return "Azure OpenAI"
+	default:
+		return "OpenRouter"
+	}
+}
+
+// defaultAzureAPIVersion is used for Azure OpenAI when no api_version is configured.
+const defaultAzureAPIVersion = "2024-02-01"
+
+// isAzure reports whether the client talks to Azure OpenAI.
+func (c *Client) isAzure() bool {
+	return strings.ToLower(c.Provider) == "azure"
+}
+
+// chatCompletionsURL returns the URL for the Chat Completions request. For Azure OpenAI the
+// endpoint is the resource URL and the deployment path and api-version are appended to it.
+func (c *Client) chatCompletionsURL() (string, error) {
+	if !c.isAzure() {
+		return c.Endpoint, nil
+	}
+	deployment := c.Deployment
+	if deployment == "" {
+		deployment = c.Model
+	}
+	if deployment == "" {
+		return "", errors.New("missing Azure OpenAI deployment name")
+	}
+	apiVersion := c.APIVersion
+	if apiVersion == "" {
+		apiVersion = defaultAzureAPIVersion
+	}
+	return fmt.Sprintf("%s/openai/deployments/%s/chat/completions?api-version=%s",
+		strings.TrimRight(c.Endpoint, "/"), url.PathEscape(deployment), url.QueryEscape(apiVersion)), nil
+}
+
+// sendOpenAI sends the prompt to OpenAI's Chat API (or a compatible API such as OpenRouter or
+// Azure OpenAI) and returns the response. A streamed request the provider rejects because of
+// the stream parameter is sent again without it.
+func (c *Client) sendOpenAI(ctx context.Context, prompt string) (*ReviewResponse, error) {
+	resp, err := c.requestOpenAI(ctx, prompt, c.Stream)
+	var apiErr *APIError
+	if c.Stream && errors.As(err, &apiErr) && rejectsStreaming(apiErr) {
+		c.logger().Info("%s rejected the streamed request (%s); retrying without streaming", apiErr.Provider, apiErr.Message)
+		return c.requestOpenAI(ctx, prompt, false)
 	}
+	return resp, err
+}
 
-	return copilotClient.SendReviewPrompt(prompt)
+// rejectsStreaming reports whether err is a provider refusing "stream": true, which some
+// OpenAI-compatible servers and models do with a 400 or 422 naming the parameter.
+func rejectsStreaming(err *APIError) bool {
+	if err.StatusCode != http.StatusBadRequest && err.StatusCode != http.StatusUnprocessableEntity {
+		return false
+	}
+	return strings.Contains(strings.ToLower(err.Message), "stream")
 }
 
-// sendOpenAI sends the prompt to OpenAI's Chat API and returns the response.
-func (c *Client) sendOpenAI(prompt string) (string, error) {
+// requestOpenAI sends a single chat completion request, streamed if stream is set.
+func (c *Client) requestOpenAI(ctx context.Context, prompt string, stream bool) (*ReviewResponse, error) {
 	if c.APIKey == "" {
-		return "", errors.New("missing OpenAI API key")
+		return nil, errors.New("missing OpenAI API key")
 	}
 	if c.Endpoint == "" {
-		return "", errors.New("missing OpenAI API endpoint")
+		return nil, errors.New("missing OpenAI API endpoint")
 	}
 
-	model := c.Model
-
-	if model == "" {
-		model = "gpt-3.5-turbo"
+	model := c.model()
+	endpoint, err := c.chatCompletionsURL()
+	if err != nil {
+		return nil, err
 	}
 
 	// Print LLM config before making the API call, but only if verbose is enabled
-	if verboseMode {
-		fmt.Fprintf(os.Stderr, "[llm] Provider: %s\n", c.Provider)
-		fmt.Fprintf(os.Stderr, "[llm] API Key: %s\n", c.APIKey)
-		fmt.Fprintf(os.Stderr, "[llm] Endpoint: %s\n", c.Endpoint)
-		fmt.Fprintf(os.Stderr, "[llm] Model: %s\n", model)
-	}
+	c.logRequest(endpoint, model)
+	c.logger().Debug("API Key: %s", redactSecret(c.APIKey))
 
 	// Prepare request body for OpenAI/OpenRouter Chat API
 	reqBody := map[string]interface{}{
@@ -115,82 +342,73 @@ func (c *Client) sendOpenAI(prompt string) (string, error) {
 		"temperature": 0.2,
 		"max_tokens":  2048,
 	}
-	bodyBytes, err := json.Marshal(reqBody)
-	if err != nil {
-		return "", fmt.Errorf("failed to marshal OpenAI request: %w", err)
+	if stream {
+		reqBody["stream"] = true
 	}
-
-	req, err := http.NewRequest("POST", c.Endpoint, bytes.NewReader(bodyBytes))
-	if err != nil {
-		return "", fmt.Errorf("failed to create OpenAI request: %w", err)
+	headers := map[string]string{"Authorization": "Bearer " + c.APIKey}
+	if c.isAzure() {
+		headers = map[string]string{"api-key": c.APIKey}
 	}
-	req.Header.Set("Authorization", "Bearer "+c.APIKey)
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := http.DefaultClient.Do(req)
+	api := c.openAIName()
+	resp, err := c.postJSON(ctx, api, endpoint, reqBody, headers)
 	if err != nil {
-		return "", fmt.Errorf("failed to contact OpenAI API: %w", err)
+		return nil, err
 	}
 	defer resp.Body.Close()
 
-	respBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", fmt.Errorf("failed to read OpenAI response: %w", err)
-	}
-	if resp.StatusCode != http.StatusOK {
-		// Try to parse OpenRouter-style error details
-		var errorResponse struct {
-			Error struct {
-				Message string `json:"message"`
-				Type    string `json:"type"`
-				Param   string `json:"param"`
-				Code    string `json:"code"`
-			} `json:"error"`
-		}
-		_ = json.Unmarshal(respBody, &errorResponse)
-		if verboseMode {
-			fmt.Fprintf(os.Stderr, "==============================================================================================================================\n")
-			fmt.Fprintf(os.Stderr, "[llm] Raw error response from LLM:\n%s\n", string(respBody))
-			fmt.Fprintf(os.Stderr, "==============================================================================================================================\n")
-			fmt.Fprintf(os.Stderr, "[llm] Error response from LLM (parsed):\n")
-			fmt.Fprintf(os.Stderr, "[llm]   Message: %s\n", errorResponse.Error.Message)
-			fmt.Fprintf(os.Stderr, "[llm]   Type: %s\n", errorResponse.Error.Type)
-			fmt.Fprintf(os.Stderr, "[llm]   Code: %s\n", errorResponse.Error.Code)
-		}
-		providerName := "OpenRouter"
-		if strings.ToLower(c.Provider) == "openai" {
-			providerName = "OpenAI"
-		}
-		return "", fmt.Errorf("%s API error: %s (type: %s, code: %s)",
-			providerName,
-			errorResponse.Error.Message,
-			errorResponse.Error.Type,
-			errorResponse.Error.Code)
+	if stream && resp.StatusCode == http.StatusOK && isEventStream(resp) {
+		return readOpenAIStream(resp.Body, c.OnChunk)
 	}
 
-	// Parse OpenAI response
 	var openAIResp struct {
 		Choices []struct {
 			Message struct {
 				Content string `json:"content"`
 			} `json:"message"`
 		} `json:"choices"`
+		Usage *Usage `json:"usage"`
 	}
-	if err := json.Unmarshal(respBody, &openAIResp); err != nil {
-		return "", fmt.Errorf("failed to parse OpenAI response: %w", err)
-	}
-	if verboseMode {
-		fmt.Fprintf(os.Stdout, "==============================================================================================================================\n")
-		fmt.Fprintf(os.Stdout, "[llm] Raw success response from LLM:\n")
-		fmt.Fprintf(os.Stdout, "==============================================================================================================================\n\n")
-		fmt.Fprintf(os.Stdout, "%s\n", string(respBody))
-		fmt.Fprintf(os.Stdout, "\n===============================================================================================================================\n")
-		fmt.Fprintf(os.Stdout, "===============================================================================================================================\n")
+	err = c.decodeJSONResponse(api, resp, &openAIResp, func(body []byte, apiErr *APIError) {
+		// OpenAI/OpenRouter-style error details; OpenRouter's code is numeric
+		var errorResponse struct {
+			Error struct {
+				Message string          `json:"message"`
+				Type    string          `json:"type"`
+				Param   string          `json:"param"`
+				Code    json.RawMessage `json:"code"`
+			} `json:"error"`
+		}
+		_ = json.Unmarshal(body, &errorResponse)
+		apiErr.Message, apiErr.Type = errorResponse.Error.Message, errorResponse.Error.Type
+		apiErr.Code = errorCode(errorResponse.Error.Code)
+		c.logger().Debug("Error response from LLM (parsed): message %q, type %q, code %q",
+			apiErr.Message, apiErr.Type, apiErr.Code)
+	})
+	if err != nil {
+		return nil, err
 	}
 	if len(openAIResp.Choices) == 0 {
-		return "", errors.New("no choices returned from OpenAI API")
+		return nil, errors.New("no choices returned from OpenAI API")
+	}
+	return &ReviewResponse{Content: openAIResp.Choices[0].Message.Content, Usage: openAIResp.Usage}, nil
+}
+
+// redactSecret masks a credential for logging, keeping only the first and last 4 characters so
+// the key can be recognised. Secrets shorter than 16 characters are masked entirely, so that
+// at least half of the secret is always hidden.
+func redactSecret(s string) string {
+	if len(s) < 16 {
+		return strings.Repeat("*", len(s))
+	}
+	return s[:4] + strings.Repeat("*", len(s)-8) + s[len(s)-4:]
+}
+
+// logger returns the client's Logger, or the default console logger.
+func (c *Client) logger() logging.Logger {
+	if c.Logger != nil {
+		return c.Logger
 	}
-	return openAIResp.Choices[0].Message.Content, nil
+	return &logging.Console{Prefix: "[llm] ", Verbose: verboseMode}
 }
 
 // SetVerbose enables or disables verbose mode for LLM debug output.
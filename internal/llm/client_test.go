@@ -2,11 +2,16 @@ package llm
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
+	"os"
 	"strings"
 	"testing"
+	"time"
 )
 
 // mockRoundTripper implements http.RoundTripper for testing HTTP requests.
@@ -124,10 +129,10 @@ func TestSendReviewPrompt_MissingEndpoint(t *testing.T) {
 
 func TestSendReviewPrompt_UnsupportedProvider(t *testing.T) {
 	client := &Client{
-		Provider: "anthropic",
+		Provider: "cohere",
 		APIKey:   "dummy",
 		Endpoint: "http://example.com",
-		Model:    "claude-2",
+		Model:    "command-r",
 	}
 	_, err := client.SendReviewPrompt("test prompt")
 	if err == nil || !strings.Contains(err.Error(), "unsupported LLM provider") {
@@ -198,3 +203,410 @@ func TestSendReviewPrompt_NoChoicesInResponse(t *testing.T) {
 		}
 	})
 }
+
+func TestSendReviewPrompt_Azure(t *testing.T) {
+	client := &Client{
+		Provider:   "azure",
+		APIKey:     "azure-key",
+		Endpoint:   "https://my-resource.openai.azure.com/",
+		Deployment: "gpt-4o-review",
+		APIVersion: "2024-06-01",
+	}
+	withMockHTTPClient(func(req *http.Request) *http.Response {
+		want := "https://my-resource.openai.azure.com/openai/deployments/gpt-4o-review/chat/completions?api-version=2024-06-01"
+		if req.URL.String() != want {
+			t.Errorf("expected Azure URL %s, got %s", want, req.URL.String())
+		}
+		if req.Header.Get("api-key") != "azure-key" {
+			t.Errorf("expected api-key header, got %q", req.Header.Get("api-key"))
+		}
+		if req.Header.Get("Authorization") != "" {
+			t.Errorf("Azure requests must not send a bearer token, got %q", req.Header.Get("Authorization"))
+		}
+		resp := `{"choices":[{"message":{"content":"Azure response"}}]}`
+		return &http.Response{
+			StatusCode: 200,
+			Body:       io.NopCloser(bytes.NewBufferString(resp)),
+			Header:     make(http.Header),
+		}
+	}, func() {
+		resp, err := client.SendReviewPrompt("test prompt")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if resp != "Azure response" {
+			t.Errorf("Expected 'Azure response', got '%s'", resp)
+		}
+	})
+}
+
+func TestSendReviewPrompt_AzureDefaults(t *testing.T) {
+	client := &Client{
+		Provider: "azure",
+		APIKey:   "azure-key",
+		Endpoint: "https://my-resource.openai.azure.com",
+		Model:    "gpt-4o",
+	}
+	withMockHTTPClient(func(req *http.Request) *http.Response {
+		want := "https://my-resource.openai.azure.com/openai/deployments/gpt-4o/chat/completions?api-version=" + defaultAzureAPIVersion
+		if req.URL.String() != want {
+			t.Errorf("expected Azure URL %s, got %s", want, req.URL.String())
+		}
+		return &http.Response{
+			StatusCode: 401,
+			Body:       io.NopCloser(bytes.NewBufferString(`{"error":{"code":"401","message":"Access denied due to invalid subscription key."}}`)),
+			Header:     make(http.Header),
+		}
+	}, func() {
+		_, err := client.SendReviewPrompt("test prompt")
+		if err == nil || !strings.Contains(err.Error(), "Azure OpenAI API error: Access denied") {
+			t.Errorf("Expected Azure OpenAI API error, got: %v", err)
+		}
+	})
+}
+
+func TestSendReviewPrompt_AzureMissingDeployment(t *testing.T) {
+	client := &Client{
+		Provider: "azure",
+		APIKey:   "azure-key",
+		Endpoint: "https://my-resource.openai.azure.com",
+	}
+	_, err := client.SendReviewPrompt("test prompt")
+	if err == nil || !strings.Contains(err.Error(), "missing Azure OpenAI deployment name") {
+		t.Errorf("Expected missing deployment error, got: %v", err)
+	}
+}
+
+func TestSendReview_ParsesUsage(t *testing.T) {
+	client := &Client{
+		Provider: "openrouter",
+		APIKey:   "dummy",
+		Endpoint: "http://example.com",
+	}
+	withMockHTTPClient(func(req *http.Request) *http.Response {
+		resp := `{
+			"choices": [{"message": {"content": "Review with usage"}}],
+			"usage": {"prompt_tokens": 1200, "completion_tokens": 300, "total_tokens": 1500}
+		}`
+		return &http.Response{
+			StatusCode: 200,
+			Body:       io.NopCloser(bytes.NewBufferString(resp)),
+			Header:     make(http.Header),
+		}
+	}, func() {
+		resp, err := client.SendReview(context.Background(), "test prompt")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if resp.Content != "Review with usage" {
+			t.Errorf("Expected 'Review with usage', got '%s'", resp.Content)
+		}
+		want := Usage{PromptTokens: 1200, CompletionTokens: 300, TotalTokens: 1500}
+		if resp.Usage == nil || *resp.Usage != want {
+			t.Fatalf("expected usage %+v, got %+v", want, resp.Usage)
+		}
+		if cost := resp.Usage.Cost(0.002); cost != 0.003 {
+			t.Errorf("expected cost 0.003, got %v", cost)
+		}
+	})
+}
+
+func TestSendReview_MissingUsage(t *testing.T) {
+	client := &Client{
+		Provider: "openai",
+		APIKey:   "dummy",
+		Endpoint: "http://example.com",
+	}
+	withMockHTTPClient(func(req *http.Request) *http.Response {
+		return &http.Response{
+			StatusCode: 200,
+			Body:       io.NopCloser(bytes.NewBufferString(`{"choices":[{"message":{"content":"No usage"}}]}`)),
+			Header:     make(http.Header),
+		}
+	}, func() {
+		resp, err := client.SendReview(context.Background(), "test prompt")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if resp.Usage != nil {
+			t.Errorf("expected nil usage when the response has none, got %+v", resp.Usage)
+		}
+	})
+}
+
+// hangingRoundTripper blocks until the request context is done, simulating a stalled provider.
+type hangingRoundTripper struct{}
+
+func (hangingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	<-req.Context().Done()
+	return nil, req.Context().Err()
+}
+
+func TestSendReview_CanceledContextAbortsPromptly(t *testing.T) {
+	origTransport := http.DefaultClient.Transport
+	http.DefaultClient.Transport = hangingRoundTripper{}
+	defer func() { http.DefaultClient.Transport = origTransport }()
+
+	clients := []*Client{
+		{Provider: "openai", APIKey: "dummy", Endpoint: "http://example.com"},
+		{Provider: "anthropic", APIKey: "dummy"},
+		{Provider: "gemini", APIKey: "dummy"},
+		{Provider: "ollama"},
+	}
+	for _, client := range clients {
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			cancel()
+		}()
+		start := time.Now()
+		_, err := client.SendReview(ctx, "test prompt")
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("%s: expected context.Canceled, got: %v", client.Provider, err)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("%s: call took %s after cancellation", client.Provider, elapsed)
+		}
+	}
+}
+
+func TestSendReview_TimeoutAbortsStalledProvider(t *testing.T) {
+	origTransport := http.DefaultClient.Transport
+	http.DefaultClient.Transport = hangingRoundTripper{}
+	defer func() { http.DefaultClient.Transport = origTransport }()
+
+	client := &Client{
+		Provider: "openai",
+		APIKey:   "dummy",
+		Endpoint: "http://example.com",
+		Timeout:  20 * time.Millisecond,
+	}
+	_, err := client.SendReview(context.Background(), "test prompt")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected context.DeadlineExceeded, got: %v", err)
+	}
+}
+
+func TestRedactSecret(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"", ""},
+		{"abc", "***"},
+		{"short-key-15chr", "***************"},
+		{"sk-or-v1-0123456789abcdef", "sk-o*****************cdef"},
+		{"sk-proj-abcdefghijklmnopqrstuvwxyz", "sk-p**************************wxyz"},
+	}
+	for _, tt := range tests {
+		got := redactSecret(tt.in)
+		if got != tt.want {
+			t.Errorf("redactSecret(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+		if len(got) != len(tt.in) {
+			t.Errorf("redactSecret(%q) changed length to %d", tt.in, len(got))
+		}
+	}
+}
+
+func TestSendReviewPrompt_VerboseDoesNotLeakAPIKey(t *testing.T) {
+	const key = "sk-or-v1-supersecretvalue1234"
+	client := &Client{
+		Provider: "openrouter",
+		APIKey:   key,
+		Endpoint: "http://example.com",
+	}
+	SetVerbose(true)
+	defer SetVerbose(false)
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("failed to create pipe: %v", err)
+	}
+	origStderr := os.Stderr
+	os.Stderr = w
+	withMockHTTPClient(func(req *http.Request) *http.Response {
+		return &http.Response{
+			StatusCode: 200,
+			Body:       io.NopCloser(bytes.NewBufferString(`{"choices":[{"message":{"content":"ok"}}]}`)),
+			Header:     make(http.Header),
+		}
+	}, func() {
+		_, err = client.SendReviewPrompt("test prompt")
+	})
+	os.Stderr = origStderr
+	w.Close()
+	logged, _ := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if strings.Contains(string(logged), key) {
+		t.Errorf("verbose output leaked the API key:\n%s", logged)
+	}
+	if !strings.Contains(string(logged), "[llm] API Key: sk-o") {
+		t.Errorf("expected a redacted API key line, got:\n%s", logged)
+	}
+}
+
+// hostResponder answers each request with the status and body configured for its host and
+// records the hosts in the order they were called.
+func hostResponder(calls *[]string, replies map[string]struct {
+	status int
+	body   string
+}) func(*http.Request) *http.Response {
+	return func(req *http.Request) *http.Response {
+		*calls = append(*calls, req.URL.Host)
+		reply := replies[req.URL.Host]
+		return &http.Response{
+			StatusCode: reply.status,
+			Body:       io.NopCloser(bytes.NewBufferString(reply.body)),
+			Header:     make(http.Header),
+		}
+	}
+}
+
+func TestSendReview_FallsBackOnHardFailure(t *testing.T) {
+	withNoSleep(t)
+	client := &Client{
+		Provider: "openrouter", APIKey: "key1", Endpoint: "http://primary.example.com", Model: "free-model",
+		Fallbacks: []*Client{
+			{Provider: "openai", APIKey: "key2", Endpoint: "http://broken.example.com", Model: "gpt-4o"},
+			{Provider: "openai", APIKey: "key3", Endpoint: "http://backup.example.com", Model: "gpt-4o-mini", PricePer1KTokens: 0.5},
+		},
+	}
+	var calls []string
+	withMockHTTPClient(hostResponder(&calls, map[string]struct {
+		status int
+		body   string
+	}{
+		"primary.example.com": {402, `{"error":{"message":"Insufficient credits","code":402}}`},
+		"broken.example.com":  {503, `{"error":{"message":"Service unavailable"}}`},
+		"backup.example.com":  {200, `{"choices":[{"message":{"content":"Review from backup"}}]}`},
+	}), func() {
+		resp, err := client.SendReview(context.Background(), "test prompt")
+		if err != nil {
+			t.Fatalf("expected the last fallback to succeed, got: %v", err)
+		}
+		if resp.Content != "Review from backup" || resp.Provider != "openai" || resp.Model != "gpt-4o-mini" || resp.PricePer1KTokens != 0.5 {
+			t.Errorf("unexpected response %+v", resp)
+		}
+	})
+	// The 503 is retried DefaultMaxAttempts times before falling back
+	want := []string{"primary.example.com", "broken.example.com", "broken.example.com", "broken.example.com", "backup.example.com"}
+	if strings.Join(calls, ",") != strings.Join(want, ",") {
+		t.Errorf("expected calls %v, got %v", want, calls)
+	}
+}
+
+func TestSendReview_NoFallbackOnBadRequest(t *testing.T) {
+	client := &Client{
+		Provider: "openai", APIKey: "key1", Endpoint: "http://primary.example.com",
+		Fallbacks: []*Client{{Provider: "openai", APIKey: "key2", Endpoint: "http://backup.example.com"}},
+	}
+	var calls []string
+	withMockHTTPClient(hostResponder(&calls, map[string]struct {
+		status int
+		body   string
+	}{
+		"primary.example.com": {400, `{"error":{"message":"Prompt is too long","type":"invalid_request_error"}}`},
+	}), func() {
+		_, err := client.SendReview(context.Background(), "test prompt")
+		if err == nil || !strings.Contains(err.Error(), "Prompt is too long") {
+			t.Errorf("expected the primary's error, got: %v", err)
+		}
+	})
+	if len(calls) != 1 {
+		t.Errorf("expected no fallback for a bad request, got calls %v", calls)
+	}
+}
+
+func TestSendReview_AllProvidersFail(t *testing.T) {
+	client := &Client{
+		Provider: "openai", APIKey: "key1", Endpoint: "http://primary.example.com",
+		Fallbacks: []*Client{{Provider: "openai", APIKey: "key2", Endpoint: "http://backup.example.com"}},
+	}
+	var calls []string
+	withMockHTTPClient(hostResponder(&calls, map[string]struct {
+		status int
+		body   string
+	}{
+		"primary.example.com": {401, `{"error":{"message":"Bad key","code":"invalid_api_key"}}`},
+		"backup.example.com":  {401, `{"error":{"message":"Bad key","code":"invalid_api_key"}}`},
+	}), func() {
+		_, err := client.SendReview(context.Background(), "test prompt")
+		if !errors.Is(err, ErrInvalidAPIKey) || !strings.Contains(err.Error(), "all 2 LLM providers failed") {
+			t.Errorf("expected a wrapped invalid-key error, got: %v", err)
+		}
+	})
+	if len(calls) != 2 {
+		t.Errorf("expected both providers to be tried, got calls %v", calls)
+	}
+}
+
+// captureLogger records every message with its level.
+type captureLogger struct {
+	entries []string
+}
+
+func (l *captureLogger) Info(format string, args ...any) {
+	l.entries = append(l.entries, "info: "+fmt.Sprintf(format, args...))
+}
+
+func (l *captureLogger) Debug(format string, args ...any) {
+	l.entries = append(l.entries, "debug: "+fmt.Sprintf(format, args...))
+}
+
+func (l *captureLogger) Error(format string, args ...any) {
+	l.entries = append(l.entries, "error: "+fmt.Sprintf(format, args...))
+}
+
+func TestSendReview_LogsToLogger(t *testing.T) {
+	log := &captureLogger{}
+	client := &Client{
+		Provider: "openai", APIKey: "sk-secretvalue123456", Endpoint: "http://primary.example.com", Model: "gpt-4o",
+		Logger:    log,
+		Fallbacks: []*Client{{Provider: "openai", APIKey: "key2", Endpoint: "http://backup.example.com", Model: "gpt-4o-mini", Logger: log}},
+	}
+	var calls []string
+	withMockHTTPClient(hostResponder(&calls, map[string]struct {
+		status int
+		body   string
+	}{
+		"primary.example.com": {401, `{"error":{"message":"Bad key","code":"invalid_api_key"}}`},
+		"backup.example.com":  {200, `{"choices":[{"message":{"content":"ok"}}]}`},
+	}), func() {
+		if _, err := client.SendReview(context.Background(), "test prompt"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	var key []string
+	for _, e := range log.entries {
+		if !strings.HasPrefix(e, "debug: ") {
+			key = append(key, e)
+		}
+		if strings.Contains(e, "sk-secretvalue123456") {
+			t.Errorf("logged the API key: %q", e)
+		}
+	}
+	want := []string{
+		`info: Using provider "openai" with model "gpt-4o"`,
+		`error: Provider "openai" failed: OpenAI API error: Bad key (code: invalid_api_key, status: 401); falling back to "openai"`,
+		`info: Using provider "openai" with model "gpt-4o-mini"`,
+	}
+	if strings.Join(key, "\n") != strings.Join(want, "\n") {
+		t.Errorf("unexpected log events:\n%s\nwant:\n%s", strings.Join(key, "\n"), strings.Join(want, "\n"))
+	}
+	if !containsPrefix(log.entries, "debug: API Key: sk-s") {
+		t.Errorf("expected the redacted API key at debug level, got %q", log.entries)
+	}
+}
+
+func containsPrefix(entries []string, prefix string) bool {
+	for _, e := range entries {
+		if strings.HasPrefix(e, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,106 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Sentinel errors matched by *APIError via errors.Is, so callers can give targeted advice
+// without inspecting provider-specific error codes.
+var (
+	ErrInsufficientQuota = errors.New("LLM account is out of credit or quota")
+	ErrRateLimited       = errors.New("LLM rate limit exceeded")
+	ErrInvalidAPIKey     = errors.New("LLM API key rejected")
+)
+
+// APIError describes an error response from an LLM provider. Message, Type, and Code are taken
+// from the provider's error JSON when present.
+type APIError struct {
+	Provider   string // Display name, e.g. "OpenAI"
+	StatusCode int
+	Message    string
+	Type       string
+	Code       string
+}
+
+func (e *APIError) Error() string {
+	var details []string
+	if e.Type != "" {
+		details = append(details, "type: "+e.Type)
+	}
+	if e.Code != "" {
+		details = append(details, "code: "+e.Code)
+	}
+	details = append(details, fmt.Sprintf("status: %d", e.StatusCode))
+	return fmt.Sprintf("%s API error: %s (%s)", e.Provider, e.Message, strings.Join(details, ", "))
+}
+
+// Unwrap maps the error onto one of the sentinel errors, or nil if none applies. Provider error
+// codes are checked before status codes because OpenAI reports exhausted quota as a 429.
+func (e *APIError) Unwrap() error {
+	for _, s := range []string{e.Code, e.Type} {
+		if isQuotaCode(s) {
+			return ErrInsufficientQuota
+		}
+		switch strings.ToLower(s) {
+		case "rate_limit_exceeded", "rate_limit_error", "resource_exhausted":
+			return ErrRateLimited
+		case "invalid_api_key", "authentication_error":
+			return ErrInvalidAPIKey
+		}
+	}
+	switch e.StatusCode {
+	case http.StatusPaymentRequired:
+		// OpenRouter: insufficient credits
+		return ErrInsufficientQuota
+	case http.StatusTooManyRequests:
+		return ErrRateLimited
+	case http.StatusUnauthorized:
+		return ErrInvalidAPIKey
+	default:
+		return nil
+	}
+}
+
+// isQuotaCode reports whether a provider error code or type means the account is out of quota
+// or credit.
+func isQuotaCode(s string) bool {
+	switch strings.ToLower(s) {
+	case "insufficient_quota", "billing_hard_limit_reached":
+		return true
+	}
+	return false
+}
+
+// shouldFallback reports whether err is a hard provider failure worth retrying with another
+// provider: rejected credentials, exhausted quota or rate limits, server errors (retries have
+// already been spent), unknown models, and network failures or timeouts. Errors caused by the
+// request itself (e.g. 400 for a prompt that is too long) and cancellation by the caller are not.
+func shouldFallback(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Unwrap() != nil || apiErr.StatusCode >= 500 ||
+			apiErr.StatusCode == http.StatusForbidden || apiErr.StatusCode == http.StatusNotFound
+	}
+	return true
+}
+
+// errorCode renders a provider error code, which is a string for OpenAI but a number for OpenRouter.
+func errorCode(raw json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+	var n json.Number
+	if err := json.Unmarshal(raw, &n); err == nil {
+		return n.String()
+	}
+	return ""
+}
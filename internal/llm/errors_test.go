@@ -0,0 +1,137 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestSendReview_TypedProviderErrors(t *testing.T) {
+	withNoSleep(t)
+	tests := []struct {
+		name     string
+		provider string
+		status   int
+		body     string
+		want     error
+		message  string
+	}{
+		{
+			name:     "OpenAI insufficient quota reported as 429",
+			provider: "openai",
+			status:   http.StatusTooManyRequests,
+			body:     `{"error":{"message":"You exceeded your current quota, please check your plan and billing details.","type":"insufficient_quota","param":null,"code":"insufficient_quota"}}`,
+			want:     ErrInsufficientQuota,
+			message:  "You exceeded your current quota",
+		},
+		{
+			name:     "OpenRouter insufficient credits with numeric code",
+			provider: "openrouter",
+			status:   http.StatusPaymentRequired,
+			body:     `{"error":{"message":"Insufficient credits. Add more using https://openrouter.ai/credits","code":402}}`,
+			want:     ErrInsufficientQuota,
+			message:  "Insufficient credits",
+		},
+		{
+			name:     "OpenAI rate limit",
+			provider: "openai",
+			status:   http.StatusTooManyRequests,
+			body:     `{"error":{"message":"Rate limit reached for gpt-4o","type":"requests","code":"rate_limit_exceeded"}}`,
+			want:     ErrRateLimited,
+			message:  "Rate limit reached",
+		},
+		{
+			name:     "OpenRouter rate limit",
+			provider: "openrouter",
+			status:   http.StatusTooManyRequests,
+			body:     `{"error":{"message":"Rate limit exceeded: free-models-per-day","code":429}}`,
+			want:     ErrRateLimited,
+			message:  "free-models-per-day",
+		},
+		{
+			name:     "OpenAI invalid key",
+			provider: "openai",
+			status:   http.StatusUnauthorized,
+			body:     `{"error":{"message":"Incorrect API key provided: sk-abc***xyz.","type":"invalid_request_error","code":"invalid_api_key"}}`,
+			want:     ErrInvalidAPIKey,
+			message:  "Incorrect API key provided",
+		},
+		{
+			name:     "OpenRouter invalid key",
+			provider: "openrouter",
+			status:   http.StatusUnauthorized,
+			body:     `{"error":{"message":"No auth credentials found","code":401}}`,
+			want:     ErrInvalidAPIKey,
+			message:  "No auth credentials found",
+		},
+		{
+			name:     "Anthropic invalid key",
+			provider: "anthropic",
+			status:   http.StatusUnauthorized,
+			body:     `{"type":"error","error":{"type":"authentication_error","message":"invalid x-api-key"}}`,
+			want:     ErrInvalidAPIKey,
+			message:  "invalid x-api-key",
+		},
+		{
+			name:     "Gemini quota exhausted",
+			provider: "gemini",
+			status:   http.StatusTooManyRequests,
+			body:     `{"error":{"code":429,"message":"Resource has been exhausted (e.g. check quota).","status":"RESOURCE_EXHAUSTED"}}`,
+			want:     ErrRateLimited,
+			message:  "Resource has been exhausted",
+		},
+	}
+	for _, tt := range tests {
+		client := &Client{Provider: tt.provider, APIKey: "dummy", Endpoint: "http://example.com", MaxAttempts: 1}
+		withMockHTTPClient(func(req *http.Request) *http.Response {
+			return &http.Response{
+				StatusCode: tt.status,
+				Body:       io.NopCloser(bytes.NewBufferString(tt.body)),
+				Header:     make(http.Header),
+			}
+		}, func() {
+			_, err := client.SendReview(context.Background(), "test prompt")
+			if !errors.Is(err, tt.want) {
+				t.Errorf("%s: expected %v, got: %v", tt.name, tt.want, err)
+			}
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) || apiErr.StatusCode != tt.status {
+				t.Errorf("%s: expected *APIError with status %d, got: %#v", tt.name, tt.status, err)
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.message) {
+				t.Errorf("%s: expected raw message %q to be kept, got: %v", tt.name, tt.message, err)
+			}
+		})
+	}
+}
+
+func TestAPIError_UnknownErrorHasNoSentinel(t *testing.T) {
+	err := &APIError{Provider: "OpenAI", StatusCode: http.StatusBadRequest, Message: "context too long", Type: "invalid_request_error"}
+	for _, sentinel := range []error{ErrInsufficientQuota, ErrRateLimited, ErrInvalidAPIKey} {
+		if errors.Is(err, sentinel) {
+			t.Errorf("did not expect %v to match %v", err, sentinel)
+		}
+	}
+	want := "OpenAI API error: context too long (type: invalid_request_error, status: 400)"
+	if err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+}
+
+func TestErrorCode(t *testing.T) {
+	tests := map[string]string{
+		`"invalid_api_key"`: "invalid_api_key",
+		`402`:               "402",
+		`null`:              "",
+		``:                  "",
+	}
+	for raw, want := range tests {
+		if got := errorCode([]byte(raw)); got != want {
+			t.Errorf("errorCode(%s) = %q, want %q", raw, got, want)
+		}
+	}
+}
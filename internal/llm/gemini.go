@@ -0,0 +1,88 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/url"
+)
+
+const (
+	defaultGeminiBaseURL = "https://generativelanguage.googleapis.com/v1beta/models"
+	defaultGeminiModel   = "gemini-1.5-pro"
+)
+
+// sendGemini sends the prompt to Google's Gemini generateContent API and returns the response text.
+// If no endpoint is configured, the endpoint is derived from the model name.
+func (c *Client) sendGemini(ctx context.Context, prompt string) (*ReviewResponse, error) {
+	if c.APIKey == "" {
+		return nil, errors.New("missing Gemini API key")
+	}
+	model := c.model()
+	endpoint := c.Endpoint
+	if endpoint == "" {
+		endpoint = defaultGeminiBaseURL + "/" + url.PathEscape(model) + ":generateContent"
+	}
+
+	c.logRequest(endpoint, model)
+
+	reqBody := map[string]interface{}{
+		"contents": []map[string]interface{}{
+			{
+				"role":  "user",
+				"parts": []map[string]string{{"text": prompt}},
+			},
+		},
+		"generationConfig": map[string]interface{}{
+			"temperature":     0.2,
+			"maxOutputTokens": 2048,
+		},
+	}
+	// The key goes in a header rather than the query string so it stays out of logged URLs
+	resp, err := c.postJSON(ctx, "Gemini", endpoint, reqBody, map[string]string{"x-goog-api-key": c.APIKey})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var geminiResp struct {
+		Candidates []struct {
+			Content struct {
+				Parts []struct {
+					Text string `json:"text"`
+				} `json:"parts"`
+			} `json:"content"`
+			FinishReason string `json:"finishReason"`
+		} `json:"candidates"`
+		UsageMetadata *struct {
+			PromptTokenCount     int `json:"promptTokenCount"`
+			CandidatesTokenCount int `json:"candidatesTokenCount"`
+			TotalTokenCount      int `json:"totalTokenCount"`
+		} `json:"usageMetadata"`
+	}
+	err = c.decodeJSONResponse("Gemini", resp, &geminiResp, func(body []byte, apiErr *APIError) {
+		var errorResponse struct {
+			Error struct {
+				Message string `json:"message"`
+				Status  string `json:"status"`
+			} `json:"error"`
+		}
+		_ = json.Unmarshal(body, &errorResponse)
+		apiErr.Message, apiErr.Type = errorResponse.Error.Message, errorResponse.Error.Status
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(geminiResp.Candidates) == 0 || len(geminiResp.Candidates[0].Content.Parts) == 0 {
+		return nil, errors.New("no candidates returned from Gemini API")
+	}
+	result := &ReviewResponse{Content: geminiResp.Candidates[0].Content.Parts[0].Text}
+	if m := geminiResp.UsageMetadata; m != nil {
+		result.Usage = &Usage{
+			PromptTokens:     m.PromptTokenCount,
+			CompletionTokens: m.CandidatesTokenCount,
+			TotalTokens:      m.TotalTokenCount,
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,146 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestSendReviewPrompt_Gemini(t *testing.T) {
+	client := &Client{
+		Provider: "gemini",
+		APIKey:   "gemini-key",
+	}
+	withMockHTTPClient(func(req *http.Request) *http.Response {
+		want := defaultGeminiBaseURL + "/gemini-1.5-pro:generateContent"
+		if req.URL.String() != want {
+			t.Errorf("expected endpoint %s, got %s", want, req.URL.String())
+		}
+		if req.Header.Get("x-goog-api-key") != "gemini-key" {
+			t.Errorf("expected x-goog-api-key header, got %q", req.Header.Get("x-goog-api-key"))
+		}
+		body, _ := io.ReadAll(req.Body)
+		var reqBody struct {
+			Contents []struct {
+				Parts []struct {
+					Text string `json:"text"`
+				} `json:"parts"`
+			} `json:"contents"`
+			GenerationConfig struct {
+				Temperature     float64 `json:"temperature"`
+				MaxOutputTokens int     `json:"maxOutputTokens"`
+			} `json:"generationConfig"`
+		}
+		if err := json.Unmarshal(body, &reqBody); err != nil {
+			t.Fatalf("invalid request body: %v", err)
+		}
+		if len(reqBody.Contents) != 1 || len(reqBody.Contents[0].Parts) != 1 || reqBody.Contents[0].Parts[0].Text != "review this" {
+			t.Errorf("unexpected contents %+v", reqBody.Contents)
+		}
+		if reqBody.GenerationConfig.Temperature != 0.2 || reqBody.GenerationConfig.MaxOutputTokens != 2048 {
+			t.Errorf("unexpected generation config %+v", reqBody.GenerationConfig)
+		}
+		resp := `{
+			"candidates": [{
+				"content": {"role": "model", "parts": [{"text": "Gemini review"}]},
+				"finishReason": "STOP"
+			}]
+		}`
+		return &http.Response{
+			StatusCode: 200,
+			Body:       io.NopCloser(bytes.NewBufferString(resp)),
+			Header:     make(http.Header),
+		}
+	}, func() {
+		resp, err := client.SendReviewPrompt("review this")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if resp != "Gemini review" {
+			t.Errorf("Expected 'Gemini review', got '%s'", resp)
+		}
+	})
+}
+
+func TestSendReviewPrompt_GeminiModelInEndpoint(t *testing.T) {
+	client := &Client{
+		Provider: "gemini",
+		APIKey:   "gemini-key",
+		Model:    "gemini-1.5-flash",
+	}
+	withMockHTTPClient(func(req *http.Request) *http.Response {
+		if !strings.HasSuffix(req.URL.Path, "/models/gemini-1.5-flash:generateContent") {
+			t.Errorf("expected configured model in endpoint, got %s", req.URL.String())
+		}
+		return &http.Response{
+			StatusCode: 200,
+			Body:       io.NopCloser(bytes.NewBufferString(`{"candidates":[{"content":{"parts":[{"text":"ok"}]}}]}`)),
+			Header:     make(http.Header),
+		}
+	}, func() {
+		if _, err := client.SendReviewPrompt("review this"); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	})
+}
+
+func TestSendReviewPrompt_GeminiErrorResponse(t *testing.T) {
+	client := &Client{Provider: "gemini", APIKey: "bad"}
+	withMockHTTPClient(func(req *http.Request) *http.Response {
+		resp := `{"error":{"code":400,"message":"API key not valid. Please pass a valid API key.","status":"INVALID_ARGUMENT"}}`
+		return &http.Response{
+			StatusCode: 400,
+			Body:       io.NopCloser(bytes.NewBufferString(resp)),
+			Header:     make(http.Header),
+		}
+	}, func() {
+		_, err := client.SendReviewPrompt("review this")
+		if err == nil || !strings.Contains(err.Error(), "API key not valid") || !strings.Contains(err.Error(), "INVALID_ARGUMENT") {
+			t.Errorf("Expected Gemini API error, got: %v", err)
+		}
+	})
+}
+
+func TestSendReviewPrompt_GeminiNoCandidates(t *testing.T) {
+	client := &Client{Provider: "gemini", APIKey: "gemini-key"}
+	withMockHTTPClient(func(req *http.Request) *http.Response {
+		return &http.Response{
+			StatusCode: 200,
+			Body:       io.NopCloser(bytes.NewBufferString(`{"candidates":[]}`)),
+			Header:     make(http.Header),
+		}
+	}, func() {
+		_, err := client.SendReviewPrompt("review this")
+		if err == nil || !strings.Contains(err.Error(), "no candidates returned from Gemini API") {
+			t.Errorf("Expected no candidates error, got: %v", err)
+		}
+	})
+}
+
+func TestSendReview_GeminiUsage(t *testing.T) {
+	client := &Client{Provider: "gemini", APIKey: "gemini-key"}
+	withMockHTTPClient(func(req *http.Request) *http.Response {
+		resp := `{
+			"candidates": [{"content": {"parts": [{"text": "ok"}]}}],
+			"usageMetadata": {"promptTokenCount": 800, "candidatesTokenCount": 50, "totalTokenCount": 850}
+		}`
+		return &http.Response{
+			StatusCode: 200,
+			Body:       io.NopCloser(bytes.NewBufferString(resp)),
+			Header:     make(http.Header),
+		}
+	}, func() {
+		resp, err := client.SendReview(context.Background(), "review this")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		want := Usage{PromptTokens: 800, CompletionTokens: 50, TotalTokens: 850}
+		if resp.Usage == nil || *resp.Usage != want {
+			t.Errorf("expected usage %+v, got %+v", want, resp.Usage)
+		}
+	})
+}
@@ -0,0 +1,73 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+const (
+	defaultOllamaEndpoint = "http://localhost:11434"
+	defaultOllamaModel    = "llama3"
+)
+
+// sendOllama sends the prompt to a local Ollama server's chat API and returns the response text.
+// The endpoint is the server's base URL; no API key is required.
+func (c *Client) sendOllama(ctx context.Context, prompt string) (*ReviewResponse, error) {
+	endpoint := strings.TrimRight(c.Endpoint, "/")
+	if endpoint == "" {
+		endpoint = defaultOllamaEndpoint
+	}
+	if !strings.HasSuffix(endpoint, "/api/chat") {
+		endpoint += "/api/chat"
+	}
+	model := c.model()
+
+	c.logRequest(endpoint, model)
+
+	reqBody := map[string]interface{}{
+		"model": model,
+		"messages": []map[string]string{
+			{"role": "user", "content": prompt},
+		},
+		"stream": false,
+		"options": map[string]interface{}{
+			"temperature": 0.2,
+			"num_predict": 2048,
+		},
+	}
+	resp, err := c.postJSON(ctx, "Ollama", endpoint, reqBody, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var ollamaResp struct {
+		Message struct {
+			Role    string `json:"role"`
+			Content string `json:"content"`
+		} `json:"message"`
+		PromptEvalCount int `json:"prompt_eval_count"`
+		EvalCount       int `json:"eval_count"`
+	}
+	err = c.decodeJSONResponse("Ollama", resp, &ollamaResp, func(body []byte, apiErr *APIError) {
+		var errorResponse struct {
+			Error string `json:"error"`
+		}
+		_ = json.Unmarshal(body, &errorResponse)
+		apiErr.Message = errorResponse.Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	if ollamaResp.Message.Content == "" {
+		return nil, errors.New("no message content returned from Ollama")
+	}
+	usage := &Usage{
+		PromptTokens:     ollamaResp.PromptEvalCount,
+		CompletionTokens: ollamaResp.EvalCount,
+		TotalTokens:      ollamaResp.PromptEvalCount + ollamaResp.EvalCount,
+	}
+	return &ReviewResponse{Content: ollamaResp.Message.Content, Usage: usage}, nil
+}
@@ -0,0 +1,108 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestSendReviewPrompt_Ollama(t *testing.T) {
+	client := &Client{
+		Provider: "ollama",
+		Model:    "codellama",
+	}
+	withMockHTTPClient(func(req *http.Request) *http.Response {
+		if req.URL.String() != "http://localhost:11434/api/chat" {
+			t.Errorf("expected default Ollama endpoint, got %s", req.URL.String())
+		}
+		if req.Header.Get("Authorization") != "" {
+			t.Error("Ollama requests must not send an Authorization header")
+		}
+		body, _ := io.ReadAll(req.Body)
+		var reqBody struct {
+			Model    string `json:"model"`
+			Stream   *bool  `json:"stream"`
+			Messages []struct {
+				Role    string `json:"role"`
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		if err := json.Unmarshal(body, &reqBody); err != nil {
+			t.Fatalf("invalid request body: %v", err)
+		}
+		if reqBody.Model != "codellama" {
+			t.Errorf("expected model 'codellama', got %q", reqBody.Model)
+		}
+		if reqBody.Stream == nil || *reqBody.Stream {
+			t.Error("expected stream:false in request")
+		}
+		if len(reqBody.Messages) != 1 || reqBody.Messages[0].Content != "review this" {
+			t.Errorf("unexpected messages %+v", reqBody.Messages)
+		}
+		resp := `{
+			"model": "codellama",
+			"created_at": "2024-05-01T12:00:00Z",
+			"message": {"role": "assistant", "content": "Local review"},
+			"done": true,
+			"prompt_eval_count": 640,
+			"eval_count": 60
+		}`
+		return &http.Response{
+			StatusCode: 200,
+			Body:       io.NopCloser(bytes.NewBufferString(resp)),
+			Header:     make(http.Header),
+		}
+	}, func() {
+		resp, err := client.SendReview(context.Background(), "review this")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if resp.Content != "Local review" {
+			t.Errorf("Expected 'Local review', got '%s'", resp.Content)
+		}
+		want := Usage{PromptTokens: 640, CompletionTokens: 60, TotalTokens: 700}
+		if resp.Usage == nil || *resp.Usage != want {
+			t.Errorf("expected usage %+v, got %+v", want, resp.Usage)
+		}
+	})
+}
+
+func TestSendReviewPrompt_OllamaCustomEndpoint(t *testing.T) {
+	for _, endpoint := range []string{"http://gpu-box:11434", "http://gpu-box:11434/", "http://gpu-box:11434/api/chat"} {
+		client := &Client{Provider: "ollama", Endpoint: endpoint}
+		withMockHTTPClient(func(req *http.Request) *http.Response {
+			if req.URL.String() != "http://gpu-box:11434/api/chat" {
+				t.Errorf("endpoint %q: expected http://gpu-box:11434/api/chat, got %s", endpoint, req.URL.String())
+			}
+			return &http.Response{
+				StatusCode: 200,
+				Body:       io.NopCloser(bytes.NewBufferString(`{"message":{"role":"assistant","content":"ok"}}`)),
+				Header:     make(http.Header),
+			}
+		}, func() {
+			if _, err := client.SendReviewPrompt("review this"); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestSendReviewPrompt_OllamaErrorResponse(t *testing.T) {
+	client := &Client{Provider: "ollama", Model: "missing-model"}
+	withMockHTTPClient(func(req *http.Request) *http.Response {
+		return &http.Response{
+			StatusCode: 404,
+			Body:       io.NopCloser(bytes.NewBufferString(`{"error":"model \"missing-model\" not found, try pulling it first"}`)),
+			Header:     make(http.Header),
+		}
+	}, func() {
+		_, err := client.SendReviewPrompt("review this")
+		if err == nil || !strings.Contains(err.Error(), "try pulling it first") {
+			t.Errorf("Expected Ollama API error, got: %v", err)
+		}
+	})
+}
@@ -0,0 +1,62 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// logRequest logs where a request is about to go, in verbose mode only.
+func (c *Client) logRequest(endpoint, model string) {
+	c.logger().Debug("Provider: %s", c.Provider)
+	c.logger().Debug("Endpoint: %s", endpoint)
+	c.logger().Debug("Model: %s", model)
+}
+
+// postJSON marshals body and POSTs it to endpoint with headers and a JSON content type,
+// retrying as doWithRetry does. api names the provider in error messages, e.g. "Gemini". The
+// caller must close the response body.
+func (c *Client) postJSON(ctx context.Context, api, endpoint string, body any, headers map[string]string) (*http.Response, error) {
+	bodyBytes, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %s request: %w", api, err)
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewReader(bodyBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s request: %w", api, err)
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.doWithRetry(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to contact %s API at %s: %w", api, endpoint, err)
+	}
+	return resp, nil
+}
+
+// decodeJSONResponse reads resp and decodes a successful body into out. Any other status is
+// returned as an *APIError for api (the provider's display name, as for postJSON), with the
+// details parseError extracts from the body.
+func (c *Client) decodeJSONResponse(api string, resp *http.Response, out any, parseError func(body []byte, apiErr *APIError)) error {
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read %s response: %w", api, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		c.logger().Debug("Raw error response from LLM:\n%s", string(respBody))
+		apiErr := &APIError{Provider: api, StatusCode: resp.StatusCode}
+		parseError(respBody, apiErr)
+		return apiErr
+	}
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("failed to parse %s response: %w", api, err)
+	}
+	c.logger().Debug("Raw success response from LLM:\n%s", string(respBody))
+	return nil
+}
@@ -0,0 +1,128 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// DefaultMaxAttempts is the number of attempts made for an LLM request when Client.MaxAttempts is unset.
+	DefaultMaxAttempts = 3
+	// maxRetryDelay caps both exponential backoff and server-provided Retry-After delays.
+	maxRetryDelay = 60 * time.Second
+)
+
+// retryBaseDelay is the delay before the first retry; it doubles on each subsequent attempt.
+// It and sleep are variables so tests can avoid real waits.
+var (
+	retryBaseDelay = 2 * time.Second
+	sleep          = sleepContext
+)
+
+// sleepContext waits for d, returning early with the context's error if ctx is done first.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// doWithRetry sends req, retrying on 429 and 5xx responses with exponential backoff, except for
+// a 429 reporting exhausted quota, which no amount of waiting fixes. A Retry-After
+// header, when present, overrides the computed delay. The request body is rewound via GetBody
+// before each retry, so req must have been created with a replayable body (e.g. bytes.Reader).
+// The last response is returned as-is once attempts are exhausted. Waiting between attempts
+// stops early if the request's context is done.
+func (c *Client) doWithRetry(req *http.Request) (*http.Response, error) {
+	attempts := c.MaxAttempts
+	if attempts <= 0 {
+		attempts = DefaultMaxAttempts
+	}
+	for attempt := 1; ; attempt++ {
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		if !isRetryableStatus(resp.StatusCode) || attempt >= attempts {
+			return resp, nil
+		}
+		if resp.StatusCode == http.StatusTooManyRequests {
+			body, err := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if err != nil {
+				return nil, fmt.Errorf("failed to read rate limit response: %w", err)
+			}
+			resp.Body = io.NopCloser(bytes.NewReader(body))
+			if isQuotaExhausted(body) {
+				return resp, nil
+			}
+		}
+		delay := retryDelay(resp.Header.Get("Retry-After"), attempt)
+		// Drain so the connection can be reused
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, fmt.Errorf("failed to rewind request body for retry: %w", err)
+			}
+			req.Body = body
+		}
+		c.logger().Debug("Got HTTP %d, retrying in %s (attempt %d of %d)",
+			resp.StatusCode, delay, attempt+1, attempts)
+		if err := sleep(req.Context(), delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// isRetryableStatus reports whether a response status indicates a transient failure.
+func isRetryableStatus(status int) bool {
+	return status == http.StatusTooManyRequests || status >= 500
+}
+
+// isQuotaExhausted reports whether an error response body carries an OpenAI-style error code or
+// type saying the account is out of quota or credit.
+func isQuotaExhausted(body []byte) bool {
+	var parsed struct {
+		Error struct {
+			Code json.RawMessage `json:"code"`
+			Type string          `json:"type"`
+		} `json:"error"`
+	}
+	if json.Unmarshal(body, &parsed) != nil {
+		return false
+	}
+	return isQuotaCode(errorCode(parsed.Error.Code)) || isQuotaCode(parsed.Error.Type)
+}
+
+// retryDelay returns how long to wait before the next attempt, preferring the server's
+// Retry-After value (in seconds or as an HTTP date) over exponential backoff.
+func retryDelay(retryAfter string, attempt int) time.Duration {
+	delay := retryBaseDelay << (attempt - 1)
+	if retryAfter != "" {
+		if secs, err := strconv.Atoi(retryAfter); err == nil && secs >= 0 {
+			delay = time.Duration(secs) * time.Second
+		} else if t, err := http.ParseTime(retryAfter); err == nil {
+			delay = time.Until(t)
+			if delay < 0 {
+				delay = 0
+			}
+		}
+	}
+	if delay > maxRetryDelay {
+		delay = maxRetryDelay
+	}
+	return delay
+}
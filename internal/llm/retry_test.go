@@ -0,0 +1,204 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// withNoSleep replaces the retry sleep with a recorder for the duration of the test.
+func withNoSleep(t *testing.T) *[]time.Duration {
+	t.Helper()
+	var delays []time.Duration
+	origSleep := sleep
+	sleep = func(_ context.Context, d time.Duration) error {
+		delays = append(delays, d)
+		return nil
+	}
+	t.Cleanup(func() { sleep = origSleep })
+	return &delays
+}
+
+func TestSendReviewPrompt_RetriesOnRateLimit(t *testing.T) {
+	delays := withNoSleep(t)
+	client := &Client{
+		Provider: "openrouter",
+		APIKey:   "dummy",
+		Endpoint: "http://example.com",
+	}
+	var calls int
+	var bodies []string
+	withMockHTTPClient(func(req *http.Request) *http.Response {
+		calls++
+		body, _ := io.ReadAll(req.Body)
+		bodies = append(bodies, string(body))
+		if calls <= 2 {
+			header := make(http.Header)
+			if calls == 1 {
+				header.Set("Retry-After", "7")
+			}
+			return &http.Response{
+				StatusCode: http.StatusTooManyRequests,
+				Body:       io.NopCloser(bytes.NewBufferString(`{"error":{"message":"Rate limit exceeded"}}`)),
+				Header:     header,
+			}
+		}
+		return &http.Response{
+			StatusCode: 200,
+			Body:       io.NopCloser(bytes.NewBufferString(`{"choices":[{"message":{"content":"Finally"}}]}`)),
+			Header:     make(http.Header),
+		}
+	}, func() {
+		resp, err := client.SendReviewPrompt("test prompt")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if resp != "Finally" {
+			t.Errorf("Expected 'Finally', got '%s'", resp)
+		}
+	})
+	if calls != 3 {
+		t.Fatalf("expected 3 attempts, got %d", calls)
+	}
+	for i, b := range bodies {
+		if b == "" || b != bodies[0] {
+			t.Errorf("attempt %d sent a different body: %q", i+1, b)
+		}
+	}
+	if len(*delays) != 2 || (*delays)[0] != 7*time.Second || (*delays)[1] != 2*retryBaseDelay {
+		t.Errorf("expected delays [7s %s], got %v", 2*retryBaseDelay, *delays)
+	}
+}
+
+func TestSendReviewPrompt_RetryGivesUpAfterMaxAttempts(t *testing.T) {
+	withNoSleep(t)
+	client := &Client{
+		Provider:    "openai",
+		APIKey:      "dummy",
+		Endpoint:    "http://example.com",
+		MaxAttempts: 2,
+	}
+	var calls int
+	withMockHTTPClient(func(req *http.Request) *http.Response {
+		calls++
+		return &http.Response{
+			StatusCode: http.StatusBadGateway,
+			Body:       io.NopCloser(bytes.NewBufferString(`{"error":{"message":"upstream unavailable"}}`)),
+			Header:     make(http.Header),
+		}
+	}, func() {
+		_, err := client.SendReviewPrompt("test prompt")
+		if err == nil || !strings.Contains(err.Error(), "upstream unavailable") {
+			t.Errorf("Expected final error to be returned, got: %v", err)
+		}
+	})
+	if calls != 2 {
+		t.Errorf("expected 2 attempts, got %d", calls)
+	}
+}
+
+func TestSendReviewPrompt_NoRetryOnClientError(t *testing.T) {
+	delays := withNoSleep(t)
+	client := &Client{
+		Provider: "openai",
+		APIKey:   "dummy",
+		Endpoint: "http://example.com",
+	}
+	var calls int
+	withMockHTTPClient(func(req *http.Request) *http.Response {
+		calls++
+		return &http.Response{
+			StatusCode: http.StatusUnauthorized,
+			Body:       io.NopCloser(bytes.NewBufferString(`{"error":{"message":"bad key"}}`)),
+			Header:     make(http.Header),
+		}
+	}, func() {
+		if _, err := client.SendReviewPrompt("test prompt"); err == nil {
+			t.Error("expected error for 401")
+		}
+	})
+	if calls != 1 || len(*delays) != 0 {
+		t.Errorf("expected a single attempt without sleeping, got %d attempts and delays %v", calls, *delays)
+	}
+}
+
+func TestSendReviewPrompt_NoRetryOnExhaustedQuota(t *testing.T) {
+	delays := withNoSleep(t)
+	client := &Client{
+		Provider: "openai",
+		APIKey:   "dummy",
+		Endpoint: "http://example.com",
+	}
+	var calls int
+	withMockHTTPClient(func(req *http.Request) *http.Response {
+		calls++
+		return &http.Response{
+			StatusCode: http.StatusTooManyRequests,
+			Body:       io.NopCloser(bytes.NewBufferString(`{"error":{"message":"You exceeded your current quota","type":"insufficient_quota","code":"insufficient_quota"}}`)),
+			Header:     make(http.Header),
+		}
+	}, func() {
+		_, err := client.SendReviewPrompt("test prompt")
+		if !errors.Is(err, ErrInsufficientQuota) {
+			t.Errorf("expected ErrInsufficientQuota, got %v", err)
+		}
+	})
+	if calls != 1 || len(*delays) != 0 {
+		t.Errorf("expected a single attempt without sleeping, got %d attempts and delays %v", calls, *delays)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	tests := []struct {
+		name       string
+		retryAfter string
+		attempt    int
+		want       time.Duration
+	}{
+		{"first backoff", "", 1, retryBaseDelay},
+		{"third backoff", "", 3, 4 * retryBaseDelay},
+		{"retry-after seconds", "3", 1, 3 * time.Second},
+		{"retry-after capped", "3600", 1, maxRetryDelay},
+		{"backoff capped", "", 10, maxRetryDelay},
+		{"past date", "Mon, 02 Jan 2006 15:04:05 GMT", 1, 0},
+		{"unparseable falls back", "soon", 2, 2 * retryBaseDelay},
+	}
+	for _, tt := range tests {
+		if got := retryDelay(tt.retryAfter, tt.attempt); got != tt.want {
+			t.Errorf("%s: retryDelay(%q, %d) = %s, want %s", tt.name, tt.retryAfter, tt.attempt, got, tt.want)
+		}
+	}
+}
+
+func TestSendReview_RetryWaitStopsOnCancel(t *testing.T) {
+	client := &Client{
+		Provider: "openai",
+		APIKey:   "dummy",
+		Endpoint: "http://example.com",
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	withMockHTTPClient(func(req *http.Request) *http.Response {
+		cancel()
+		header := make(http.Header)
+		header.Set("Retry-After", "30")
+		return &http.Response{
+			StatusCode: http.StatusTooManyRequests,
+			Body:       io.NopCloser(bytes.NewBufferString(`{}`)),
+			Header:     header,
+		}
+	}, func() {
+		start := time.Now()
+		_, err := client.SendReview(ctx, "test prompt")
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected context.Canceled, got: %v", err)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("retry wait was not interrupted, took %s", elapsed)
+		}
+	})
+}
@@ -0,0 +1,172 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func sseResponse(events ...string) *http.Response {
+	var body strings.Builder
+	for _, e := range events {
+		body.WriteString(e)
+		body.WriteString("\n\n")
+	}
+	header := make(http.Header)
+	header.Set("Content-Type", "text/event-stream; charset=utf-8")
+	return &http.Response{
+		StatusCode: 200,
+		Body:       io.NopCloser(strings.NewReader(body.String())),
+		Header:     header,
+	}
+}
+
+func TestSendReview_Streaming(t *testing.T) {
+	var chunks []string
+	client := &Client{
+		Provider: "openrouter",
+		APIKey:   "dummy",
+		Endpoint: "http://example.com",
+		Stream:   true,
+		OnChunk:  func(s string) { chunks = append(chunks, s) },
+	}
+	withMockHTTPClient(func(req *http.Request) *http.Response {
+		body, _ := io.ReadAll(req.Body)
+		if !strings.Contains(string(body), `"stream":true`) {
+			t.Errorf("expected stream:true in request body, got %s", body)
+		}
+		return sseResponse(
+			": OPENROUTER PROCESSING",
+			`data: {"choices":[{"delta":{"role":"assistant","content":""}}]}`,
+			`data: {"choices":[{"delta":{"content":"Looks "}}]}`,
+			`data: {"choices":[{"delta":{"content":"good"}}]}`,
+			`data: {"choices":[{"delta":{"content":" overall."}}]}`,
+			`data: {"choices":[{"delta":{},"finish_reason":"stop"}],"usage":{"prompt_tokens":10,"completion_tokens":3,"total_tokens":13}}`,
+			"data: [DONE]",
+		)
+	}, func() {
+		resp, err := client.SendReview(context.Background(), "test prompt")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if resp.Content != "Looks good overall." {
+			t.Errorf("expected concatenated content, got %q", resp.Content)
+		}
+		if resp.Usage == nil || resp.Usage.TotalTokens != 13 {
+			t.Errorf("expected usage from final chunk, got %+v", resp.Usage)
+		}
+		if !resp.Streamed {
+			t.Error("expected the response to be marked as streamed")
+		}
+	})
+	if strings.Join(chunks, "|") != "Looks |good| overall." {
+		t.Errorf("expected one callback per content chunk, got %q", chunks)
+	}
+}
+
+func TestSendReview_StreamingFallsBackToJSON(t *testing.T) {
+	client := &Client{
+		Provider: "openai",
+		APIKey:   "dummy",
+		Endpoint: "http://example.com",
+		Stream:   true,
+		OnChunk:  func(string) { t.Error("callback must not be called for a non-streamed response") },
+	}
+	withMockHTTPClient(func(req *http.Request) *http.Response {
+		header := make(http.Header)
+		header.Set("Content-Type", "application/json")
+		return &http.Response{
+			StatusCode: 200,
+			Body:       io.NopCloser(bytes.NewBufferString(`{"choices":[{"message":{"content":"Whole response"}}]}`)),
+			Header:     header,
+		}
+	}, func() {
+		resp, err := client.SendReview(context.Background(), "test prompt")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if resp.Content != "Whole response" || resp.Streamed {
+			t.Errorf("expected the whole non-streamed response, got %+v", resp)
+		}
+	})
+}
+
+func TestSendReview_RetriesWithoutStreamingWhenRejected(t *testing.T) {
+	client := &Client{
+		Provider: "openai",
+		APIKey:   "dummy",
+		Endpoint: "http://example.com",
+		Stream:   true,
+		OnChunk:  func(string) { t.Error("callback must not be called for a non-streamed response") },
+	}
+	var bodies []string
+	withMockHTTPClient(func(req *http.Request) *http.Response {
+		body, _ := io.ReadAll(req.Body)
+		bodies = append(bodies, string(body))
+		if strings.Contains(string(body), `"stream":true`) {
+			return &http.Response{
+				StatusCode: http.StatusBadRequest,
+				Body:       io.NopCloser(bytes.NewBufferString(`{"error":{"message":"Unsupported value: 'stream' does not support true with this model.","type":"invalid_request_error","param":"stream"}}`)),
+				Header:     make(http.Header),
+			}
+		}
+		return &http.Response{
+			StatusCode: 200,
+			Body:       io.NopCloser(bytes.NewBufferString(`{"choices":[{"message":{"content":"Whole response"}}]}`)),
+			Header:     make(http.Header),
+		}
+	}, func() {
+		resp, err := client.SendReview(context.Background(), "test prompt")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if resp.Content != "Whole response" || resp.Streamed {
+			t.Errorf("expected the non-streamed response, got %+v", resp)
+		}
+	})
+	if len(bodies) != 2 || strings.Contains(bodies[1], `"stream"`) {
+		t.Errorf("expected a streamed request followed by one without stream, got %q", bodies)
+	}
+}
+
+func TestSendReview_StreamingError(t *testing.T) {
+	client := &Client{
+		Provider: "openrouter",
+		APIKey:   "dummy",
+		Endpoint: "http://example.com",
+		Stream:   true,
+	}
+	withMockHTTPClient(func(req *http.Request) *http.Response {
+		return sseResponse(
+			`data: {"choices":[{"delta":{"content":"Partial"}}]}`,
+			`data: {"error":{"message":"Provider returned error"}}`,
+		)
+	}, func() {
+		_, err := client.SendReview(context.Background(), "test prompt")
+		if err == nil || !strings.Contains(err.Error(), "Provider returned error") {
+			t.Errorf("expected mid-stream error, got: %v", err)
+		}
+	})
+}
+
+func TestSendReview_NoStreamFieldByDefault(t *testing.T) {
+	client := &Client{Provider: "openai", APIKey: "dummy", Endpoint: "http://example.com"}
+	withMockHTTPClient(func(req *http.Request) *http.Response {
+		body, _ := io.ReadAll(req.Body)
+		if strings.Contains(string(body), `"stream"`) {
+			t.Errorf("did not expect a stream field, got %s", body)
+		}
+		return &http.Response{
+			StatusCode: 200,
+			Body:       io.NopCloser(bytes.NewBufferString(`{"choices":[{"message":{"content":"ok"}}]}`)),
+			Header:     make(http.Header),
+		}
+	}, func() {
+		if _, err := client.SendReview(context.Background(), "test prompt"); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	})
+}
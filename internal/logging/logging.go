@@ -0,0 +1,127 @@
+// Package logging provides the minimal leveled logger used by pullreview's packages, with a
+// console implementation for interactive use and a JSON-lines one for pipelines.
+package logging
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Logger receives progress and diagnostic messages. Debug messages are only emitted in
+// verbose mode. Arguments are formatted as with fmt.Sprintf.
+type Logger interface {
+	Info(format string, args ...any)
+	Debug(format string, args ...any)
+	Error(format string, args ...any)
+}
+
+// Supported log formats.
+const (
+	FormatConsole = "console"
+	FormatJSON    = "json"
+)
+
+// New returns a logger for component in the given format: console (the default if empty) or
+// json, which writes to stderr.
+func New(format, component string, verbose bool) (Logger, error) {
+	switch strings.ToLower(format) {
+	case "", FormatConsole:
+		return &Console{Prefix: "[" + component + "] ", Verbose: verbose}, nil
+	case FormatJSON:
+		return &JSON{Component: component, Verbose: verbose}, nil
+	default:
+		return nil, fmt.Errorf("invalid log format %q (must be %s or %s)", format, FormatConsole, FormatJSON)
+	}
+}
+
+// Console writes Info messages to Out and Debug and Error messages to Err, one per line with
+// Prefix prepended. Nil writers mean os.Stdout and os.Stderr, looked up on every write.
+type Console struct {
+	Out, Err io.Writer
+	Prefix   string
+	Verbose  bool
+}
+
+func (c *Console) Info(format string, args ...any) {
+	c.write(c.Out, os.Stdout, format, args)
+}
+
+func (c *Console) Debug(format string, args ...any) {
+	if c.Verbose {
+		c.write(c.Err, os.Stderr, format, args)
+	}
+}
+
+func (c *Console) Error(format string, args ...any) {
+	c.write(c.Err, os.Stderr, format, args)
+}
+
+func (c *Console) write(w, fallback io.Writer, format string, args []any) {
+	if w == nil {
+		w = fallback
+	}
+	fmt.Fprintln(w, c.Prefix+fmt.Sprintf(format, args...))
+}
+
+// JSON writes one JSON object per message to W (os.Stderr if nil), with the time, level,
+// component, and message. It is safe for concurrent use.
+type JSON struct {
+	W         io.Writer
+	Component string
+	Verbose   bool
+
+	mu  sync.Mutex
+	now func() time.Time // Overridden in tests
+}
+
+// Entry is a line written by JSON.
+type Entry struct {
+	Time      time.Time `json:"time"`
+	Level     string    `json:"level"`
+	Component string    `json:"component,omitempty"`
+	Message   string    `json:"msg"`
+}
+
+func (j *JSON) Info(format string, args ...any) {
+	j.write("info", format, args)
+}
+
+func (j *JSON) Debug(format string, args ...any) {
+	if j.Verbose {
+		j.write("debug", format, args)
+	}
+}
+
+func (j *JSON) Error(format string, args ...any) {
+	j.write("error", format, args)
+}
+
+func (j *JSON) write(level, format string, args []any) {
+	now := time.Now
+	if j.now != nil {
+		now = j.now
+	}
+	line, err := json.Marshal(Entry{Time: now().UTC(), Level: level, Component: j.Component, Message: fmt.Sprintf(format, args...)})
+	if err != nil {
+		return
+	}
+	w := j.W
+	if w == nil {
+		w = os.Stderr
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	w.Write(append(line, '\n'))
+}
+
+// Nop discards all messages.
+type Nop struct{}
+
+func (Nop) Info(string, ...any)  {}
+func (Nop) Debug(string, ...any) {}
+func (Nop) Error(string, ...any) {}
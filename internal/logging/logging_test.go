@@ -0,0 +1,59 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConsole(t *testing.T) {
+	var out, errOut bytes.Buffer
+	c := &Console{Out: &out, Err: &errOut, Prefix: "[llm] "}
+	c.Info("Using provider %q", "openai")
+	c.Debug("hidden")
+	c.Error("failed: %v", "boom")
+	c.Verbose = true
+	c.Debug("Endpoint: %s", "http://example.com")
+
+	if out.String() != "[llm] Using provider \"openai\"\n" {
+		t.Errorf("unexpected stdout %q", out.String())
+	}
+	if errOut.String() != "[llm] failed: boom\n[llm] Endpoint: http://example.com\n" {
+		t.Errorf("unexpected stderr %q", errOut.String())
+	}
+}
+
+func TestJSON(t *testing.T) {
+	var buf bytes.Buffer
+	at := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	j := &JSON{W: &buf, Component: "llm", now: func() time.Time { return at }}
+	j.Info("Using provider %q", "openai")
+	j.Debug("hidden")
+	j.Error("line one\nline two")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 JSON lines (debug is off), got %d: %q", len(lines), buf.String())
+	}
+	var e Entry
+	if err := json.Unmarshal([]byte(lines[1]), &e); err != nil {
+		t.Fatalf("invalid JSON line %q: %v", lines[1], err)
+	}
+	if e.Level != "error" || e.Component != "llm" || e.Message != "line one\nline two" || !e.Time.Equal(at) {
+		t.Errorf("unexpected entry %+v", e)
+	}
+}
+
+func TestNew(t *testing.T) {
+	if l, err := New("", "llm", false); err != nil || l.(*Console).Prefix != "[llm] " {
+		t.Errorf("expected a console logger by default, got %#v (%v)", l, err)
+	}
+	if l, err := New("JSON", "llm", true); err != nil || !l.(*JSON).Verbose {
+		t.Errorf("expected a verbose JSON logger, got %#v (%v)", l, err)
+	}
+	if _, err := New("xml", "llm", false); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
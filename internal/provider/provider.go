@@ -0,0 +1,31 @@
+// Package provider defines what the review flow needs from a code host, so pull requests on
+// Bitbucket and GitHub can be reviewed by the same engine.
+package provider
+
+import "context"
+
+// Comment is an inline comment to be posted on a PR.
+type Comment struct {
+	FilePath string // Relative file path for inline comments
+	Line     int    // Line number for inline comments (new file)
+	FromLine int    // Line number in the old file, for comments on deleted lines; used when Line is 0
+	Text     string // Markdown comment text
+}
+
+// PRInfo is what the review prompt is told about a PR besides its diff.
+type PRInfo struct {
+	Title       string
+	Description string
+}
+
+// Provider fetches a PR's diff and posts review comments on it.
+type Provider interface {
+	// GetPRInfo returns the PR's title and description.
+	GetPRInfo(ctx context.Context, prID string) (PRInfo, error)
+	// GetPRDiff returns the unified diff of the PR.
+	GetPRDiff(ctx context.Context, prID string) (string, error)
+	// PostInlineComment posts a comment anchored to a line of the PR diff.
+	PostInlineComment(ctx context.Context, prID string, cmt Comment) error
+	// PostSummaryComment posts a top-level comment on the PR.
+	PostSummaryComment(ctx context.Context, prID, text string) error
+}
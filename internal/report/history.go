@@ -0,0 +1,143 @@
+package report
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Outcomes of a review run recorded in the history.
+const (
+	OutcomePosted  = "posted"  // The review was posted to the PR
+	OutcomePrinted = "printed" // The review was only printed
+	OutcomeSkipped = "skipped" // The PR was not reviewed (e.g. already approved, or its diff was too large)
+	OutcomeFailed  = "failed"
+)
+
+// HistoryEntry is one review run recorded in the history.
+type HistoryEntry struct {
+	PRID     string    `json:"pr_id"`
+	Time     time.Time `json:"time"`
+	Comments int       `json:"comments"`         // Matched and unmatched findings
+	Tokens   int       `json:"tokens,omitempty"` // LLM tokens used, if the provider reports them
+	Outcome  string    `json:"outcome"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// NewHistoryEntry builds the history entry for a review result produced at t.
+func NewHistoryEntry(res ReviewResult, t time.Time) HistoryEntry {
+	e := HistoryEntry{
+		PRID:     res.PRID,
+		Time:     t.UTC(),
+		Comments: len(res.Matched) + len(res.Unmatched),
+		Tokens:   res.Tokens,
+		Error:    res.Error,
+	}
+	switch {
+	case res.Error != "":
+		e.Outcome = OutcomeFailed
+	case res.Skipped:
+		e.Outcome = OutcomeSkipped
+	case res.Posting.Posted:
+		e.Outcome = OutcomePosted
+	default:
+		e.Outcome = OutcomePrinted
+	}
+	return e
+}
+
+// History is a JSON-lines file of review runs, one HistoryEntry per line, kept to track review
+// quality over time. All methods are safe to call on a nil *History, which records nothing.
+type History struct {
+	Path string
+}
+
+// NewHistory returns the history stored at path, or nil if path is empty.
+func NewHistory(path string) *History {
+	if path == "" {
+		return nil
+	}
+	return &History{Path: path}
+}
+
+// Append adds entries to the end of the history file, creating it (and its directory) if
+// needed.
+func (h *History) Append(entries ...HistoryEntry) error {
+	if h == nil || len(entries) == 0 {
+		return nil
+	}
+	if dir := filepath.Dir(h.Path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("failed to create history directory: %w", err)
+		}
+	}
+	f, err := os.OpenFile(h.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open history file: %w", err)
+	}
+	enc := json.NewEncoder(f)
+	for _, e := range entries {
+		if err := enc.Encode(e); err != nil {
+			f.Close()
+			return fmt.Errorf("failed to write history entry: %w", err)
+		}
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write history file: %w", err)
+	}
+	return nil
+}
+
+// HistoryQuery selects history entries; zero fields match everything.
+type HistoryQuery struct {
+	PRID    string
+	Outcome string
+	Since   time.Time // Only entries at or after this time
+}
+
+func (q HistoryQuery) matches(e HistoryEntry) bool {
+	return (q.PRID == "" || e.PRID == q.PRID) &&
+		(q.Outcome == "" || e.Outcome == q.Outcome) &&
+		(q.Since.IsZero() || !e.Time.Before(q.Since))
+}
+
+// Query returns the entries matching q, oldest first. A history file that does not exist yet
+// has no entries.
+func (h *History) Query(q HistoryQuery) ([]HistoryEntry, error) {
+	if h == nil {
+		return nil, nil
+	}
+	f, err := os.Open(h.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history file: %w", err)
+	}
+	defer f.Close()
+
+	var entries []HistoryEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var e HistoryEntry
+		if err := json.Unmarshal(line, &e); err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid history entry: %w", h.Path, n, err)
+		}
+		if q.matches(e) {
+			entries = append(entries, e)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read history file: %w", err)
+	}
+	return entries, nil
+}
@@ -0,0 +1,105 @@
+package report
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"pullreview/internal/review"
+)
+
+func TestHistory_AppendAndQuery(t *testing.T) {
+	h := NewHistory(filepath.Join(t.TempDir(), "state", "history.jsonl"))
+	day := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	first := []HistoryEntry{
+		{PRID: "1", Time: day, Comments: 3, Tokens: 1500, Outcome: OutcomePosted},
+		{PRID: "2", Time: day.Add(time.Hour), Outcome: OutcomeFailed, Error: "LLM unavailable"},
+	}
+	second := HistoryEntry{PRID: "1", Time: day.Add(24 * time.Hour), Comments: 1, Outcome: OutcomePrinted}
+	if err := h.Append(first...); err != nil {
+		t.Fatalf("Append failed: %v", err)
+	}
+	if err := h.Append(second); err != nil {
+		t.Fatalf("second Append failed: %v", err)
+	}
+
+	all, err := h.Query(HistoryQuery{})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if want := append(first, second); !reflect.DeepEqual(all, want) {
+		t.Errorf("round trip mismatch:\ngot  %+v\nwant %+v", all, want)
+	}
+
+	tests := []struct {
+		name string
+		q    HistoryQuery
+		want int
+	}{
+		{"by PR", HistoryQuery{PRID: "1"}, 2},
+		{"by outcome", HistoryQuery{Outcome: OutcomeFailed}, 1},
+		{"since", HistoryQuery{Since: day.Add(time.Hour)}, 2},
+		{"combined", HistoryQuery{PRID: "1", Since: day.Add(time.Minute)}, 1},
+		{"no match", HistoryQuery{PRID: "9"}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := h.Query(tt.q)
+			if err != nil {
+				t.Fatalf("Query failed: %v", err)
+			}
+			if len(got) != tt.want {
+				t.Errorf("got %d entries, want %d: %+v", len(got), tt.want, got)
+			}
+		})
+	}
+}
+
+func TestHistory_MissingAndInvalid(t *testing.T) {
+	dir := t.TempDir()
+	entries, err := NewHistory(filepath.Join(dir, "none.jsonl")).Query(HistoryQuery{})
+	if err != nil || entries != nil {
+		t.Errorf("expected no entries for a missing file, got %v, %v", entries, err)
+	}
+
+	path := filepath.Join(dir, "bad.jsonl")
+	if err := os.WriteFile(path, []byte(`{"pr_id":"1","outcome":"posted"}`+"\n\nnot json\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewHistory(path).Query(HistoryQuery{}); err == nil || !strings.Contains(err.Error(), ":3:") {
+		t.Errorf("expected an error naming line 3, got %v", err)
+	}
+
+	// A nil history records nothing
+	if err := NewHistory("").Append(HistoryEntry{PRID: "1"}); err != nil {
+		t.Errorf("nil Append failed: %v", err)
+	}
+}
+
+func TestNewHistoryEntry(t *testing.T) {
+	at := time.Date(2025, 3, 1, 12, 0, 0, 0, time.FixedZone("EST", -5*3600))
+	posted := NewReviewResult("7", "ok", []review.Comment{{FilePath: "a.go", Line: 1, Text: "x"}},
+		[]review.Comment{{FilePath: "b.go", Text: "y"}}, Posting{Posted: true})
+	posted.Tokens = 900
+
+	tests := []struct {
+		name string
+		res  ReviewResult
+		want HistoryEntry
+	}{
+		{"posted", posted, HistoryEntry{PRID: "7", Time: at.UTC(), Comments: 2, Tokens: 900, Outcome: OutcomePosted}},
+		{"printed", NewReviewResult("8", "", nil, nil, Posting{}), HistoryEntry{PRID: "8", Time: at.UTC(), Outcome: OutcomePrinted}},
+		{"skipped", ReviewResult{PRID: "9", Skipped: true}, HistoryEntry{PRID: "9", Time: at.UTC(), Outcome: OutcomeSkipped}},
+		{"failed", ReviewResult{PRID: "10", Error: "boom"}, HistoryEntry{PRID: "10", Time: at.UTC(), Outcome: OutcomeFailed, Error: "boom"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewHistoryEntry(tt.res, at); got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,242 @@
+package report
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	"pullreview/internal/review"
+)
+
+// Finding is a single review comment attributed to the PR it was raised on.
+type Finding struct {
+	PRID        string `json:"pr_id"`
+	FilePath    string `json:"file"`
+	Line        int    `json:"line,omitempty"`
+	OldLine     int    `json:"old_line,omitempty"` // Old-file line for comments on deleted lines
+	IsFileLevel bool   `json:"file_level"`
+	Category    string `json:"category,omitempty"`
+	Severity    string `json:"severity,omitempty"`
+	Matched     bool   `json:"matched"` // Whether the comment mapped onto the PR diff
+	Text        string `json:"text"`
+}
+
+// PRResult summarizes the outcome of reviewing a single PR.
+type PRResult struct {
+	PRID     string `json:"pr_id"`
+	Title    string `json:"title,omitempty"`
+	Summary  string `json:"summary,omitempty"`
+	Findings int    `json:"findings"`
+	Skipped  bool   `json:"skipped,omitempty"` // The PR was not reviewed, e.g. its diff was too large
+	Error    string `json:"error,omitempty"`
+}
+
+// Report aggregates findings across many reviewed PRs.
+type Report struct {
+	PRs      []PRResult `json:"prs"`
+	Findings []Finding  `json:"findings"`
+}
+
+// AddReview records the comments produced for a PR. Matched comments are those that
+// mapped onto the diff; unmatched ones would have been folded into the summary.
+func (r *Report) AddReview(prID, title, summary string, matched, unmatched []review.Comment) {
+	for _, c := range matched {
+		r.Findings = append(r.Findings, newFinding(prID, c, true))
+	}
+	for _, c := range unmatched {
+		r.Findings = append(r.Findings, newFinding(prID, c, false))
+	}
+	r.PRs = append(r.PRs, PRResult{
+		PRID:     prID,
+		Title:    title,
+		Summary:  summary,
+		Findings: len(matched) + len(unmatched),
+	})
+}
+
+// AddSkipped records a PR that was deliberately not reviewed.
+func (r *Report) AddSkipped(prID, title string) {
+	r.PRs = append(r.PRs, PRResult{PRID: prID, Title: title, Skipped: true})
+}
+
+// AddFailure records a PR that could not be reviewed.
+func (r *Report) AddFailure(prID string, err error) {
+	r.PRs = append(r.PRs, PRResult{PRID: prID, Error: err.Error()})
+}
+
+func newFinding(prID string, c review.Comment, matched bool) Finding {
+	return Finding{
+		PRID:        prID,
+		FilePath:    c.FilePath,
+		Line:        c.Line,
+		OldLine:     c.OldLine,
+		IsFileLevel: c.IsFileLevel,
+		Category:    c.Category,
+		Severity:    c.Severity,
+		Matched:     matched,
+		Text:        c.Text,
+	}
+}
+
+// CategoryCounts returns the number of findings per category. Uncategorized findings are
+// counted under "uncategorized".
+func (r *Report) CategoryCounts() map[string]int {
+	counts := make(map[string]int)
+	for _, f := range r.Findings {
+		cat := f.Category
+		if cat == "" {
+			cat = "uncategorized"
+		}
+		counts[cat]++
+	}
+	return counts
+}
+
+// Totals holds aggregate numbers for a report.
+type Totals struct {
+	PRsReviewed int            `json:"prs_reviewed"`
+	PRsSkipped  int            `json:"prs_skipped"`
+	PRsFailed   int            `json:"prs_failed"`
+	Findings    int            `json:"findings"`
+	Matched     int            `json:"matched"`
+	ByCategory  map[string]int `json:"by_category"`
+}
+
+// Totals computes aggregate numbers across all PRs in the report.
+func (r *Report) Totals() Totals {
+	t := Totals{ByCategory: r.CategoryCounts()}
+	for _, pr := range r.PRs {
+		switch {
+		case pr.Error != "":
+			t.PRsFailed++
+		case pr.Skipped:
+			t.PRsSkipped++
+		default:
+			t.PRsReviewed++
+		}
+	}
+	for _, f := range r.Findings {
+		t.Findings++
+		if f.Matched {
+			t.Matched++
+		}
+	}
+	return t
+}
+
+// WriteJSON writes the full report, including totals, as indented JSON.
+func (r *Report) WriteJSON(w io.Writer) error {
+	out := struct {
+		Totals   Totals     `json:"totals"`
+		PRs      []PRResult `json:"prs"`
+		Findings []Finding  `json:"findings"`
+	}{
+		Totals:   r.Totals(),
+		PRs:      r.PRs,
+		Findings: r.Findings,
+	}
+	if out.PRs == nil {
+		out.PRs = []PRResult{}
+	}
+	if out.Findings == nil {
+		out.Findings = []Finding{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(out); err != nil {
+		return fmt.Errorf("failed to encode JSON report: %w", err)
+	}
+	return nil
+}
+
+// csvHeader is the column layout used by WriteCSV.
+var csvHeader = []string{"pr_id", "file", "line", "file_level", "category", "matched", "text"}
+
+// WriteCSV writes one row per finding, sorted by PR ID, file, and line.
+func (r *Report) WriteCSV(w io.Writer) error {
+	findings := make([]Finding, len(r.Findings))
+	copy(findings, r.Findings)
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].PRID != findings[j].PRID {
+			return findings[i].PRID < findings[j].PRID
+		}
+		if findings[i].FilePath != findings[j].FilePath {
+			return findings[i].FilePath < findings[j].FilePath
+		}
+		return findings[i].Line < findings[j].Line
+	})
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+	for _, f := range findings {
+		row := []string{
+			f.PRID,
+			f.FilePath,
+			strconv.Itoa(f.Line),
+			strconv.FormatBool(f.IsFileLevel),
+			f.Category,
+			strconv.FormatBool(f.Matched),
+			f.Text,
+		}
+		if err := cw.Write(row); err != nil {
+			return fmt.Errorf("failed to write CSV row: %w", err)
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteSummary writes a short human-readable roll-up: one line per PR followed by the totals.
+func (r *Report) WriteSummary(w io.Writer) error {
+	matched := make(map[string]int)
+	for _, f := range r.Findings {
+		if f.Matched {
+			matched[f.PRID]++
+		}
+	}
+	for _, pr := range r.PRs {
+		name := "PR #" + pr.PRID
+		if pr.Title != "" {
+			name += " (" + pr.Title + ")"
+		}
+		var err error
+		switch {
+		case pr.Error != "":
+			_, err = fmt.Fprintf(w, "❌ %s: %s\n", name, pr.Error)
+		case pr.Skipped:
+			_, err = fmt.Fprintf(w, "⏭️  %s: skipped\n", name)
+		default:
+			_, err = fmt.Fprintf(w, "✅ %s: %d finding(s), %d on the diff\n", name, pr.Findings, matched[pr.PRID])
+		}
+		if err != nil {
+			return fmt.Errorf("failed to write summary: %w", err)
+		}
+	}
+	t := r.Totals()
+	skipped := ""
+	if t.PRsSkipped > 0 {
+		skipped = fmt.Sprintf(", %d skipped", t.PRsSkipped)
+	}
+	if _, err := fmt.Fprintf(w, "Reviewed %d PR(s)%s, %d failed; %d finding(s), %d on the diff\n",
+		t.PRsReviewed, skipped, t.PRsFailed, t.Findings, t.Matched); err != nil {
+		return fmt.Errorf("failed to write summary: %w", err)
+	}
+	return nil
+}
+
+// Write writes the report in the given format ("csv" or "json").
+func (r *Report) Write(w io.Writer, format string) error {
+	switch format {
+	case "csv":
+		return r.WriteCSV(w)
+	case "json", "":
+		return r.WriteJSON(w)
+	default:
+		return fmt.Errorf("unsupported report format %q (use csv or json)", format)
+	}
+}
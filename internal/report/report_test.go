@@ -0,0 +1,143 @@
+package report
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"pullreview/internal/review"
+)
+
+func sampleReport() *Report {
+	r := &Report{}
+	r.AddReview("12", "Add login", "Login summary",
+		[]review.Comment{
+			{FilePath: "auth.go", Line: 10, Text: "Timing attack", Category: "security"},
+			{FilePath: "auth.go", Text: "No tests", IsFileLevel: true},
+		},
+		[]review.Comment{
+			{FilePath: "gone.go", Line: 3, Text: "Outside diff", Category: "bug"},
+		},
+	)
+	r.AddReview("7", "Refactor", "", []review.Comment{
+		{FilePath: "db.go", Line: 5, Text: "N+1 query", Category: "perf"},
+	}, nil)
+	r.AddFailure("99", errors.New("diff not found"))
+	return r
+}
+
+func TestReport_Totals(t *testing.T) {
+	totals := sampleReport().Totals()
+	if totals.PRsReviewed != 2 {
+		t.Errorf("expected 2 reviewed PRs, got %d", totals.PRsReviewed)
+	}
+	if totals.PRsFailed != 1 {
+		t.Errorf("expected 1 failed PR, got %d", totals.PRsFailed)
+	}
+	if totals.Findings != 4 {
+		t.Errorf("expected 4 findings, got %d", totals.Findings)
+	}
+	if totals.Matched != 3 {
+		t.Errorf("expected 3 matched findings, got %d", totals.Matched)
+	}
+	want := map[string]int{"security": 1, "bug": 1, "perf": 1, "uncategorized": 1}
+	for cat, n := range want {
+		if totals.ByCategory[cat] != n {
+			t.Errorf("expected %d %s findings, got %d", n, cat, totals.ByCategory[cat])
+		}
+	}
+}
+
+func TestReport_WriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := sampleReport().WriteJSON(&buf); err != nil {
+		t.Fatalf("WriteJSON failed: %v", err)
+	}
+	var decoded struct {
+		Totals   Totals     `json:"totals"`
+		PRs      []PRResult `json:"prs"`
+		Findings []Finding  `json:"findings"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("invalid JSON output: %v", err)
+	}
+	if len(decoded.PRs) != 3 || len(decoded.Findings) != 4 {
+		t.Fatalf("expected 3 PRs and 4 findings, got %d and %d", len(decoded.PRs), len(decoded.Findings))
+	}
+	if decoded.PRs[2].Error != "diff not found" {
+		t.Errorf("expected failure to be recorded, got %+v", decoded.PRs[2])
+	}
+	if decoded.Totals.Findings != 4 {
+		t.Errorf("expected totals in JSON, got %+v", decoded.Totals)
+	}
+}
+
+func TestReport_WriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := sampleReport().WriteCSV(&buf); err != nil {
+		t.Fatalf("WriteCSV failed: %v", err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("invalid CSV output: %v", err)
+	}
+	if len(rows) != 5 {
+		t.Fatalf("expected header + 4 rows, got %d", len(rows))
+	}
+	if rows[0][0] != "pr_id" || rows[0][6] != "text" {
+		t.Errorf("unexpected header: %v", rows[0])
+	}
+	// Rows are sorted by PR ID, then file, then line
+	if rows[1][0] != "12" || rows[1][1] != "auth.go" || rows[1][2] != "0" {
+		t.Errorf("unexpected first row: %v", rows[1])
+	}
+	if rows[3][1] != "gone.go" || rows[3][5] != "false" {
+		t.Errorf("expected unmatched finding row, got %v", rows[3])
+	}
+	if rows[4][0] != "7" || rows[4][4] != "perf" {
+		t.Errorf("unexpected last row: %v", rows[4])
+	}
+}
+
+func TestReport_WriteUnsupportedFormat(t *testing.T) {
+	var buf bytes.Buffer
+	if err := sampleReport().Write(&buf, "xml"); err == nil {
+		t.Error("expected error for unsupported format")
+	}
+}
+
+func TestReport_WriteSummary(t *testing.T) {
+	var buf bytes.Buffer
+	if err := sampleReport().WriteSummary(&buf); err != nil {
+		t.Fatalf("WriteSummary failed: %v", err)
+	}
+	want := "✅ PR #12 (Add login): 3 finding(s), 2 on the diff\n" +
+		"✅ PR #7 (Refactor): 1 finding(s), 1 on the diff\n" +
+		"❌ PR #99: diff not found\n" +
+		"Reviewed 2 PR(s), 1 failed; 4 finding(s), 3 on the diff\n"
+	if buf.String() != want {
+		t.Errorf("unexpected summary:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
+
+func TestReport_WriteSummarySkipped(t *testing.T) {
+	r := sampleReport()
+	r.AddSkipped("40", "Vendor update")
+	if totals := r.Totals(); totals.PRsSkipped != 1 || totals.PRsReviewed != 2 || totals.PRsFailed != 1 {
+		t.Errorf("expected the skipped PR counted on its own, got %+v", totals)
+	}
+	var buf bytes.Buffer
+	if err := r.WriteSummary(&buf); err != nil {
+		t.Fatalf("WriteSummary failed: %v", err)
+	}
+	want := "✅ PR #12 (Add login): 3 finding(s), 2 on the diff\n" +
+		"✅ PR #7 (Refactor): 1 finding(s), 1 on the diff\n" +
+		"❌ PR #99: diff not found\n" +
+		"⏭️  PR #40 (Vendor update): skipped\n" +
+		"Reviewed 2 PR(s), 1 skipped, 1 failed; 4 finding(s), 3 on the diff\n"
+	if buf.String() != want {
+		t.Errorf("unexpected summary:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
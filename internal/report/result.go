@@ -0,0 +1,63 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"pullreview/internal/review"
+)
+
+// Posting records what a run posted to a PR.
+type Posting struct {
+	Posted         bool `json:"posted"`          // Whether the review was posted at all
+	InlineComments int  `json:"inline_comments"` // Inline and file-level comments posted or updated
+	SummaryPosted  bool `json:"summary_posted"`
+}
+
+// ReviewResult is the machine-readable outcome of reviewing one PR with the review command.
+type ReviewResult struct {
+	PRID      string        `json:"pr_id"`
+	Summary   string        `json:"summary,omitempty"` // The LLM summary, without the unmatched comments
+	Matched   []Finding     `json:"matched"`           // Comments placed on the diff
+	Unmatched []Finding     `json:"unmatched"`         // Comments folded into the summary
+	Posting   Posting       `json:"posting"`
+	Timings   []PhaseTiming `json:"timings,omitempty"` // Time spent in each phase of the review
+	Tokens    int           `json:"tokens,omitempty"`  // LLM tokens used, if the provider reports them
+	Skipped   bool          `json:"skipped,omitempty"` // The PR was not reviewed (e.g. already approved)
+	Error     string        `json:"error,omitempty"`
+}
+
+// NewReviewResult builds the result for a reviewed PR.
+func NewReviewResult(prID, summary string, matched, unmatched []review.Comment, posting Posting) ReviewResult {
+	res := ReviewResult{
+		PRID:      prID,
+		Summary:   summary,
+		Matched:   []Finding{},
+		Unmatched: []Finding{},
+		Posting:   posting,
+	}
+	for _, c := range matched {
+		res.Matched = append(res.Matched, newFinding(prID, c, true))
+	}
+	for _, c := range unmatched {
+		res.Unmatched = append(res.Unmatched, newFinding(prID, c, false))
+	}
+	return res
+}
+
+// WriteReviewResults writes the results as an indented JSON object with a "reviews" array.
+func WriteReviewResults(w io.Writer, results []ReviewResult) error {
+	out := struct {
+		Reviews []ReviewResult `json:"reviews"`
+	}{Reviews: results}
+	if out.Reviews == nil {
+		out.Reviews = []ReviewResult{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(out); err != nil {
+		return fmt.Errorf("failed to encode JSON review results: %w", err)
+	}
+	return nil
+}
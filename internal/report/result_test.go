@@ -0,0 +1,58 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"pullreview/internal/review"
+)
+
+func TestWriteReviewResults(t *testing.T) {
+	results := []ReviewResult{
+		NewReviewResult("12", "Looks good overall.",
+			[]review.Comment{
+				{FilePath: "auth.go", Line: 10, Text: "Timing attack", Category: "security", Severity: "high"},
+				{FilePath: "auth.go", Text: "No tests", IsFileLevel: true},
+			},
+			[]review.Comment{{FilePath: "gone.go", Line: 3, Text: "Outside diff"}},
+			Posting{Posted: true, InlineComments: 2, SummaryPosted: true},
+		),
+		{PRID: "13", Error: "diff not found"},
+	}
+	var buf bytes.Buffer
+	if err := WriteReviewResults(&buf, results); err != nil {
+		t.Fatalf("WriteReviewResults failed: %v", err)
+	}
+
+	var decoded struct {
+		Reviews []map[string]interface{} `json:"reviews"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("invalid JSON output: %v", err)
+	}
+	if len(decoded.Reviews) != 2 {
+		t.Fatalf("expected 2 reviews, got %d", len(decoded.Reviews))
+	}
+	first := decoded.Reviews[0]
+	for _, field := range []string{"pr_id", "summary", "matched", "unmatched", "posting"} {
+		if _, ok := first[field]; !ok {
+			t.Errorf("expected field %q in %v", field, first)
+		}
+	}
+	matched := first["matched"].([]interface{})
+	finding := matched[0].(map[string]interface{})
+	if finding["file"] != "auth.go" || finding["line"] != float64(10) || finding["severity"] != "high" {
+		t.Errorf("unexpected matched finding: %v", finding)
+	}
+	if len(first["unmatched"].([]interface{})) != 1 {
+		t.Errorf("expected 1 unmatched finding, got %v", first["unmatched"])
+	}
+	posting := first["posting"].(map[string]interface{})
+	if posting["posted"] != true || posting["inline_comments"] != float64(2) || posting["summary_posted"] != true {
+		t.Errorf("unexpected posting outcome: %v", posting)
+	}
+	if decoded.Reviews[1]["error"] != "diff not found" {
+		t.Errorf("expected failure to be recorded, got %v", decoded.Reviews[1])
+	}
+}
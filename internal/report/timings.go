@@ -0,0 +1,95 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Review phases timed by the review command.
+const (
+	PhaseFetchPR   = "fetch PR"
+	PhaseFetchDiff = "fetch diff"
+	PhaseLLM       = "LLM"
+	PhaseParse     = "parse"
+	PhasePost      = "post"
+)
+
+// PhaseTiming is the time spent in one phase of a review.
+type PhaseTiming struct {
+	Name       string        `json:"name"`
+	Duration   time.Duration `json:"-"`
+	DurationMS int64         `json:"duration_ms"`
+}
+
+// Timings records how long each phase of a review took, in the order the phases first ran.
+// Time added to a phase that already ran (e.g. one LLM call per diff chunk) accumulates. All
+// methods are safe to call on a nil *Timings, which records nothing.
+type Timings struct {
+	Phases []PhaseTiming
+
+	now func() time.Time // Overridden in tests
+}
+
+// Add adds d to the named phase.
+func (t *Timings) Add(name string, d time.Duration) {
+	if t == nil {
+		return
+	}
+	for i := range t.Phases {
+		if t.Phases[i].Name == name {
+			t.Phases[i].Duration += d
+			t.Phases[i].DurationMS = t.Phases[i].Duration.Milliseconds()
+			return
+		}
+	}
+	t.Phases = append(t.Phases, PhaseTiming{Name: name, Duration: d, DurationMS: d.Milliseconds()})
+}
+
+// Start starts timing the named phase and returns a function that stops it, for use with defer.
+func (t *Timings) Start(name string) func() {
+	if t == nil {
+		return func() {}
+	}
+	now := t.now
+	if now == nil {
+		now = time.Now
+	}
+	start := now()
+	return func() { t.Add(name, now().Sub(start)) }
+}
+
+// Total returns the time spent across all phases.
+func (t *Timings) Total() time.Duration {
+	if t == nil {
+		return 0
+	}
+	var total time.Duration
+	for _, p := range t.Phases {
+		total += p.Duration
+	}
+	return total
+}
+
+// WriteSummary writes a one-line summary of the phases, e.g.
+// "⏱️  fetch diff 0.2s, LLM 12.3s, post 1.1s (total 13.6s)". Nothing is written if no phase ran.
+func (t *Timings) WriteSummary(w io.Writer) error {
+	if t == nil || len(t.Phases) == 0 {
+		return nil
+	}
+	parts := make([]string, 0, len(t.Phases))
+	for _, p := range t.Phases {
+		parts = append(parts, fmt.Sprintf("%s %s", p.Name, formatDuration(p.Duration)))
+	}
+	_, err := fmt.Fprintf(w, "⏱️  %s (total %s)\n", strings.Join(parts, ", "), formatDuration(t.Total()))
+	return err
+}
+
+// formatDuration renders d in milliseconds below one second and in tenths of seconds above.
+func formatDuration(d time.Duration) string {
+	if d < time.Second {
+		return fmt.Sprintf("%dms", d.Milliseconds())
+	}
+	return fmt.Sprintf("%.1fs", d.Seconds())
+}
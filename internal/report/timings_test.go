@@ -0,0 +1,66 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestTimings_PhasesInOrder(t *testing.T) {
+	clock := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	tm := &Timings{now: func() time.Time { return clock }}
+	step := func(name string, d time.Duration) {
+		stop := tm.Start(name)
+		clock = clock.Add(d)
+		stop()
+	}
+	step(PhaseFetchPR, 300*time.Millisecond)
+	step(PhaseFetchDiff, 200*time.Millisecond)
+	step(PhaseLLM, 4*time.Second)
+	step(PhaseParse, 5*time.Millisecond)
+	step(PhaseLLM, 6*time.Second) // A second chunk
+	step(PhasePost, 1100*time.Millisecond)
+
+	want := []struct {
+		name string
+		d    time.Duration
+	}{
+		{PhaseFetchPR, 300 * time.Millisecond},
+		{PhaseFetchDiff, 200 * time.Millisecond},
+		{PhaseLLM, 10 * time.Second},
+		{PhaseParse, 5 * time.Millisecond},
+		{PhasePost, 1100 * time.Millisecond},
+	}
+	if len(tm.Phases) != len(want) {
+		t.Fatalf("expected %d phases, got %+v", len(want), tm.Phases)
+	}
+	for i, w := range want {
+		if tm.Phases[i].Name != w.name || tm.Phases[i].Duration != w.d || tm.Phases[i].DurationMS != w.d.Milliseconds() {
+			t.Errorf("phase %d: got %+v, want %s %s", i, tm.Phases[i], w.name, w.d)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := tm.WriteSummary(&buf); err != nil {
+		t.Fatalf("WriteSummary failed: %v", err)
+	}
+	if got := buf.String(); got != "⏱️  fetch PR 300ms, fetch diff 200ms, LLM 10.0s, parse 5ms, post 1.1s (total 11.6s)\n" {
+		t.Errorf("unexpected summary %q", got)
+	}
+
+	data, err := json.Marshal(tm.Phases[:1])
+	if err != nil || string(data) != `[{"name":"fetch PR","duration_ms":300}]` {
+		t.Errorf("unexpected JSON %s (%v)", data, err)
+	}
+}
+
+func TestTimings_Nil(t *testing.T) {
+	var tm *Timings
+	tm.Start(PhaseLLM)()
+	tm.Add(PhasePost, time.Second)
+	var buf bytes.Buffer
+	if err := tm.WriteSummary(&buf); err != nil || buf.Len() != 0 || tm.Total() != 0 {
+		t.Errorf("expected a nil Timings to record nothing, got %q", buf.String())
+	}
+}
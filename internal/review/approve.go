@@ -0,0 +1,63 @@
+package review
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+
+	"pullreview/internal/utils"
+)
+
+// Approve shows each file-level and inline comment of res on out and asks whether to post it,
+// then asks about the summary, reading the answers from in. It returns res reduced to the
+// approved comments, with an empty summary if the summary was declined. Unanswered questions
+// (empty input) default to no.
+func (res Result) Approve(in io.Reader, out io.Writer) (Result, error) {
+	reader := bufio.NewReader(in)
+	approved := Result{Unmatched: res.Unmatched}
+	total := len(res.FileLevel) + len(res.Inline)
+	n := 0
+	ask := func(cmt Comment) (bool, error) {
+		n++
+		fmt.Fprintf(out, "\n[%d/%d] %s", n, total, cmt.Location())
+		if cmt.Severity != "" {
+			fmt.Fprintf(out, " (%s)", cmt.Severity)
+		}
+		fmt.Fprintf(out, "\n%s\n", cmt.Text)
+		return utils.PromptYesNoFrom(reader, out, "Post this comment?", "n")
+	}
+	for _, cmt := range res.FileLevel {
+		ok, err := ask(cmt)
+		if err != nil {
+			return Result{}, err
+		}
+		if ok {
+			approved.FileLevel = append(approved.FileLevel, cmt)
+		}
+	}
+	for _, cmt := range res.Inline {
+		ok, err := ask(cmt)
+		if err != nil {
+			return Result{}, err
+		}
+		if ok {
+			approved.Inline = append(approved.Inline, cmt)
+		}
+	}
+	if res.Summary != "" {
+		fmt.Fprintf(out, "\n------ Summary ------\n%s\n", res.Summary)
+		ok, err := utils.PromptYesNoFrom(reader, out, "Post the summary comment?", "n")
+		if err != nil {
+			return Result{}, err
+		}
+		if ok {
+			approved.Summary = res.Summary
+		}
+	}
+	return approved, nil
+}
+
+// Comments returns the file-level and inline comments of res, file-level first.
+func (res Result) Comments() []Comment {
+	return append(append([]Comment{}, res.FileLevel...), res.Inline...)
+}
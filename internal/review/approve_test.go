@@ -0,0 +1,48 @@
+package review
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestResult_Approve(t *testing.T) {
+	res := Result{
+		FileLevel: []Comment{{FilePath: "go.mod", Text: "Pin the toolchain.", IsFileLevel: true}},
+		Inline: []Comment{
+			{FilePath: "main.go", Line: 3, Text: "Check the error.", Severity: "high"},
+			{FilePath: "main.go", Line: 9, Text: "Rename this."},
+		},
+		Unmatched: []Comment{{FilePath: "gone.go", Line: 1, Text: "Stale."}},
+		Summary:   "Looks fine.\n",
+	}
+
+	// Answers: file-level yes, first inline yes, second inline default (no), summary no
+	in := strings.NewReader("y\nyes\n\nn\n")
+	var out bytes.Buffer
+	got, err := res.Approve(in, &out)
+	if err != nil {
+		t.Fatalf("Approve failed: %v", err)
+	}
+	if len(got.FileLevel) != 1 || len(got.Inline) != 1 || got.Inline[0].Line != 3 {
+		t.Errorf("unexpected approved comments: %+v", got)
+	}
+	if got.Summary != "" {
+		t.Errorf("expected the declined summary to be dropped, got %q", got.Summary)
+	}
+	if len(got.Unmatched) != 1 {
+		t.Errorf("expected unmatched comments to be kept, got %+v", got.Unmatched)
+	}
+	for _, want := range []string{"[1/3] go.mod", "[2/3] main.go:3 (high)", "Check the error.", "[3/3] main.go:9", "Looks fine.", "Post the summary comment? [y/N]: "} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestResult_Approve_InputEnds(t *testing.T) {
+	res := Result{Inline: []Comment{{FilePath: "a.go", Line: 1, Text: "x"}, {FilePath: "a.go", Line: 2, Text: "y"}}}
+	if _, err := res.Approve(strings.NewReader("y\n"), &bytes.Buffer{}); err == nil {
+		t.Error("expected an error when input runs out")
+	}
+}
@@ -0,0 +1,100 @@
+package review
+
+import (
+	"sort"
+	"strings"
+)
+
+// Known finding categories, in the order they are grouped in the summary.
+const (
+	CategoryBug      = "bug"
+	CategorySecurity = "security"
+	CategoryPerf     = "perf"
+	CategoryStyle    = "style"
+)
+
+var categoryOrder = []string{CategoryBug, CategorySecurity, CategoryPerf, CategoryStyle}
+
+// categoryAliases maps common LLM spellings onto the known categories.
+var categoryAliases = map[string]string{
+	"bugs":            CategoryBug,
+	"defect":          CategoryBug,
+	"correctness":     CategoryBug,
+	"sec":             CategorySecurity,
+	"performance":     CategoryPerf,
+	"maintainability": CategoryStyle,
+	"readability":     CategoryStyle,
+}
+
+// NormalizeCategory lowercases and trims a category value and maps known aliases
+// (e.g. "Performance" -> "perf"). Unknown categories are kept as-is (lowercased).
+func NormalizeCategory(category string) string {
+	c := strings.ToLower(strings.TrimSpace(category))
+	if alias, ok := categoryAliases[c]; ok {
+		return alias
+	}
+	return c
+}
+
+// FilterByCategory returns only the comments whose category is in the given list.
+// An empty list disables filtering. Comments without a category are dropped when filtering.
+func FilterByCategory(comments []Comment, categories []string) []Comment {
+	if len(categories) == 0 {
+		return comments
+	}
+	allowed := make(map[string]bool)
+	for _, c := range categories {
+		if n := NormalizeCategory(c); n != "" {
+			allowed[n] = true
+		}
+	}
+	if len(allowed) == 0 {
+		return comments
+	}
+	var filtered []Comment
+	for _, c := range comments {
+		if allowed[c.Category] {
+			filtered = append(filtered, c)
+		}
+	}
+	return filtered
+}
+
+// CategoryGroup is a set of comments sharing the same category.
+type CategoryGroup struct {
+	Category string // Empty for uncategorized comments
+	Comments []Comment
+}
+
+// GroupByCategory groups comments by category. Known categories come first in a fixed
+// order, followed by other categories alphabetically, with uncategorized comments last.
+// The relative order of comments within a group is preserved.
+func GroupByCategory(comments []Comment) []CategoryGroup {
+	byCategory := make(map[string][]Comment)
+	for _, c := range comments {
+		byCategory[c.Category] = append(byCategory[c.Category], c)
+	}
+
+	var groups []CategoryGroup
+	seen := make(map[string]bool)
+	for _, cat := range categoryOrder {
+		if cs, ok := byCategory[cat]; ok {
+			groups = append(groups, CategoryGroup{Category: cat, Comments: cs})
+			seen[cat] = true
+		}
+	}
+	var others []string
+	for cat := range byCategory {
+		if cat != "" && !seen[cat] {
+			others = append(others, cat)
+		}
+	}
+	sort.Strings(others)
+	for _, cat := range others {
+		groups = append(groups, CategoryGroup{Category: cat, Comments: byCategory[cat]})
+	}
+	if cs, ok := byCategory[""]; ok {
+		groups = append(groups, CategoryGroup{Category: "", Comments: cs})
+	}
+	return groups
+}
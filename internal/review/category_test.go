@@ -0,0 +1,68 @@
+package review
+
+import "testing"
+
+func TestNormalizeCategory(t *testing.T) {
+	tests := map[string]string{
+		"Security":    "security",
+		" BUG ":       "bug",
+		"Performance": "perf",
+		"readability": "style",
+		"docs":        "docs",
+		"":            "",
+	}
+	for in, want := range tests {
+		if got := NormalizeCategory(in); got != want {
+			t.Errorf("NormalizeCategory(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestFilterByCategory(t *testing.T) {
+	comments := []Comment{
+		{FilePath: "a.go", Line: 1, Text: "sql injection", Category: "security"},
+		{FilePath: "a.go", Line: 2, Text: "nil deref", Category: "bug"},
+		{FilePath: "b.go", Line: 3, Text: "slow loop", Category: "perf"},
+		{FilePath: "b.go", Line: 4, Text: "uncategorized"},
+	}
+
+	if got := FilterByCategory(comments, nil); len(got) != len(comments) {
+		t.Errorf("expected no filtering with empty categories, got %d comments", len(got))
+	}
+
+	got := FilterByCategory(comments, []string{"security"})
+	if len(got) != 1 || got[0].Text != "sql injection" {
+		t.Errorf("expected only the security comment, got %+v", got)
+	}
+
+	got = FilterByCategory(comments, []string{"Security", "performance"})
+	if len(got) != 2 {
+		t.Fatalf("expected 2 comments, got %d", len(got))
+	}
+	if got[0].Category != "security" || got[1].Category != "perf" {
+		t.Errorf("unexpected filtered comments: %+v", got)
+	}
+}
+
+func TestGroupByCategory(t *testing.T) {
+	comments := []Comment{
+		{Text: "1", Category: "style"},
+		{Text: "2"},
+		{Text: "3", Category: "docs"},
+		{Text: "4", Category: "bug"},
+		{Text: "5", Category: "style"},
+	}
+	groups := GroupByCategory(comments)
+	wantOrder := []string{"bug", "style", "docs", ""}
+	if len(groups) != len(wantOrder) {
+		t.Fatalf("expected %d groups, got %d", len(wantOrder), len(groups))
+	}
+	for i, cat := range wantOrder {
+		if groups[i].Category != cat {
+			t.Errorf("group %d: expected category %q, got %q", i, cat, groups[i].Category)
+		}
+	}
+	if len(groups[1].Comments) != 2 || groups[1].Comments[0].Text != "1" || groups[1].Comments[1].Text != "5" {
+		t.Errorf("expected style group to preserve order, got %+v", groups[1].Comments)
+	}
+}
@@ -0,0 +1,133 @@
+package review
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SplitDiff groups the parsed diff files into chunks of at most maxBytes of unified diff text
+// each, so that a large PR can be reviewed in several LLM requests. Files are never split: a
+// single file larger than maxBytes gets a chunk of its own. Files keep their diff order.
+func SplitDiff(files []*DiffFile, maxBytes int) []string {
+	var chunks []string
+	var current strings.Builder
+	for _, f := range files {
+		text := formatUnifiedDiff(f)
+		if current.Len() > 0 && current.Len()+len(text) > maxBytes {
+			chunks = append(chunks, current.String())
+			current.Reset()
+		}
+		current.WriteString(text)
+	}
+	if current.Len() > 0 {
+		chunks = append(chunks, current.String())
+	}
+	return chunks
+}
+
+// formatUnifiedDiff renders a parsed diff file back into git-style unified diff text.
+func formatUnifiedDiff(f *DiffFile) string {
+	var sb strings.Builder
+	oldPath, newPath := f.OldPath, f.NewPath
+	if oldPath == "" {
+		oldPath = newPath
+	}
+	if newPath == "" {
+		newPath = oldPath
+	}
+	fmt.Fprintf(&sb, "diff --git a/%s b/%s\n", oldPath, newPath)
+	switch {
+	case f.IsNew:
+		sb.WriteString("new file mode 100644\n")
+	case f.IsDeleted:
+		sb.WriteString("deleted file mode 100644\n")
+	case f.IsRename:
+		fmt.Fprintf(&sb, "rename from %s\nrename to %s\n", f.OldPath, f.NewPath)
+	case f.IsCopy:
+		fmt.Fprintf(&sb, "copy from %s\ncopy to %s\n", f.OldPath, f.NewPath)
+	}
+	if len(f.Hunks) == 0 {
+		return sb.String()
+	}
+	from, to := "a/"+f.OldPath, "b/"+f.NewPath
+	if f.IsNew {
+		from = "/dev/null"
+	}
+	if f.IsDeleted {
+		to = "/dev/null"
+	}
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", from, to)
+	for _, h := range f.Hunks {
+		sb.WriteString(h.Header)
+		sb.WriteString("\n")
+		lines := h.Lines
+		// Drop the trailing empty lines left behind by the diff's final newline
+		for len(lines) > 0 && lines[len(lines)-1] == "" {
+			lines = lines[:len(lines)-1]
+		}
+		for _, line := range lines {
+			sb.WriteString(line)
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String()
+}
+
+// ReviewInChunks reviews the diff with one or more calls to send, which receives a diff and
+// returns the raw LLM response. If maxBytes is positive, the diff is larger than maxBytes, and
+// the diff has been parsed (ParseDiff), it is split with SplitDiff and the comments and
+// summaries of every chunk are merged; otherwise the whole diff is sent in a single call.
+// r.Comments and r.Summary hold the result.
+func (r *Review) ReviewInChunks(maxBytes int, send func(diff string) (string, error)) error {
+	if maxBytes <= 0 || len(r.Diff) <= maxBytes || len(r.Files) == 0 {
+		resp, err := send(r.Diff)
+		if err != nil {
+			return err
+		}
+		r.ParseLLMResponse(resp)
+		return nil
+	}
+
+	chunks := SplitDiff(r.Files, maxBytes)
+	commentSets := make([][]Comment, 0, len(chunks))
+	summaries := make([]string, 0, len(chunks))
+	for i, chunk := range chunks {
+		resp, err := send(chunk)
+		if err != nil {
+			return fmt.Errorf("chunk %d of %d: %w", i+1, len(chunks), err)
+		}
+		comments, summary := ParseLLMResponse(resp)
+		commentSets = append(commentSets, comments)
+		summaries = append(summaries, summary)
+	}
+	r.Comments, r.Summary = MergeChunkResults(commentSets, summaries)
+	return nil
+}
+
+// MergeChunkResults combines the comments and summaries produced for each chunk of a diff.
+// Comments are concatenated in chunk order, dropping file-level comments that repeat an earlier
+// one for the same file (ignoring whitespace differences). Non-empty summaries are joined by
+// blank lines.
+func MergeChunkResults(commentSets [][]Comment, summaries []string) ([]Comment, string) {
+	var merged []Comment
+	seen := make(map[string]bool)
+	for _, comments := range commentSets {
+		for _, c := range comments {
+			if c.IsFileLevel {
+				key := c.FilePath + "\x00" + normalizeCommentText(c.Text)
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+			}
+			merged = append(merged, c)
+		}
+	}
+	var parts []string
+	for _, s := range summaries {
+		if s = strings.TrimSpace(s); s != "" {
+			parts = append(parts, s)
+		}
+	}
+	return merged, strings.Join(parts, "\n\n")
+}
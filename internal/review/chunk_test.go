@@ -0,0 +1,230 @@
+package review
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// multiFileDiff builds a diff touching n files, each with a single one-line change.
+func multiFileDiff(n int) string {
+	var sb strings.Builder
+	for i := 1; i <= n; i++ {
+		fmt.Fprintf(&sb, "diff --git a/pkg/file%d.go b/pkg/file%d.go\n", i, i)
+		fmt.Fprintf(&sb, "index 1111111..2222222 100644\n--- a/pkg/file%d.go\n+++ b/pkg/file%d.go\n", i, i)
+		fmt.Fprintf(&sb, "@@ -1,2 +1,2 @@\n package pkg\n-var v%d = 1\n+var v%d = 2\n", i, i)
+	}
+	return sb.String()
+}
+
+// fakeLLM answers with one inline comment, the same file-level comment, and a summary for
+// every file present in the diff it receives.
+func fakeLLM(calls *[]string) func(string) (string, error) {
+	return func(diff string) (string, error) {
+		*calls = append(*calls, diff)
+		files, err := ParseUnifiedDiff(diff)
+		if err != nil {
+			return "", err
+		}
+		var fileLevel, inline, summary strings.Builder
+		for _, f := range files {
+			fmt.Fprintf(&fileLevel, "FILE: pkg/file1.go\nCOMMENT: Package lacks tests.\n\n")
+			fmt.Fprintf(&inline, "FILE: %s\nLINE: 2\nCOMMENT: Changed value in %s.\n\n", f.NewPath, f.NewPath)
+			fmt.Fprintf(&summary, "Reviewed %s. ", f.NewPath)
+		}
+		return "******************** SECTION: FILE-LEVEL COMMENTS ********************\n" + fileLevel.String() +
+			"******************** SECTION: INLINE COMMENTS ********************\n" + inline.String() +
+			"******************** SECTION: SUMMARY ********************\n" + summary.String() + "\n" +
+			"******************** END ********************\n", nil
+	}
+}
+
+func TestSplitDiff(t *testing.T) {
+	files, err := ParseUnifiedDiff(multiFileDiff(5))
+	if err != nil {
+		t.Fatalf("ParseUnifiedDiff failed: %v", err)
+	}
+	single := len(formatUnifiedDiff(files[0]))
+	chunks := SplitDiff(files, 2*single)
+	if len(chunks) != 3 {
+		t.Fatalf("expected 3 chunks of at most two files, got %d", len(chunks))
+	}
+	var rejoined []*DiffFile
+	for i, chunk := range chunks {
+		if len(chunk) > 2*single {
+			t.Errorf("chunk %d is %d bytes, over the %d limit", i, len(chunk), 2*single)
+		}
+		parsed, err := ParseUnifiedDiff(chunk)
+		if err != nil {
+			t.Fatalf("chunk %d does not parse: %v", i, err)
+		}
+		rejoined = append(rejoined, parsed...)
+	}
+	if len(rejoined) != len(files) {
+		t.Fatalf("expected %d files across chunks, got %d", len(files), len(rejoined))
+	}
+	for i, f := range rejoined {
+		got := strings.TrimRight(strings.Join(f.Hunks[0].Lines, "\n"), "\n")
+		want := strings.TrimRight(strings.Join(files[i].Hunks[0].Lines, "\n"), "\n")
+		if f.NewPath != files[i].NewPath || got != want {
+			t.Errorf("file %d did not round-trip: got %s %q, want %s %q", i, f.NewPath, got, files[i].NewPath, want)
+		}
+	}
+
+	// A file larger than the limit still gets a chunk of its own
+	if chunks := SplitDiff(files[:2], 10); len(chunks) != 2 {
+		t.Errorf("expected oversized files to get one chunk each, got %d chunks", len(chunks))
+	}
+}
+
+func TestReviewInChunks_CommentsFromEveryFile(t *testing.T) {
+	diff := multiFileDiff(4)
+	r := NewReview("1", diff)
+	if err := r.ParseDiff(); err != nil {
+		t.Fatalf("ParseDiff failed: %v", err)
+	}
+	var calls []string
+	if err := r.ReviewInChunks(len(diff)/3, fakeLLM(&calls)); err != nil {
+		t.Fatalf("ReviewInChunks failed: %v", err)
+	}
+	if len(calls) < 2 {
+		t.Fatalf("expected the diff to be split into several requests, got %d", len(calls))
+	}
+
+	inlineByFile := make(map[string]int)
+	fileLevel := 0
+	for _, c := range r.Comments {
+		if c.IsFileLevel {
+			fileLevel++
+		} else {
+			inlineByFile[c.FilePath]++
+		}
+	}
+	for i := 1; i <= 4; i++ {
+		path := fmt.Sprintf("pkg/file%d.go", i)
+		if inlineByFile[path] != 1 {
+			t.Errorf("expected one inline comment for %s, got %d", path, inlineByFile[path])
+		}
+		if !strings.Contains(r.Summary, "Reviewed "+path) {
+			t.Errorf("summary is missing the review of %s: %q", path, r.Summary)
+		}
+	}
+	if fileLevel != 1 {
+		t.Errorf("expected duplicate file-level comments to be merged into one, got %d", fileLevel)
+	}
+	matched, unmatched := MatchCommentsToDiff(r.Comments, r.Files)
+	if len(unmatched) != 0 || len(matched) != 5 {
+		t.Errorf("expected all 5 comments to match the diff, got %d matched and %d unmatched", len(matched), len(unmatched))
+	}
+}
+
+func TestReviewInChunks_SmallDiffSingleRequest(t *testing.T) {
+	diff := multiFileDiff(3)
+	r := NewReview("1", diff)
+	if err := r.ParseDiff(); err != nil {
+		t.Fatalf("ParseDiff failed: %v", err)
+	}
+	var calls []string
+	if err := r.ReviewInChunks(len(diff), fakeLLM(&calls)); err != nil {
+		t.Fatalf("ReviewInChunks failed: %v", err)
+	}
+	if len(calls) != 1 || calls[0] != diff {
+		t.Errorf("expected the raw diff to be sent once, got %d call(s)", len(calls))
+	}
+	// The repeated file-level comment collapses when the single response is parsed
+	if len(r.Comments) != 4 {
+		t.Errorf("expected 4 comments from the single response, got %d", len(r.Comments))
+	}
+}
+
+func TestReviewInChunks_PropagatesChunkError(t *testing.T) {
+	diff := multiFileDiff(3)
+	r := NewReview("1", diff)
+	if err := r.ParseDiff(); err != nil {
+		t.Fatalf("ParseDiff failed: %v", err)
+	}
+	boom := errors.New("context length exceeded")
+	calls := 0
+	err := r.ReviewInChunks(1, func(string) (string, error) {
+		calls++
+		if calls == 2 {
+			return "", boom
+		}
+		return "", nil
+	})
+	if !errors.Is(err, boom) || !strings.Contains(err.Error(), "chunk 2 of 3") {
+		t.Errorf("expected chunk error to be wrapped, got: %v", err)
+	}
+}
+
+func TestMergeChunkResults(t *testing.T) {
+	comments, summary := MergeChunkResults([][]Comment{
+		{
+			{FilePath: "a.go", Text: "Missing error handling.", IsFileLevel: true},
+			{FilePath: "a.go", Line: 3, Text: "Off by one."},
+		},
+		{
+			{FilePath: "a.go", Text: "Missing  error\nhandling.", IsFileLevel: true},
+			{FilePath: "b.go", Text: "Missing error handling.", IsFileLevel: true},
+			{FilePath: "a.go", Line: 3, Text: "Off by one."},
+		},
+	}, []string{"First part.", "  ", "Second part."})
+
+	if len(comments) != 4 {
+		t.Fatalf("expected 4 comments after de-duplication, got %d: %+v", len(comments), comments)
+	}
+	if comments[2].FilePath != "b.go" {
+		t.Errorf("expected file-level comments on other files to be kept, got %+v", comments[2])
+	}
+	if summary != "First part.\n\nSecond part." {
+		t.Errorf("unexpected merged summary %q", summary)
+	}
+}
+
+func TestSplitDiff_KeepsRenames(t *testing.T) {
+	files := []*DiffFile{{OldPath: "old.go", NewPath: "new.go", IsRename: true}}
+	chunks := SplitDiff(files, 1000)
+	if len(chunks) != 1 {
+		t.Fatalf("expected 1 chunk, got %d", len(chunks))
+	}
+	parsed, err := ParseUnifiedDiff(chunks[0])
+	if err != nil {
+		t.Fatalf("chunk does not parse: %v", err)
+	}
+	if len(parsed) != 1 || !parsed[0].IsRename || parsed[0].OldPath != "old.go" || parsed[0].NewPath != "new.go" {
+		t.Errorf("rename did not round-trip: %+v", parsed)
+	}
+}
+
+func TestSplitDiff_AddedAndDeletedFilesRoundTrip(t *testing.T) {
+	diff := `diff --git a/added.go b/added.go
+new file mode 100644
+--- /dev/null
++++ b/added.go
+@@ -0,0 +1 @@
++package added
+diff --git a/removed.go b/removed.go
+deleted file mode 100644
+--- a/removed.go
++++ /dev/null
+@@ -1 +0,0 @@
+-package removed
+`
+	files, err := ParseUnifiedDiff(diff)
+	if err != nil {
+		t.Fatalf("ParseUnifiedDiff failed: %v", err)
+	}
+	chunks := SplitDiff(files, 1)
+	if len(chunks) != 2 {
+		t.Fatalf("expected 2 chunks, got %d", len(chunks))
+	}
+	added, _ := ParseUnifiedDiff(chunks[0])
+	removed, _ := ParseUnifiedDiff(chunks[1])
+	if len(added) != 1 || !added[0].IsNew || added[0].OldPath != "" || added[0].NewPath != "added.go" {
+		t.Errorf("added file did not round-trip: %+v", added)
+	}
+	if len(removed) != 1 || !removed[0].IsDeleted || removed[0].NewPath != "" || removed[0].OldPath != "removed.go" {
+		t.Errorf("deleted file did not round-trip: %+v", removed)
+	}
+}
@@ -0,0 +1,60 @@
+package review
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DedupeComments collapses repeated comments. Comments on the same file and line with the same
+// text (ignoring whitespace differences) are kept once, and an inline comment repeated on a run
+// of consecutive lines is kept once, anchored at the first line of the run. The first occurrence
+// of each kept comment keeps its position.
+func DedupeComments(comments []Comment) []Comment {
+	// Lines each inline text appears on, to find the start of consecutive runs
+	lines := make(map[string]map[int]bool)
+	for _, c := range comments {
+		if key, line, ok := rangeKey(c); ok {
+			if lines[key] == nil {
+				lines[key] = make(map[int]bool)
+			}
+			lines[key][line] = true
+		}
+	}
+
+	var deduped []Comment
+	seen := make(map[string]bool)
+	for _, c := range comments {
+		if key, line, ok := rangeKey(c); ok && lines[key][line-1] {
+			// Continuation of a range anchored at an earlier line
+			continue
+		}
+		key := fmt.Sprintf("%s\x00%t\x00%d\x00%d\x00%s", c.FilePath, c.IsFileLevel, c.Line, c.OldLine, normalizeCommentText(c.Text))
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		deduped = append(deduped, c)
+	}
+	return deduped
+}
+
+// rangeKey identifies an inline comment's file, side and text for range merging, and returns
+// the line it is anchored to on that side.
+func rangeKey(c Comment) (string, int, bool) {
+	text := normalizeCommentText(c.Text)
+	switch {
+	case c.IsFileLevel:
+		return "", 0, false
+	case c.Line > 0:
+		return c.FilePath + "\x00new\x00" + text, c.Line, true
+	case c.OldLine > 0:
+		return c.FilePath + "\x00old\x00" + text, c.OldLine, true
+	}
+	return "", 0, false
+}
+
+// normalizeCommentText collapses runs of whitespace so formatting differences do not hide
+// duplicates.
+func normalizeCommentText(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
@@ -0,0 +1,61 @@
+package review
+
+import "testing"
+
+func TestDedupeComments(t *testing.T) {
+	comments := []Comment{
+		{FilePath: "a.go", Line: 10, Text: "Check the error."},
+		{FilePath: "a.go", Line: 11, Text: "Check  the\nerror."},
+		{FilePath: "a.go", Line: 12, Text: "Check the error."},
+		{FilePath: "a.go", Line: 20, Text: "Check the error."},
+		{FilePath: "a.go", Line: 10, Text: "Different issue."},
+		{FilePath: "a.go", Line: 10, Text: "Different issue. "},
+		{FilePath: "b.go", Line: 11, Text: "Check the error."},
+		{FilePath: "a.go", Text: "File issue.", IsFileLevel: true},
+		{FilePath: "a.go", Text: "File  issue.", IsFileLevel: true},
+		{FilePath: "a.go", OldLine: 3, Text: "Removed guard."},
+		{FilePath: "a.go", OldLine: 4, Text: "Removed guard."},
+	}
+	got := DedupeComments(comments)
+	want := []string{
+		"a.go:10 Check the error.",
+		"a.go:20 Check the error.",
+		"a.go:10 Different issue.",
+		"b.go:11 Check the error.",
+		"a.go File issue.",
+		"a.go:-3 (deleted) Removed guard.",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d comments, got %d: %+v", len(want), len(got), got)
+	}
+	for i, c := range got {
+		if s := c.Location() + " " + c.Text; s != want[i] {
+			t.Errorf("comment %d = %q, want %q", i, s, want[i])
+		}
+	}
+}
+
+func TestParseLLMResponse_DedupesComments(t *testing.T) {
+	raw := `******************** SECTION: INLINE COMMENTS ********************
+
+FILE: auth.go
+LINE: 12
+COMMENT: Token is compared with ==.
+
+FILE: auth.go
+LINE: 13
+COMMENT: Token is compared with ==.
+
+FILE: auth.go
+LINE: 12
+COMMENT: Token is compared with ==.
+
+******************** SECTION: SUMMARY ********************
+
+Summary text.
+`
+	comments, _ := ParseLLMResponse(raw)
+	if len(comments) != 1 || comments[0].Line != 12 {
+		t.Errorf("expected a single comment on line 12, got %+v", comments)
+	}
+}
@@ -0,0 +1,136 @@
+package review
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+)
+
+// IgnoreFileName is the file, at the repository root, listing paths the reviewer never
+// comments on.
+const IgnoreFileName = ".pullreviewignore"
+
+// IgnoreRules are the patterns of a .pullreviewignore file. The syntax follows .gitignore:
+// one glob per line, blank lines and "#" comments are skipped, a leading "!" re-includes a
+// path excluded by an earlier pattern, a leading "/" anchors the pattern to the repository
+// root, a trailing "/" matches only directories, and "**" matches any number of directories.
+// A pattern matching a directory ignores everything below it. The last matching pattern wins.
+type IgnoreRules struct {
+	rules []ignoreRule
+}
+
+type ignoreRule struct {
+	pattern string
+	negate  bool
+	dirOnly bool
+	// rooted is set for "/name": a single-segment pattern that only matches at the root.
+	// Patterns containing a "/" are always matched against the full path.
+	rooted bool
+}
+
+// ParseIgnore reads .pullreviewignore patterns from r.
+func ParseIgnore(r io.Reader) (*IgnoreRules, error) {
+	ig := &IgnoreRules{}
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		var rule ignoreRule
+		switch {
+		case strings.HasPrefix(line, "!"):
+			rule.negate = true
+			line = line[1:]
+		case strings.HasPrefix(line, `\!`), strings.HasPrefix(line, `\#`):
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			rule.dirOnly = true
+			line = strings.TrimSuffix(line, "/")
+		}
+		if strings.HasPrefix(line, "/") {
+			line = line[1:]
+			rule.rooted = !strings.Contains(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+		if _, err := globRegexp(line); err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		rule.pattern = line
+		ig.rules = append(ig.rules, rule)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return ig, nil
+}
+
+// LoadIgnoreFile reads the ignore file at path. A missing file is not an error and yields nil
+// rules, which ignore nothing.
+func LoadIgnoreFile(path string) (*IgnoreRules, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	defer f.Close()
+	ig, err := ParseIgnore(f)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", path, err)
+	}
+	return ig, nil
+}
+
+// Len returns the number of patterns.
+func (ig *IgnoreRules) Len() int {
+	if ig == nil {
+		return 0
+	}
+	return len(ig.rules)
+}
+
+// Ignored reports whether p is ignored. Nil rules ignore nothing.
+func (ig *IgnoreRules) Ignored(p string) bool {
+	if ig == nil {
+		return false
+	}
+	p = strings.TrimPrefix(p, "/")
+	ignored := false
+	for _, rule := range ig.rules {
+		if rule.match(p) {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
+
+// match reports whether the rule matches p or one of its parent directories.
+func (r ignoreRule) match(p string) bool {
+	for i := 0; i <= len(p); i++ {
+		if i < len(p) && p[i] != '/' {
+			continue
+		}
+		// p[:i] is a directory for every prefix but the full path
+		isDir := i < len(p)
+		if (isDir || !r.dirOnly) && r.matchPath(p[:i]) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r ignoreRule) matchPath(p string) bool {
+	if r.rooted && strings.Contains(p, "/") {
+		return false
+	}
+	return matchGlob(r.pattern, p)
+}
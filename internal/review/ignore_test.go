@@ -0,0 +1,147 @@
+package review
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestIgnoreRules_Ignored(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules string
+		want  map[string]bool
+	}{
+		{
+			name: "base name and double star",
+			rules: `# generated code
+*.pb.go
+**/__snapshots__/**
+db/migrations/**
+`,
+			want: map[string]bool{
+				"api/v1/service.pb.go":            true,
+				"service.pb.go":                   true,
+				"web/src/__snapshots__/app.snap":  true,
+				"__snapshots__/x.snap":            true,
+				"db/migrations/0001_init.sql":     true,
+				"internal/db/migrations/0001.sql": false,
+				"api/v1/service.go":               false,
+				"web/src/app.test.js":             false,
+			},
+		},
+		{
+			name:  "directory pattern ignores everything below it",
+			rules: "generated/\n",
+			want: map[string]bool{
+				"generated/a.go":          true,
+				"internal/generated/b.go": true,
+				"generated":               false,
+				"internal/generated.go":   false,
+			},
+		},
+		{
+			name:  "rooted pattern",
+			rules: "/vendor\n",
+			want: map[string]bool{
+				"vendor/x/y.go":        true,
+				"internal/vendor/y.go": false,
+				"internal/vendor.go":   false,
+			},
+		},
+		{
+			name:  "negation re-includes",
+			rules: "db/migrations/**\n!db/migrations/README.md\n",
+			want: map[string]bool{
+				"db/migrations/0001.sql":  true,
+				"db/migrations/README.md": false,
+			},
+		},
+		{
+			name:  "last matching pattern wins",
+			rules: "!keep.pb.go\n*.pb.go\n",
+			want: map[string]bool{
+				"api/keep.pb.go": true,
+				"api/x.pb.go":    true,
+			},
+		},
+		{
+			name:  "negation then re-ignore",
+			rules: "*.pb.go\n!api/**\napi/internal/**\n",
+			want: map[string]bool{
+				"gen/x.pb.go":          true,
+				"api/x.pb.go":          false,
+				"api/internal/x.pb.go": true,
+			},
+		},
+		{
+			name:  "escaped leading characters",
+			rules: "\\#notes.md\n\\!bang.txt\n",
+			want: map[string]bool{
+				"#notes.md": true,
+				"!bang.txt": true,
+				"notes.md":  false,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ig, err := ParseIgnore(strings.NewReader(tt.rules))
+			if err != nil {
+				t.Fatalf("ParseIgnore() error = %v", err)
+			}
+			for p, want := range tt.want {
+				if got := ig.Ignored(p); got != want {
+					t.Errorf("Ignored(%q) = %v, want %v", p, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseIgnore_Invalid(t *testing.T) {
+	_, err := ParseIgnore(strings.NewReader("*.go\n[abc\n"))
+	if err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Fatalf("ParseIgnore() error = %v, want error naming line 2", err)
+	}
+}
+
+func TestLoadIgnoreFile(t *testing.T) {
+	dir := t.TempDir()
+	ig, err := LoadIgnoreFile(filepath.Join(dir, IgnoreFileName))
+	if err != nil || ig != nil {
+		t.Fatalf("missing file: got %v, %v; want nil, nil", ig, err)
+	}
+	if ig.Ignored("a.go") {
+		t.Error("nil rules should ignore nothing")
+	}
+
+	path := filepath.Join(dir, IgnoreFileName)
+	if err := os.WriteFile(path, []byte("*.snap\r\n\r\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ig, err = LoadIgnoreFile(path)
+	if err != nil {
+		t.Fatalf("LoadIgnoreFile() error = %v", err)
+	}
+	if ig.Len() != 1 || !ig.Ignored("ui/app.snap") {
+		t.Errorf("got %d rules, Ignored(ui/app.snap) = %v", ig.Len(), ig.Ignored("ui/app.snap"))
+	}
+}
+
+func TestPathFilter_Ignore(t *testing.T) {
+	ig, err := ParseIgnore(strings.NewReader("gen/**\n!gen/keep.go\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pf := PathFilter{Ignore: ig}
+	if pf.IsZero() {
+		t.Fatal("IsZero() = true with ignore rules")
+	}
+	comments := []Comment{{FilePath: "gen/a.go"}, {FilePath: "gen/keep.go"}, {FilePath: "main.go"}}
+	got := FilterCommentsByPath(comments, pf)
+	if len(got) != 2 || got[0].FilePath != "gen/keep.go" || got[1].FilePath != "main.go" {
+		t.Errorf("FilterCommentsByPath() = %+v", got)
+	}
+}
@@ -0,0 +1,104 @@
+package review
+
+import "strings"
+
+// IncrementalFiles narrows a PR's parsed diff to the hunks touched by a later diff, e.g. the
+// changes pushed since the last review. Both diffs must end at the same commit (the PR's
+// head), so their new-file line numbers agree. A PR hunk is kept if its new-file range
+// overlaps a hunk of the incremental diff in the same file. Files left without hunks are
+// dropped, unless neither diff has hunks for them (e.g. a pure rename).
+func IncrementalFiles(prFiles, incremental []*DiffFile) []*DiffFile {
+	touched := make(map[string][]*DiffHunk)
+	present := make(map[string]bool)
+	for _, f := range incremental {
+		present[f.Path()] = true
+		touched[f.Path()] = append(touched[f.Path()], f.Hunks...)
+	}
+
+	var kept []*DiffFile
+	for _, f := range prFiles {
+		if !present[f.Path()] {
+			continue
+		}
+		if len(f.Hunks) == 0 {
+			if len(touched[f.Path()]) == 0 {
+				kept = append(kept, f)
+			}
+			continue
+		}
+		var hunks []*DiffHunk
+		for _, h := range f.Hunks {
+			for _, ih := range touched[f.Path()] {
+				if hunksOverlap(h, ih) {
+					hunks = append(hunks, h)
+					break
+				}
+			}
+		}
+		if len(hunks) > 0 {
+			narrowed := *f
+			narrowed.Hunks = hunks
+			kept = append(kept, &narrowed)
+		}
+	}
+	return kept
+}
+
+// hunksOverlap reports whether two hunks cover any common new-file line. A hunk that only
+// deletes lines covers the line it deletes before.
+func hunksOverlap(a, b *DiffHunk) bool {
+	aStart, aEnd := newRange(a)
+	bStart, bEnd := newRange(b)
+	return aStart <= bEnd && bStart <= aEnd
+}
+
+// newRange returns the first and last new-file lines of a hunk.
+func newRange(h *DiffHunk) (start, end int) {
+	if h.NewLines == 0 {
+		return h.NewStart, h.NewStart
+	}
+	return h.NewStart, h.NewStart + h.NewLines - 1
+}
+
+// IncrementalDiff returns the hunks of prDiff touched by incrementalDiff (see
+// IncrementalFiles) as unified diff text, or "" if none are.
+func IncrementalDiff(prDiff, incrementalDiff string) (string, error) {
+	prFiles, err := ParseUnifiedDiff(prDiff)
+	if err != nil {
+		return "", err
+	}
+	incFiles, err := ParseUnifiedDiff(incrementalDiff)
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	for _, f := range IncrementalFiles(prFiles, incFiles) {
+		sb.WriteString(formatUnifiedDiff(f))
+	}
+	return sb.String(), nil
+}
+
+// LastReviewedCommit returns the commit of the last review: the one recorded by the summary
+// comment, which every posting run updates, or else (for reviews posted before summaries were
+// marked) the one recorded by the most recently posted comment that has one. It returns "" if
+// there is none. posted must be in posting order, as ListComments returns them.
+func LastReviewedCommit(posted []PostedComment) string {
+	if s := FindSummary(posted); s != nil && s.Marker.Commit != "" {
+		return s.Marker.Commit
+	}
+	for i := len(posted) - 1; i >= 0; i-- {
+		if c := posted[i].Marker.Commit; c != "" {
+			return c
+		}
+	}
+	return ""
+}
+
+// SameCommit reports whether two commit hashes refer to the same commit, allowing either to
+// be abbreviated (Bitbucket Cloud reports 12-character hashes).
+func SameCommit(a, b string) bool {
+	if a == "" || b == "" {
+		return false
+	}
+	return strings.HasPrefix(a, b) || strings.HasPrefix(b, a)
+}
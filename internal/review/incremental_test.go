@@ -0,0 +1,134 @@
+package review
+
+import (
+	"strings"
+	"testing"
+)
+
+// prDiff changes two hunks in a.go and one in b.go, and renames c.go to d.go.
+const incrementalPRDiff = `diff --git a/a.go b/a.go
+--- a/a.go
++++ b/a.go
+@@ -1,3 +1,4 @@
+ package a
++// first change
+ func A() {}
+ func B() {}
+@@ -20,3 +21,3 @@
+ func X() {}
+-func Y() {}
++func Y() int { return 1 }
+ func Z() {}
+diff --git a/b.go b/b.go
+--- a/b.go
++++ b/b.go
+@@ -5,2 +5,3 @@
+ var v = 1
++var w = 2
+ var x = 3
+diff --git a/c.go b/d.go
+similarity index 100%
+rename from c.go
+rename to d.go
+`
+
+func TestIncrementalFiles(t *testing.T) {
+	// Since the last review only Y's body changed again
+	incremental := `diff --git a/a.go b/a.go
+--- a/a.go
++++ b/a.go
+@@ -21,3 +21,3 @@
+ func X() {}
+-func Y() int { return 0 }
++func Y() int { return 1 }
+ func Z() {}
+`
+	prFiles, err := ParseUnifiedDiff(incrementalPRDiff)
+	if err != nil {
+		t.Fatalf("ParseUnifiedDiff failed: %v", err)
+	}
+	incFiles, err := ParseUnifiedDiff(incremental)
+	if err != nil {
+		t.Fatalf("ParseUnifiedDiff failed: %v", err)
+	}
+	got := IncrementalFiles(prFiles, incFiles)
+	if len(got) != 1 || got[0].Path() != "a.go" || len(got[0].Hunks) != 1 || got[0].Hunks[0].NewStart != 21 {
+		t.Fatalf("expected only the second hunk of a.go, got %+v", got)
+	}
+	if len(prFiles[0].Hunks) != 2 {
+		t.Errorf("expected the PR's parsed files to be left untouched, got %d hunks", len(prFiles[0].Hunks))
+	}
+}
+
+func TestIncrementalFiles_DeletionAndRename(t *testing.T) {
+	// A pure deletion right after b.go line 5 touches the b.go hunk; the rename is repeated
+	incremental := `diff --git a/b.go b/b.go
+--- a/b.go
++++ b/b.go
+@@ -6,1 +5,0 @@
+-var old = 0
+diff --git a/c.go b/d.go
+similarity index 100%
+rename from c.go
+rename to d.go
+`
+	prFiles, _ := ParseUnifiedDiff(incrementalPRDiff)
+	incFiles, _ := ParseUnifiedDiff(incremental)
+	got := IncrementalFiles(prFiles, incFiles)
+	var paths []string
+	for _, f := range got {
+		paths = append(paths, f.Path())
+	}
+	if strings.Join(paths, ",") != "b.go,d.go" {
+		t.Errorf("expected b.go and the d.go rename, got %v", paths)
+	}
+}
+
+func TestIncrementalDiff(t *testing.T) {
+	incremental := `diff --git a/a.go b/a.go
+--- a/a.go
++++ b/a.go
+@@ -1,2 +1,3 @@
+ package a
++// first change
+ func A() {}
+`
+	got, err := IncrementalDiff(incrementalPRDiff, incremental)
+	if err != nil {
+		t.Fatalf("IncrementalDiff failed: %v", err)
+	}
+	files, err := ParseUnifiedDiff(got)
+	if err != nil || len(files) != 1 || len(files[0].Hunks) != 1 || files[0].Hunks[0].NewStart != 1 {
+		t.Fatalf("expected the first hunk of a.go only, got %q (%v)", got, err)
+	}
+	// Nothing the PR changes was touched
+	got, err = IncrementalDiff(incrementalPRDiff, "diff --git a/other.go b/other.go\n--- a/other.go\n+++ b/other.go\n@@ -1 +1 @@\n-a\n+b\n")
+	if err != nil || got != "" {
+		t.Errorf("expected an empty diff, got %q (%v)", got, err)
+	}
+}
+
+func TestLastReviewedCommit(t *testing.T) {
+	posted := []PostedComment{
+		{ID: "1", Marker: Marker{Commit: "aaa111"}},
+		{ID: "2", Marker: Marker{Commit: "bbb222"}},
+		{ID: "3"},
+	}
+	if got := LastReviewedCommit(posted); got != "bbb222" {
+		t.Errorf("expected bbb222, got %q", got)
+	}
+	// The summary is updated in place on every run, so it wins over newer comments
+	withSummary := append([]PostedComment{{ID: "0", Marker: SummaryMarker("ccc333")}}, posted...)
+	if got := LastReviewedCommit(withSummary); got != "ccc333" {
+		t.Errorf("expected ccc333 from the summary, got %q", got)
+	}
+	if got := LastReviewedCommit(nil); got != "" {
+		t.Errorf("expected no commit, got %q", got)
+	}
+}
+
+func TestSameCommit(t *testing.T) {
+	if !SameCommit("abc123def456", "abc123def4567890") || SameCommit("abc123", "abd123") || SameCommit("", "abc") {
+		t.Error("unexpected SameCommit result")
+	}
+}
@@ -0,0 +1,159 @@
+package review
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// markerVersion is bumped whenever the marker layout changes incompatibly.
+const markerVersion = "v1"
+
+// markerRe matches a marker embedded in a comment body.
+var markerRe = regexp.MustCompile(`<!-- pullreview:(\S+) ([^>]*?) -->`)
+
+// Marker identifies a posted comment across runs. It is embedded in the comment body as a
+// hidden HTML comment so a later run can tell whether the code it was anchored to still exists.
+type Marker struct {
+	Commit  string // Source commit the comment was anchored against
+	Path    string // File the comment refers to
+	Line    int    // New-file line for inline comments; 0 for file-level comments
+	OldLine int    // Old-file line for comments on deleted lines; 0 otherwise
+	Hash    string // ContentHash of the anchored line, or of the path for file-level comments
+	Summary bool   // Marks the review summary comment, which has no path or hash
+}
+
+// SummaryMarker returns the marker for the summary comment of a review of the given commit.
+func SummaryMarker(commit string) Marker {
+	return Marker{Commit: commit, Summary: true}
+}
+
+// isFileLevel reports whether the marker belongs to a file-level comment.
+func (m Marker) isFileLevel() bool {
+	return m.Line == 0 && m.OldLine == 0
+}
+
+// Encode renders the marker as a hidden HTML comment.
+func (m Marker) Encode() string {
+	if m.Summary {
+		return fmt.Sprintf("<!-- pullreview:%s kind=summary commit=%s -->", markerVersion, url.QueryEscape(m.Commit))
+	}
+	return fmt.Sprintf("<!-- pullreview:%s commit=%s path=%s line=%d old=%d hash=%s -->",
+		markerVersion, url.QueryEscape(m.Commit), url.QueryEscape(m.Path), m.Line, m.OldLine, m.Hash)
+}
+
+// AppendMarker returns text with the encoded marker appended on its own line.
+func AppendMarker(text string, m Marker) string {
+	return text + "\n\n" + m.Encode()
+}
+
+// DecodeMarker extracts the marker from a comment body. It returns false if the body has no
+// marker or the marker was written by an incompatible version.
+func DecodeMarker(body string) (Marker, bool) {
+	match := markerRe.FindStringSubmatch(body)
+	if match == nil || match[1] != markerVersion {
+		return Marker{}, false
+	}
+	var m Marker
+	for _, field := range strings.Fields(match[2]) {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok {
+			continue
+		}
+		value, err := url.QueryUnescape(value)
+		if err != nil {
+			return Marker{}, false
+		}
+		switch key {
+		case "commit":
+			m.Commit = value
+		case "path":
+			m.Path = value
+		case "line":
+			m.Line, _ = strconv.Atoi(value)
+		case "old":
+			m.OldLine, _ = strconv.Atoi(value)
+		case "hash":
+			m.Hash = value
+		case "kind":
+			m.Summary = value == "summary"
+		}
+	}
+	if m.Summary {
+		return m, true
+	}
+	if m.Path == "" || m.Hash == "" {
+		return Marker{}, false
+	}
+	return m, true
+}
+
+// StripMarker removes any marker from a comment body, returning the human-visible text.
+func StripMarker(body string) string {
+	return strings.TrimSpace(markerRe.ReplaceAllString(body, ""))
+}
+
+// ContentHash returns a short, whitespace-insensitive hash of a line of code or comment text.
+func ContentHash(content string) string {
+	sum := sha256.Sum256([]byte(strings.Join(strings.Fields(content), " ")))
+	return hex.EncodeToString(sum[:8])
+}
+
+// NewMarker builds the marker for a comment posted against the given commit. Inline comments
+// are anchored to the content of their line in the diff; file-level comments only to their
+// path, so rewording one updates it in place rather than replacing it.
+func NewMarker(c Comment, files []*DiffFile, commit string) Marker {
+	m := Marker{Commit: commit, Path: c.FilePath}
+	if c.IsFileLevel {
+		m.Hash = ContentHash(c.FilePath)
+		return m
+	}
+	if c.Line <= 0 && c.OldLine > 0 {
+		m.OldLine = c.OldLine
+	} else {
+		m.Line = c.Line
+	}
+	if content, ok := lineContent(files, m); ok {
+		m.Hash = ContentHash(content)
+	} else {
+		m.Hash = ContentHash(c.Text)
+	}
+	return m
+}
+
+// lineContent returns the content (without the diff prefix) of the line a marker points at: a
+// line of the new file, or a deleted line of the old file when OldLine is set.
+func lineContent(files []*DiffFile, m Marker) (string, bool) {
+	for _, f := range files {
+		if f.Path() != m.Path {
+			continue
+		}
+		for _, h := range f.Hunks {
+			for _, hl := range h.LineMapping {
+				if m.OldLine > 0 && hl.Type == DeletionLine && hl.OldLine == m.OldLine {
+					return diffLineText(hl.Content), true
+				}
+				if m.OldLine == 0 && hl.Type != DeletionLine && hl.NewLine == m.Line {
+					return diffLineText(hl.Content), true
+				}
+			}
+		}
+	}
+	return "", false
+}
+
+// diffLineText strips the leading +, -, or space from a diff line.
+func diffLineText(content string) string {
+	if content == "" {
+		return content
+	}
+	switch content[0] {
+	case '+', '-', ' ':
+		return content[1:]
+	}
+	return content
+}
@@ -0,0 +1,87 @@
+package review
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMarker_RoundTrip(t *testing.T) {
+	m := Marker{Commit: "abc123", Path: "dir with space/file-->x.go", Line: 42, Hash: ContentHash("return nil")}
+	body := AppendMarker("Missing error check.\nSecond line.", m)
+
+	decoded, ok := DecodeMarker(body)
+	if !ok {
+		t.Fatalf("expected marker to decode from %q", body)
+	}
+	if decoded != m {
+		t.Errorf("round-trip mismatch: got %+v, want %+v", decoded, m)
+	}
+	if got := StripMarker(body); got != "Missing error check.\nSecond line." {
+		t.Errorf("unexpected stripped text %q", got)
+	}
+}
+
+func TestSummaryMarker_RoundTrip(t *testing.T) {
+	m := SummaryMarker("abc123")
+	decoded, ok := DecodeMarker(AppendMarker("Looks good overall.", m))
+	if !ok || decoded != m {
+		t.Errorf("summary marker did not round-trip: got %+v (ok %v), want %+v", decoded, ok, m)
+	}
+}
+
+func TestDecodeMarker_Invalid(t *testing.T) {
+	bodies := []string{
+		"plain comment",
+		"<!-- pullreview:v0 commit=a path=b line=1 hash=c -->",
+		"<!-- pullreview:v1 commit=a line=1 -->",
+	}
+	for _, body := range bodies {
+		if _, ok := DecodeMarker(body); ok {
+			t.Errorf("expected no marker in %q", body)
+		}
+	}
+}
+
+func TestContentHash_IgnoresWhitespace(t *testing.T) {
+	if ContentHash("  if err != nil {") != ContentHash("if  err != nil {\t") {
+		t.Error("expected whitespace-only differences to hash equally")
+	}
+	if ContentHash("a") == ContentHash("b") {
+		t.Error("expected different content to hash differently")
+	}
+}
+
+func TestNewMarker(t *testing.T) {
+	files, err := ParseUnifiedDiff(strings.Join([]string{
+		"diff --git a/foo.go b/foo.go",
+		"@@ -1,2 +1,3 @@",
+		" package foo",
+		"+var x = 1",
+		" func f() {}",
+	}, "\n"))
+	if err != nil {
+		t.Fatalf("failed to parse diff: %v", err)
+	}
+
+	inline := NewMarker(Comment{FilePath: "foo.go", Line: 2, Text: "unused"}, files, "sha1")
+	if inline.Commit != "sha1" || inline.Line != 2 || inline.Hash != ContentHash("var x = 1") {
+		t.Errorf("unexpected inline marker %+v", inline)
+	}
+	deleted := NewMarker(Comment{FilePath: "foo.go", OldLine: 2, Text: "removed"}, []*DiffFile{{
+		NewPath: "foo.go",
+		Hunks: []*DiffHunk{{LineMapping: []HunkLine{
+			{Type: DeletionLine, Content: "-var old = 0", OldLine: 2},
+			{Type: AdditionLine, Content: "+var x = 1", NewLine: 2},
+		}}},
+	}}, "sha1")
+	if deleted.Line != 0 || deleted.OldLine != 2 || deleted.Hash != ContentHash("var old = 0") {
+		t.Errorf("unexpected deleted-line marker %+v", deleted)
+	}
+	if decoded, ok := DecodeMarker(deleted.Encode()); !ok || decoded != deleted {
+		t.Errorf("deleted-line marker did not round-trip: %+v", decoded)
+	}
+	fileLevel := NewMarker(Comment{FilePath: "foo.go", Text: "no tests", IsFileLevel: true}, files, "sha1")
+	if fileLevel.Line != 0 || fileLevel.Hash != ContentHash("foo.go") {
+		t.Errorf("unexpected file-level marker %+v", fileLevel)
+	}
+}
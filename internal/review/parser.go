@@ -34,7 +34,7 @@ func ParseLLMResponse(llmResp string) ([]Comment, string) {
 
 	}
 
-	return comments, summary
+	return DedupeComments(comments), summary
 }
 
 func splitSectionsNewFormat(llmResp string) map[string]string {
@@ -64,68 +64,162 @@ func splitSectionsNewFormat(llmResp string) map[string]string {
 	return sections
 }
 
+// fileLineRefRe matches a backtick-wrapped "path:line" reference at the start of a line, e.g.
+// "`internal/auth.go:42`: Token is compared with ==", capturing the path, line, and the rest.
+var fileLineRefRe = regexp.MustCompile("^`([^`\\s]+):(\\d+)`\\s*[:\\-]?\\s*(.*)$")
+
 func parseExplicitInlineComments(content string) []Comment {
 	var comments []Comment
 	scanner := bufio.NewScanner(strings.NewReader(content))
 	var file string
-	var line int
+	var line, oldLine int
 	var comment string
+	var category string
+	var severity string
+	inComment := false
+	inFence := false
+	flush := func() {
+		if file != "" && (line > 0 || oldLine > 0) && comment != "" {
+			comments = append(comments, Comment{
+				FilePath:   file,
+				Line:       line,
+				OldLine:    oldLine,
+				Text:       comment,
+				Category:   category,
+				Severity:   severity,
+				Suggestion: extractSuggestion(comment),
+			})
+		}
+		line, oldLine, comment, category, severity = 0, 0, "", "", ""
+	}
 	for scanner.Scan() {
 		txt := strings.TrimSpace(scanner.Text())
+		if inComment && strings.HasPrefix(txt, "```") {
+			// Fenced blocks (e.g. ```suggestion) are kept verbatim, blank lines included
+			inFence = !inFence
+			comment = appendCommentLine(comment, scanner.Text())
+			continue
+		}
+		if inFence {
+			comment = appendCommentLine(comment, scanner.Text())
+			continue
+		}
 		if txt == "" {
-			if file != "" && line > 0 && comment != "" {
-				comments = append(comments, Comment{
-					FilePath: file,
-					Line:     line,
-					Text:     comment,
-				})
-			}
-			file, line, comment = "", 0, ""
+			inComment = false
+			flush()
+			file = ""
 			continue
 		}
 		if strings.HasPrefix(txt, "FILE:") {
+			// A new FILE: key also ends a complete block that was not followed by a blank line
+			flush()
+			inComment = false
 			file = strings.TrimSpace(txt[len("FILE:"):])
+		} else if m := fileLineRefRe.FindStringSubmatch(txt); m != nil {
+			// "`path:line` comment" starts a comment of its own
+			flush()
+			file = m[1]
+			line, _ = strconv.Atoi(m[2])
+			inComment = true
+			comment = m[3]
 		} else if strings.HasPrefix(txt, "LINE:") {
+			inComment = false
 			lineStr := strings.TrimSpace(txt[len("LINE:"):])
 			line, _ = strconv.Atoi(lineStr)
+		} else if strings.HasPrefix(txt, "OLD_LINE:") {
+			// Anchors the comment to a deleted line, numbered in the old file
+			inComment = false
+			oldLine, _ = strconv.Atoi(strings.TrimSpace(txt[len("OLD_LINE:"):]))
+		} else if strings.HasPrefix(txt, "CATEGORY:") {
+			inComment = false
+			category = NormalizeCategory(txt[len("CATEGORY:"):])
+		} else if strings.HasPrefix(txt, "SEVERITY:") {
+			inComment = false
+			severity = NormalizeSeverity(txt[len("SEVERITY:"):])
 		} else if strings.HasPrefix(txt, "COMMENT:") {
+			inComment = true
 			comment = strings.TrimSpace(txt[len("COMMENT:"):])
+		} else if inComment {
+			comment = appendCommentLine(comment, scanner.Text())
 		}
 	}
 	// Handle last block if not followed by blank line
-	if file != "" && line > 0 && comment != "" {
-		comments = append(comments, Comment{
-			FilePath: file,
-			Line:     line,
-			Text:     comment,
-		})
-	}
+	flush()
 	return comments
 }
 
+// extractSuggestion returns the replacement code of the first ```suggestion fenced block in a
+// comment, or "" if there is none. The replacement keeps its lines and indentation.
+func extractSuggestion(comment string) string {
+	lines := strings.Split(comment, "\n")
+	for i, l := range lines {
+		if !strings.HasPrefix(strings.TrimSpace(l), "```suggestion") {
+			continue
+		}
+		var body []string
+		for _, b := range lines[i+1:] {
+			if strings.HasPrefix(strings.TrimSpace(b), "```") {
+				return strings.Join(body, "\n")
+			}
+			body = append(body, b)
+		}
+		// Unterminated block: the rest of the comment is the suggestion
+		return strings.Join(body, "\n")
+	}
+	return ""
+}
+
 func parseExplicitFileLevelComments(content string) []Comment {
 	var comments []Comment
 	scanner := bufio.NewScanner(strings.NewReader(content))
 	var file string
 	var comment string
+	var category string
+	var severity string
+	inComment := false
 	for scanner.Scan() {
 		txt := strings.TrimSpace(scanner.Text())
 		if txt == "" {
+			inComment = false
 			if file != "" && comment != "" {
 				comments = append(comments, Comment{
 					FilePath:    file,
 					Line:        0,
 					Text:        comment,
 					IsFileLevel: true,
+					Category:    category,
+					Severity:    severity,
 				})
 			}
-			file, comment = "", ""
+			file, comment, category, severity = "", "", "", ""
 			continue
 		}
 		if strings.HasPrefix(txt, "FILE:") {
+			// A new FILE: key also ends a complete block that was not followed by a blank line
+			if file != "" && comment != "" {
+				comments = append(comments, Comment{
+					FilePath:    file,
+					Line:        0,
+					Text:        comment,
+					IsFileLevel: true,
+					Category:    category,
+					Severity:    severity,
+				})
+				comment, category, severity = "", "", ""
+			}
+			inComment = false
 			file = strings.TrimSpace(txt[len("FILE:"):])
+		} else if strings.HasPrefix(txt, "CATEGORY:") {
+			inComment = false
+			category = NormalizeCategory(txt[len("CATEGORY:"):])
+		} else if strings.HasPrefix(txt, "SEVERITY:") {
+			inComment = false
+			severity = NormalizeSeverity(txt[len("SEVERITY:"):])
 		} else if strings.HasPrefix(txt, "COMMENT:") {
+			inComment = true
 			comment = strings.TrimSpace(txt[len("COMMENT:"):])
+		} else if inComment {
+			comment = appendCommentLine(comment, scanner.Text())
 		}
 	}
 	// Handle last block if not followed by blank line
@@ -135,11 +229,23 @@ func parseExplicitFileLevelComments(content string) []Comment {
 			Line:        0,
 			Text:        comment,
 			IsFileLevel: true,
+			Category:    category,
+			Severity:    severity,
 		})
 	}
 	return comments
 }
 
+// appendCommentLine appends a continuation line to a multi-line COMMENT value, keeping the
+// line's indentation so lists and code snippets survive.
+func appendCommentLine(comment, line string) string {
+	line = strings.TrimRight(line, " \t\r")
+	if comment == "" {
+		return strings.TrimSpace(line)
+	}
+	return comment + "\n" + line
+}
+
 func parseExplicitSummary(content string) string {
 	// The summary section is just the text content, possibly with blank lines.
 	// We'll trim leading/trailing whitespace and collapse multiple blank lines to a single space.
@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 	"testing"
 )
@@ -45,6 +46,8 @@ func parseExpectations(section string) ([]Expectation, error) {
 					}
 				}
 			}
+			// Multi-line comments are written with \n escapes
+			exp.Comment = strings.ReplaceAll(exp.Comment, `\n`, "\n")
 			exps = append(exps, exp)
 		} else if strings.HasPrefix(line, "file:") {
 			exp := Expectation{Type: "file"}
@@ -212,3 +215,215 @@ func TestLLMResponseParsingFromTestFiles(t *testing.T) {
 		})
 	}
 }
+
+func TestParseLLMResponse_Categories(t *testing.T) {
+	raw := `******************** SECTION: FILE-LEVEL COMMENTS ********************
+
+FILE: db.go
+CATEGORY: Performance
+COMMENT: Queries run inside a loop.
+
+******************** SECTION: INLINE COMMENTS ********************
+
+FILE: auth.go
+LINE: 12
+CATEGORY: security
+COMMENT: Token is compared with ==, use constant-time comparison.
+
+FILE: auth.go
+LINE: 30
+CATEGORY: BUG
+COMMENT: Error is ignored.
+
+FILE: util.go
+LINE: 4
+COMMENT: Missing category is tolerated.
+
+******************** SECTION: SUMMARY ********************
+
+Summary text.
+`
+	comments, _ := ParseLLMResponse(raw)
+	if len(comments) != 4 {
+		t.Fatalf("expected 4 comments, got %d", len(comments))
+	}
+	want := map[string]string{
+		"auth.go:12": "security",
+		"auth.go:30": "bug",
+		"util.go:4":  "",
+		"db.go:0":    "perf",
+	}
+	for _, c := range comments {
+		key := c.FilePath + ":" + strconv.Itoa(c.Line)
+		cat, ok := want[key]
+		if !ok {
+			t.Errorf("unexpected comment %s", key)
+			continue
+		}
+		if c.Category != cat {
+			t.Errorf("comment %s: expected category %q, got %q", key, cat, c.Category)
+		}
+	}
+}
+
+func TestParseLLMResponse_Severity(t *testing.T) {
+	raw := `******************** SECTION: FILE-LEVEL COMMENTS ********************
+
+FILE: db.go
+CATEGORY: perf
+SEVERITY: Medium
+COMMENT: Queries run inside a loop.
+
+******************** SECTION: INLINE COMMENTS ********************
+
+FILE: auth.go
+LINE: 12
+SEVERITY: critical
+CATEGORY: security
+COMMENT: Token is compared with ==, use constant-time comparison.
+
+FILE: auth.go
+LINE: 30
+SEVERITY: minor
+COMMENT: Error is ignored.
+FILE: util.go
+LINE: 4
+COMMENT: Missing severity is tolerated.
+
+******************** SECTION: SUMMARY ********************
+
+Summary text.
+`
+	comments, _ := ParseLLMResponse(raw)
+	if len(comments) != 4 {
+		t.Fatalf("expected 4 comments, got %d", len(comments))
+	}
+	want := map[string]string{
+		"auth.go:12": "critical",
+		"auth.go:30": "low",
+		"util.go:4":  "",
+		"db.go:0":    "medium",
+	}
+	for _, c := range comments {
+		key := c.FilePath + ":" + strconv.Itoa(c.Line)
+		sev, ok := want[key]
+		if !ok {
+			t.Errorf("unexpected comment %s", key)
+			continue
+		}
+		if c.Severity != sev {
+			t.Errorf("comment %s: expected severity %q, got %q", key, sev, c.Severity)
+		}
+	}
+}
+
+func TestParseLLMResponse_MultilineComments(t *testing.T) {
+	raw := `******************** SECTION: FILE-LEVEL COMMENTS ********************
+
+FILE: server.go
+COMMENT: Handlers share mutable state without locking.
+This causes data races under load:
+  - sessions map
+  - counters
+
+******************** SECTION: INLINE COMMENTS ********************
+
+FILE: auth.go
+LINE: 12
+COMMENT: Token comparison is not constant-time.
+Use subtle.ConstantTimeCompare instead:
+    subtle.ConstantTimeCompare(a, b) == 1
+FILE: auth.go
+LINE: 20
+COMMENT: Single-line comment.
+
+FILE: auth.go
+LINE: 30
+COMMENT:
+Comment body starting on the next line.
+CATEGORY: bug
+
+******************** SECTION: SUMMARY ********************
+
+Summary.
+`
+	comments, _ := ParseLLMResponse(raw)
+	if len(comments) != 4 {
+		t.Fatalf("expected 4 comments, got %d: %+v", len(comments), comments)
+	}
+	got := make(map[string]Comment)
+	for _, c := range comments {
+		got[c.FilePath+":"+strconv.Itoa(c.Line)] = c
+	}
+
+	wantFile := "Handlers share mutable state without locking.\nThis causes data races under load:\n  - sessions map\n  - counters"
+	if c := got["server.go:0"]; c.Text != wantFile {
+		t.Errorf("unexpected file-level text:\n%q\nwant:\n%q", c.Text, wantFile)
+	}
+	wantInline := "Token comparison is not constant-time.\nUse subtle.ConstantTimeCompare instead:\n    subtle.ConstantTimeCompare(a, b) == 1"
+	if c := got["auth.go:12"]; c.Text != wantInline {
+		t.Errorf("unexpected inline text:\n%q\nwant:\n%q", c.Text, wantInline)
+	}
+	if c := got["auth.go:20"]; c.Text != "Single-line comment." {
+		t.Errorf("expected single-line comment unchanged, got %q", c.Text)
+	}
+	if c := got["auth.go:30"]; c.Text != "Comment body starting on the next line." || c.Category != "bug" {
+		t.Errorf("expected continuation to stop at CATEGORY key, got %+v", c)
+	}
+}
+
+func TestParseLLMResponse_OldLineAnchor(t *testing.T) {
+	raw := `******************** SECTION: INLINE COMMENTS ********************
+
+FILE: foo.go
+OLD_LINE: 14
+COMMENT: The nil check removed here is still needed.
+
+FILE: foo.go
+LINE: 20
+COMMENT: Regular comment.
+
+******************** SECTION: SUMMARY ********************
+
+Summary.
+`
+	comments, _ := ParseLLMResponse(raw)
+	if len(comments) != 2 {
+		t.Fatalf("expected 2 comments, got %d: %+v", len(comments), comments)
+	}
+	if c := comments[0]; c.OldLine != 14 || c.Line != 0 || c.Text != "The nil check removed here is still needed." {
+		t.Errorf("unexpected deleted-line comment %+v", c)
+	}
+	if c := comments[1]; c.Line != 20 || c.OldLine != 0 {
+		t.Errorf("unexpected new-line comment %+v", c)
+	}
+}
+
+func TestParseLLMResponse_SuggestionBlocks(t *testing.T) {
+	data, err := os.ReadFile("testdata/llm_output_suggestions.txt")
+	if err != nil {
+		t.Fatalf("failed to read testdata: %v", err)
+	}
+	raw := strings.SplitN(string(data), "***Raw*Seperator***", 2)[1]
+	comments, _ := ParseLLMResponse(raw)
+	want := map[string]string{
+		"internal/auth/token.go:12": "\tif subtle.ConstantTimeCompare([]byte(got), []byte(want)) != 1 {\n\n\t\treturn ErrInvalidToken",
+		"internal/auth/token.go:30": "",
+		"cmd/server/main.go:7":      "",
+		"cmd/server/main.go:19":     "\tdefer srv.Close()",
+	}
+	for _, c := range comments {
+		if c.IsFileLevel {
+			continue
+		}
+		key := c.Location()
+		suggestion, ok := want[key]
+		if !ok {
+			t.Errorf("unexpected comment %s", key)
+			continue
+		}
+		if c.Suggestion != suggestion {
+			t.Errorf("comment %s: expected suggestion %q, got %q", key, suggestion, c.Suggestion)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package review
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// PathFilter selects files by glob patterns. A path is kept if it matches any Include pattern
+// (or Include is empty) and no Exclude pattern. Patterns use path.Match syntax plus "**", which
+// matches any number of directories (e.g. "internal/**", "**/*_test.go"). A pattern without a
+// "/" matches the file's base name in any directory (e.g. "*.pb.go"). Paths matched by Ignore
+// (a .pullreviewignore file) are dropped as well.
+type PathFilter struct {
+	Include []string
+	Exclude []string
+	Ignore  *IgnoreRules
+}
+
+// Validate returns an error for malformed patterns.
+func (pf PathFilter) Validate() error {
+	for _, p := range append(append([]string{}, pf.Include...), pf.Exclude...) {
+		if _, err := globRegexp(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IsZero reports whether the filter has no patterns and so keeps every path.
+func (pf PathFilter) IsZero() bool {
+	return len(pf.Include) == 0 && len(pf.Exclude) == 0 && pf.Ignore.Len() == 0
+}
+
+// Match reports whether the filter keeps the given path.
+func (pf PathFilter) Match(p string) bool {
+	if len(pf.Include) > 0 && !matchAny(pf.Include, p) {
+		return false
+	}
+	return !matchAny(pf.Exclude, p) && !pf.Ignore.Ignored(p)
+}
+
+func matchAny(patterns []string, p string) bool {
+	for _, pattern := range patterns {
+		if matchGlob(pattern, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchGlob matches a single pattern; malformed patterns match nothing (see Validate).
+func matchGlob(pattern, p string) bool {
+	pattern = strings.TrimPrefix(pattern, "./")
+	if !strings.Contains(pattern, "/") {
+		p = path.Base(p)
+	}
+	re, err := globRegexp(pattern)
+	return err == nil && re.MatchString(p)
+}
+
+// globRegexp translates a glob pattern into an anchored regular expression.
+func globRegexp(pattern string) (*regexp.Regexp, error) {
+	if _, err := path.Match(strings.ReplaceAll(pattern, "**", "*"), ""); err != nil {
+		return nil, fmt.Errorf("invalid path pattern %q: %w", pattern, err)
+	}
+	var sb strings.Builder
+	sb.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			// Zero or more leading directories
+			sb.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**"):
+			sb.WriteString(".*")
+			i++
+		case c == '*':
+			sb.WriteString("[^/]*")
+		case c == '?':
+			sb.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(pattern[i:], ']')
+			class := pattern[i+1 : i+end]
+			if strings.HasPrefix(class, "^") {
+				class = "^" + regexp.QuoteMeta(class[1:])
+			} else {
+				class = regexp.QuoteMeta(class)
+			}
+			// QuoteMeta escapes '-', which would turn ranges into literals
+			sb.WriteString("[" + strings.ReplaceAll(class, `\-`, "-") + "]")
+			i += end
+		case c == '\\' && i+1 < len(pattern):
+			i++
+			sb.WriteString(regexp.QuoteMeta(string(pattern[i])))
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	sb.WriteString("$")
+	return regexp.Compile(sb.String())
+}
+
+// FilterPaths drops the diff files and comments whose paths the filter does not keep, and
+// rebuilds r.Diff from the remaining files so excluded paths are never sent to the LLM. It
+// returns the number of files dropped. The diff must have been parsed (ParseDiff).
+func (r *Review) FilterPaths(pf PathFilter) int {
+	if pf.IsZero() {
+		return 0
+	}
+	var kept []*DiffFile
+	var diff strings.Builder
+	for _, f := range r.Files {
+		if pf.Match(f.Path()) {
+			kept = append(kept, f)
+			diff.WriteString(formatUnifiedDiff(f))
+		}
+	}
+	dropped := len(r.Files) - len(kept)
+	if dropped > 0 {
+		r.Files = kept
+		r.Diff = diff.String()
+	}
+	r.Comments = FilterCommentsByPath(r.Comments, pf)
+	return dropped
+}
+
+// FilterCommentsByPath returns only the comments on paths the filter keeps.
+func FilterCommentsByPath(comments []Comment, pf PathFilter) []Comment {
+	if pf.IsZero() {
+		return comments
+	}
+	var filtered []Comment
+	for _, c := range comments {
+		if pf.Match(c.FilePath) {
+			filtered = append(filtered, c)
+		}
+	}
+	return filtered
+}
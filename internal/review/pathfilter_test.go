@@ -0,0 +1,102 @@
+package review
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPathFilter_Match(t *testing.T) {
+	tests := []struct {
+		name string
+		pf   PathFilter
+		want map[string]bool
+	}{
+		{
+			name: "include only",
+			pf:   PathFilter{Include: []string{"internal/**"}},
+			want: map[string]bool{
+				"internal/review/review.go": true,
+				"internal/a.go":             true,
+				"cmd/pullreview/main.go":    false,
+				"README.md":                 false,
+			},
+		},
+		{
+			name: "exclude only",
+			pf:   PathFilter{Exclude: []string{"vendor/**", "*.pb.go"}},
+			want: map[string]bool{
+				"vendor/github.com/x/y.go": false,
+				"api/v1/service.pb.go":     false,
+				"api/v1/service.go":        true,
+				"main.go":                  true,
+			},
+		},
+		{
+			name: "include and exclude",
+			pf:   PathFilter{Include: []string{"internal/**/*.go"}, Exclude: []string{"**/*_test.go", "internal/gen/**"}},
+			want: map[string]bool{
+				"internal/review/review.go":      true,
+				"internal/x.go":                  true,
+				"internal/review/review_test.go": false,
+				"internal/gen/models.go":         false,
+				"internal/review/testdata/a.txt": false,
+				"cmd/main.go":                    false,
+			},
+		},
+		{
+			name: "character classes",
+			pf:   PathFilter{Include: []string{"docs/v[0-9].md", "?.go"}},
+			want: map[string]bool{
+				"docs/v2.md": true,
+				"docs/vx.md": false,
+				"pkg/a.go":   true,
+				"pkg/ab.go":  false,
+			},
+		},
+	}
+	for _, tt := range tests {
+		for p, want := range tt.want {
+			if got := tt.pf.Match(p); got != want {
+				t.Errorf("%s: Match(%q) = %v, want %v", tt.name, p, got, want)
+			}
+		}
+	}
+}
+
+func TestPathFilter_Validate(t *testing.T) {
+	if err := (PathFilter{Include: []string{"internal/**"}, Exclude: []string{"*.pb.go"}}).Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := (PathFilter{Exclude: []string{"vendor/[abc"}}).Validate(); err == nil {
+		t.Error("expected an error for a malformed pattern")
+	}
+}
+
+func TestReview_FilterPaths(t *testing.T) {
+	r := NewReview("1", multiFileDiff(3))
+	if err := r.ParseDiff(); err != nil {
+		t.Fatalf("ParseDiff failed: %v", err)
+	}
+	r.Comments = []Comment{
+		{FilePath: "pkg/file1.go", Line: 2, Text: "kept"},
+		{FilePath: "pkg/file2.go", Line: 2, Text: "dropped"},
+	}
+	dropped := r.FilterPaths(PathFilter{Exclude: []string{"pkg/file2.go"}})
+	if dropped != 1 || len(r.Files) != 2 {
+		t.Fatalf("expected 1 file dropped and 2 kept, got %d dropped and %d kept", dropped, len(r.Files))
+	}
+	if strings.Contains(r.Diff, "file2.go") || !strings.Contains(r.Diff, "pkg/file3.go") {
+		t.Errorf("expected the rebuilt diff to omit only the excluded file:\n%s", r.Diff)
+	}
+	if len(r.Comments) != 1 || r.Comments[0].Text != "kept" {
+		t.Errorf("expected comments on excluded files to be dropped, got %+v", r.Comments)
+	}
+	// The rebuilt diff is what gets chunked and sent, so it must still parse
+	files, err := ParseUnifiedDiff(r.Diff)
+	if err != nil || len(files) != 2 {
+		t.Errorf("rebuilt diff does not parse back into 2 files: %v", err)
+	}
+	if chunks := SplitDiff(r.Files, 1); len(chunks) != 2 {
+		t.Errorf("expected chunking to see only the 2 kept files, got %d chunks", len(chunks))
+	}
+}
@@ -0,0 +1,121 @@
+package review
+
+import (
+	"fmt"
+	"io"
+
+	"pullreview/internal/logging"
+)
+
+// Options configure Run. The zero value reviews the whole diff in one request and keeps every
+// comment the LLM returns.
+type Options struct {
+	Paths      PathFilter // Files to leave out of the review, and whose comments are dropped
+	Categories []string   // Only keep comments in these categories (all if empty)
+
+	MinSeverity       string // Only keep comments at or above this severity
+	LineTolerance     int    // See MatchCommentsToDiffWithTolerance
+	MaxInlineComments int    // Move inline comments over this many to the summary (0 means unlimited)
+
+	MaxDiffBytes       int    // Review diffs larger than this in per-file chunks (0 means one request)
+	OversizedDiffBytes int    // Diffs larger than this are handled per OversizedDiff (0 means no limit)
+	OversizedDiff      string // OversizedSkip (default), OversizedChunk or OversizedTruncate
+
+	OutsideDiff string // Policy for comments on files not in the diff; see ApplyOutsideDiffPolicy
+	RepoRoot    string // Checkout used by OutsideDiffVerify
+
+	// Logger receives progress messages; nil discards them.
+	Logger logging.Logger
+	// StartParse, if set, is called when parsing starts and again when placing the comments
+	// starts; the function it returns is called when each ends.
+	StartParse func() (stop func())
+}
+
+// Run reviews diff: it parses it, leaves out the files opts.Paths filters out, applies the
+// oversized diff policy, sends the diff to the LLM through send (in chunks if it is large),
+// and filters and places the comments. r.Matched and r.Unmatched hold the outcome after the
+// filters, the outside-diff policy and the inline comment cap; with OversizedTruncate,
+// r.Dropped lists the files left out. send receives a diff and returns the raw LLM response.
+// When the diff is skipped as oversized, or no file of it fits the limit, the returned review
+// is nil.
+func Run(prID, diff string, opts Options, send func(diff string) (string, error)) (*Review, error) {
+	log := opts.Logger
+	if log == nil {
+		log = &logging.Console{Out: io.Discard, Err: io.Discard}
+	}
+	startParse := opts.StartParse
+	if startParse == nil {
+		startParse = func() func() { return func() {} }
+	}
+
+	stopParse := startParse()
+	r := NewReview(prID, diff)
+	if err := r.ParseDiff(); err != nil {
+		log.Error("Warning: failed to parse diff for comment mapping: %v", err)
+	}
+	stopParse()
+	pf := opts.Paths
+	if !pf.IsZero() {
+		if len(r.Files) == 0 {
+			log.Error("Warning: --include/--exclude and %s ignored because the diff could not be parsed", IgnoreFileName)
+		} else {
+			if n := r.FilterPaths(pf); n > 0 {
+				log.Info("🚫 Skipping %d file(s) filtered out by --include/--exclude or %s", n, IgnoreFileName)
+			}
+			if len(r.Files) == 0 {
+				log.Info("ℹ️  No files left to review after filtering.")
+				return r, nil
+			}
+		}
+	}
+	chunkBytes := opts.MaxDiffBytes
+	switch d := DecideDiffSize(r.Files, len(r.Diff), opts.OversizedDiffBytes, opts.OversizedDiff); d.Action {
+	case OversizedSkip:
+		log.Info("⏭️  Skipping review: the diff is %d bytes, over oversized_diff_bytes (%d).", len(r.Diff), opts.OversizedDiffBytes)
+		log.Info("   Narrow it with --include/--exclude or %s, raise oversized_diff_bytes, or set oversized_diff to chunk or truncate.", IgnoreFileName)
+		return nil, nil
+	case OversizedChunk:
+		if chunkBytes <= 0 || chunkBytes > opts.OversizedDiffBytes {
+			chunkBytes = opts.OversizedDiffBytes
+		}
+	case OversizedTruncate:
+		log.Info("✂️  Diff is %d bytes, over oversized_diff_bytes (%d); reviewing %d file(s) and leaving out %d:",
+			len(r.Diff), opts.OversizedDiffBytes, len(d.Files), len(d.Dropped))
+		for _, path := range d.Dropped {
+			log.Info("   - %s", path)
+		}
+		if len(d.Files) == 0 {
+			log.Info("ℹ️  No file fits within oversized_diff_bytes; nothing to review.")
+			return nil, nil
+		}
+		r.Files, r.Diff, r.Dropped = d.Files, d.Diff, d.Dropped
+	}
+	if chunkBytes > 0 && len(r.Diff) > chunkBytes && len(r.Files) > 0 {
+		log.Info("✂️  Diff is %d bytes (limit %d); reviewing it in %d chunk(s)",
+			len(r.Diff), chunkBytes, len(SplitDiff(r.Files, chunkBytes)))
+	}
+
+	if err := r.ReviewInChunks(chunkBytes, send); err != nil {
+		return nil, fmt.Errorf("failed to get response from LLM: %w", err)
+	}
+	defer startParse()()
+	r.Comments = FilterCommentsByPath(r.Comments, pf)
+	r.Comments = FilterByCategory(r.Comments, opts.Categories)
+	r.Comments = FilterBySeverity(r.Comments, opts.MinSeverity)
+	SortBySeverity(r.Comments)
+
+	// Only keep the comments that match the diff, and report the rest
+	r.MatchComments(opts.LineTolerance)
+
+	// Decide what to do with comments on files outside the diff
+	promoted, unmatched := ApplyOutsideDiffPolicy(opts.OutsideDiff, r.Unmatched, r.Files, opts.RepoRoot)
+	r.Matched, r.Unmatched = append(r.Matched, promoted...), unmatched
+
+	// Keep the inline comments within the cap; the overflow is reported in the summary
+	matched, overflow := CapInlineComments(r.Matched, opts.MaxInlineComments)
+	if len(overflow) > 0 {
+		log.Info("✂️  %d inline comment(s) over the limit of %d moved to the summary", len(overflow), opts.MaxInlineComments)
+	}
+	r.Matched, r.Unmatched = matched, append(r.Unmatched, overflow...)
+	return r, nil
+}
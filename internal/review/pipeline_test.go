@@ -0,0 +1,78 @@
+package review
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	diff := multiFileDiff(3)
+	files, err := ParseUnifiedDiff(diff)
+	if err != nil {
+		t.Fatalf("ParseUnifiedDiff failed: %v", err)
+	}
+	single := len(formatUnifiedDiff(files[0]))
+	ignore, err := ParseIgnore(strings.NewReader("pkg/file3.go\n"))
+	if err != nil {
+		t.Fatalf("ParseIgnore failed: %v", err)
+	}
+
+	t.Run("ignored files are neither reviewed nor commented on", func(t *testing.T) {
+		var calls []string
+		r, err := Run("1", diff, Options{Paths: PathFilter{Ignore: ignore}}, fakeLLM(&calls))
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		if len(calls) != 1 || strings.Contains(calls[0], "file3.go") {
+			t.Fatalf("expected one request without the ignored file, got %q", calls)
+		}
+		if len(r.Matched) != 3 { // Two inline comments and the file-level one on file1.go
+			t.Errorf("expected 3 matched comments, got %+v", r.Matched)
+		}
+	})
+
+	t.Run("oversized diff is skipped", func(t *testing.T) {
+		var calls []string
+		r, err := Run("1", diff, Options{OversizedDiffBytes: single}, fakeLLM(&calls))
+		if err != nil || r != nil || len(calls) != 0 {
+			t.Errorf("expected the diff to be skipped without a request, got %+v, %v, %d request(s)", r, err, len(calls))
+		}
+	})
+
+	t.Run("oversized diff is truncated", func(t *testing.T) {
+		var calls []string
+		r, err := Run("1", diff, Options{OversizedDiffBytes: 2 * single, OversizedDiff: OversizedTruncate}, fakeLLM(&calls))
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		if len(calls) != 1 || strings.Contains(calls[0], "file3.go") {
+			t.Fatalf("expected one request without the last file, got %q", calls)
+		}
+		if len(r.Dropped) != 1 || r.Dropped[0] != "pkg/file3.go" {
+			t.Errorf("expected pkg/file3.go to be dropped, got %v", r.Dropped)
+		}
+	})
+
+	t.Run("oversized diff is chunked", func(t *testing.T) {
+		var calls []string
+		_, err := Run("1", diff, Options{OversizedDiffBytes: single, OversizedDiff: OversizedChunk}, fakeLLM(&calls))
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		if len(calls) != 3 {
+			t.Errorf("expected one request per file, got %d", len(calls))
+		}
+	})
+
+	t.Run("inline comments are capped", func(t *testing.T) {
+		var calls []string
+		r, err := Run("1", diff, Options{MaxInlineComments: 1}, fakeLLM(&calls))
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		// The file-level comment does not count towards the cap
+		if len(r.Matched) != 2 || len(r.Unmatched) != 2 {
+			t.Errorf("expected 2 matched and 2 unmatched comments, got %+v and %+v", r.Matched, r.Unmatched)
+		}
+	})
+}
@@ -0,0 +1,84 @@
+package review
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Policies for comments that reference files not present in the diff.
+const (
+	OutsideDiffDrop    = "drop"    // Discard the comments
+	OutsideDiffSummary = "summary" // Fold them into the summary (default)
+	OutsideDiffVerify  = "verify"  // Post as file-level comments if the file exists in the repo, else fold into the summary
+)
+
+// ValidateOutsideDiffPolicy returns an error if policy is not one of the supported values.
+// An empty policy is treated as OutsideDiffSummary.
+func ValidateOutsideDiffPolicy(policy string) error {
+	switch strings.ToLower(policy) {
+	case "", OutsideDiffDrop, OutsideDiffSummary, OutsideDiffVerify:
+		return nil
+	default:
+		return fmt.Errorf("invalid outside-diff policy %q (must be %s, %s, or %s)", policy, OutsideDiffDrop, OutsideDiffSummary, OutsideDiffVerify)
+	}
+}
+
+// ApplyOutsideDiffPolicy decides what happens to unmatched comments whose file is not part of
+// the diff. Unmatched comments on files that are in the diff (i.e. the line did not match) are
+// always kept in the returned unmatched slice.
+//
+// It returns the comments to promote to file-level comments and the comments that remain
+// unmatched (to be folded into the summary). repoRoot is used by OutsideDiffVerify to check
+// that the referenced file really exists.
+func ApplyOutsideDiffPolicy(policy string, unmatched []Comment, files []*DiffFile, repoRoot string) (promoted []Comment, remaining []Comment) {
+	inDiff := make(map[string]bool)
+	for _, f := range files {
+		inDiff[f.Path()] = true
+	}
+
+	for _, c := range unmatched {
+		if inDiff[c.FilePath] {
+			remaining = append(remaining, c)
+			continue
+		}
+		switch strings.ToLower(policy) {
+		case OutsideDiffDrop:
+			// Discard
+		case OutsideDiffVerify:
+			if fileExistsInRepo(repoRoot, c.FilePath) {
+				promoted = append(promoted, toFileLevel(c))
+			} else {
+				remaining = append(remaining, c)
+			}
+		default:
+			remaining = append(remaining, c)
+		}
+	}
+	return promoted, remaining
+}
+
+// toFileLevel converts an inline comment into a file-level one, keeping the line reference in the text.
+func toFileLevel(c Comment) Comment {
+	if !c.IsFileLevel && c.Line > 0 {
+		c.Text = fmt.Sprintf("Line %d: %s", c.Line, c.Text)
+	}
+	c.IsFileLevel = true
+	c.Line = 0
+	return c
+}
+
+// fileExistsInRepo reports whether relPath names a regular file inside repoRoot.
+// Paths that are absolute or escape the repo root are rejected.
+func fileExistsInRepo(repoRoot, relPath string) bool {
+	if repoRoot == "" || relPath == "" {
+		return false
+	}
+	clean := filepath.FromSlash(relPath)
+	if !filepath.IsLocal(clean) {
+		return false
+	}
+	info, err := os.Stat(filepath.Join(repoRoot, clean))
+	return err == nil && info.Mode().IsRegular()
+}
@@ -0,0 +1,81 @@
+package review
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func policyFixture(t *testing.T) ([]Comment, []*DiffFile, string) {
+	t.Helper()
+	repoRoot := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(repoRoot, "pkg"), 0755); err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(repoRoot, "pkg", "real.go"), []byte("package pkg\n"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	files := []*DiffFile{{OldPath: "foo.go", NewPath: "foo.go"}}
+	unmatched := []Comment{
+		{FilePath: "foo.go", Line: 99, Text: "wrong line in diff file"},
+		{FilePath: "pkg/real.go", Line: 4, Text: "real file outside diff"},
+		{FilePath: "made/up.go", Line: 1, Text: "hallucinated file"},
+		{FilePath: "../escape.go", Text: "escapes repo", IsFileLevel: true},
+	}
+	return unmatched, files, repoRoot
+}
+
+func TestApplyOutsideDiffPolicy_Summary(t *testing.T) {
+	unmatched, files, repoRoot := policyFixture(t)
+	promoted, remaining := ApplyOutsideDiffPolicy(OutsideDiffSummary, unmatched, files, repoRoot)
+	if len(promoted) != 0 {
+		t.Errorf("expected no promoted comments, got %d", len(promoted))
+	}
+	if len(remaining) != 4 {
+		t.Errorf("expected all 4 comments to remain for the summary, got %d", len(remaining))
+	}
+	// Empty policy behaves like summary
+	if _, remaining := ApplyOutsideDiffPolicy("", unmatched, files, repoRoot); len(remaining) != 4 {
+		t.Errorf("expected default policy to keep all comments, got %d", len(remaining))
+	}
+}
+
+func TestApplyOutsideDiffPolicy_Drop(t *testing.T) {
+	unmatched, files, repoRoot := policyFixture(t)
+	promoted, remaining := ApplyOutsideDiffPolicy(OutsideDiffDrop, unmatched, files, repoRoot)
+	if len(promoted) != 0 {
+		t.Errorf("expected no promoted comments, got %d", len(promoted))
+	}
+	if len(remaining) != 1 || remaining[0].FilePath != "foo.go" {
+		t.Errorf("expected only the in-diff comment to remain, got %+v", remaining)
+	}
+}
+
+func TestApplyOutsideDiffPolicy_Verify(t *testing.T) {
+	unmatched, files, repoRoot := policyFixture(t)
+	promoted, remaining := ApplyOutsideDiffPolicy(OutsideDiffVerify, unmatched, files, repoRoot)
+	if len(promoted) != 1 {
+		t.Fatalf("expected 1 promoted comment, got %d", len(promoted))
+	}
+	p := promoted[0]
+	if p.FilePath != "pkg/real.go" || !p.IsFileLevel || p.Line != 0 {
+		t.Errorf("expected real file to be promoted to file-level, got %+v", p)
+	}
+	if p.Text != "Line 4: real file outside diff" {
+		t.Errorf("expected line reference to be kept in text, got %q", p.Text)
+	}
+	if len(remaining) != 3 {
+		t.Errorf("expected 3 remaining comments (in-diff, hallucinated, escaping), got %d", len(remaining))
+	}
+}
+
+func TestValidateOutsideDiffPolicy(t *testing.T) {
+	for _, p := range []string{"", "drop", "summary", "verify", "VERIFY"} {
+		if err := ValidateOutsideDiffPolicy(p); err != nil {
+			t.Errorf("expected %q to be valid, got %v", p, err)
+		}
+	}
+	if err := ValidateOutsideDiffPolicy("ignore"); err == nil {
+		t.Error("expected error for invalid policy")
+	}
+}
@@ -0,0 +1,181 @@
+package review
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// Placeholders replaced in the review prompt template.
+const (
+	DiffPlaceholder          = "(DIFF_CONTENT_HERE)" // The raw unified diff
+	FormattedDiffPlaceholder = "{FORMATTED_DIFF}"    // The diff as formatted by FormatDiffForLLM
+	FileListPlaceholder      = "{FILE_LIST}"         // One "- path" line per changed file
+	PRTitlePlaceholder       = "{PR_TITLE}"          // The PR title (empty if unknown)
+	PRDescPlaceholder        = "{PR_DESCRIPTION}"    // The PR description (empty if unknown)
+)
+
+// PRInfo holds the PR details a template prompt can use; they are empty when the diff does not
+// come from a PR (e.g. a local review) or the provider does not supply them.
+type PRInfo struct {
+	Title       string
+	Description string
+}
+
+// PromptData is the data a template prompt is rendered with.
+type PromptData struct {
+	Diff          string   // The raw unified diff
+	FormattedDiff string   // The diff as formatted by FormatDiffForLLM
+	Files         []string // Paths of the changed files, in diff order
+	FileList      string   // One "- path" line per changed file, as for FileListPlaceholder
+	PRTitle       string
+	PRDescription string
+}
+
+// IsTemplatePrompt reports whether the prompt uses Go text/template syntax ("{{"), in which
+// case it is rendered with PromptData instead of having its placeholders replaced.
+func IsTemplatePrompt(prompt string) bool {
+	return strings.Contains(prompt, "{{")
+}
+
+// ValidatePromptTemplate returns an error if template does not contain every one of the
+// required placeholders. With none given, it requires DiffPlaceholder or
+// FormattedDiffPlaceholder. Without its placeholder the content would silently be left out
+// and the LLM would be asked to review nothing. A template prompt (see IsTemplatePrompt) must
+// instead parse, render with empty data, and use .Diff or .FormattedDiff; required is ignored.
+func ValidatePromptTemplate(template string, required ...string) error {
+	if IsTemplatePrompt(template) {
+		return validateTextTemplate(template)
+	}
+	if len(required) == 0 {
+		if strings.Contains(template, DiffPlaceholder) || strings.Contains(template, FormattedDiffPlaceholder) {
+			return nil
+		}
+		return fmt.Errorf("prompt template is missing the placeholder %s (or %s)", DiffPlaceholder, FormattedDiffPlaceholder)
+	}
+	var missing []string
+	for _, p := range required {
+		if !strings.Contains(template, p) {
+			missing = append(missing, p)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("prompt template is missing the placeholder(s) %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// validateTextTemplate checks a template prompt, catching syntax errors and unknown fields
+// before any diff is sent.
+func validateTextTemplate(prompt string) error {
+	tmpl, err := template.New("prompt").Parse(prompt)
+	if err != nil {
+		return fmt.Errorf("invalid prompt template: %w", err)
+	}
+	if err := tmpl.Execute(new(strings.Builder), PromptData{}); err != nil {
+		return fmt.Errorf("invalid prompt template: %w", err)
+	}
+	if !strings.Contains(prompt, ".Diff") && !strings.Contains(prompt, ".FormattedDiff") {
+		return fmt.Errorf("prompt template does not use .Diff or .FormattedDiff")
+	}
+	return nil
+}
+
+// RenderPrompt builds the review prompt for diff and pr. A template prompt (see
+// IsTemplatePrompt) is executed with PromptData; any other prompt has its placeholders
+// replaced as by BuildPrompt, including PRTitlePlaceholder and PRDescPlaceholder.
+func RenderPrompt(prompt, diff string, pr PRInfo) (string, error) {
+	if !IsTemplatePrompt(prompt) {
+		return buildPrompt(prompt, diff, pr), nil
+	}
+	tmpl, err := template.New("prompt").Parse(prompt)
+	if err != nil {
+		return "", fmt.Errorf("invalid prompt template: %w", err)
+	}
+	r := NewReview("", diff)
+	// On a parse error FormatDiffForLLM falls back to the raw diff and the file list is empty
+	_ = r.ParseDiff()
+	data := PromptData{
+		Diff:          diff,
+		FormattedDiff: r.FormatDiffForLLM(),
+		FileList:      formatFileList(r.Files),
+		PRTitle:       pr.Title,
+		PRDescription: pr.Description,
+	}
+	for _, f := range r.Files {
+		data.Files = append(data.Files, f.Path())
+	}
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("failed to render prompt template: %w", err)
+	}
+	return sb.String(), nil
+}
+
+// RenderPromptParts renders prompt like RenderPrompt, but returns the instructions ahead of the
+// diff separately from the rest, for providers that take instructions as a system prompt. The
+// prompt is split at the start of the first line holding DiffPlaceholder or
+// FormattedDiffPlaceholder; concatenating the parts gives RenderPrompt's result. Template
+// prompts are not split, and neither is a prompt that starts with the diff: instructions is
+// then empty.
+func RenderPromptParts(prompt, diff string, pr PRInfo) (instructions, content string, err error) {
+	split := -1
+	if !IsTemplatePrompt(prompt) {
+		for _, p := range []string{DiffPlaceholder, FormattedDiffPlaceholder} {
+			if i := strings.Index(prompt, p); i >= 0 && (split < 0 || i < split) {
+				split = i
+			}
+		}
+	}
+	if split > 0 {
+		split = strings.LastIndex(prompt[:split], "\n") + 1
+	}
+	if split <= 0 {
+		content, err := RenderPrompt(prompt, diff, pr)
+		return "", content, err
+	}
+	return buildPrompt(prompt[:split], diff, pr), buildPrompt(prompt[split:], diff, pr), nil
+}
+
+// BuildPrompt fills every known placeholder in the review prompt template from diff, leaving
+// the PR placeholders empty (see RenderPrompt). Unknown placeholders are left intact, and
+// placeholder-like text inside the diff or PR details is never substituted.
+func BuildPrompt(template, diff string) string {
+	return buildPrompt(template, diff, PRInfo{})
+}
+
+func buildPrompt(template, diff string, pr PRInfo) string {
+	pairs := []string{
+		DiffPlaceholder, diff,
+		PRTitlePlaceholder, pr.Title,
+		PRDescPlaceholder, pr.Description,
+	}
+	if strings.Contains(template, FormattedDiffPlaceholder) || strings.Contains(template, FileListPlaceholder) {
+		r := NewReview("", diff)
+		// On a parse error FormatDiffForLLM falls back to the raw diff and the file list is empty
+		_ = r.ParseDiff()
+		pairs = append(pairs,
+			FormattedDiffPlaceholder, r.FormatDiffForLLM(),
+			FileListPlaceholder, formatFileList(r.Files))
+	}
+	return strings.NewReplacer(pairs...).Replace(template)
+}
+
+// formatFileList lists the changed files, one "- path" line each, noting added, deleted, and
+// renamed files.
+func formatFileList(files []*DiffFile) string {
+	var sb strings.Builder
+	for _, f := range files {
+		fmt.Fprintf(&sb, "- %s", f.Path())
+		switch {
+		case f.IsNew:
+			sb.WriteString(" (added)")
+		case f.IsDeleted:
+			sb.WriteString(" (deleted)")
+		case f.IsRename:
+			fmt.Fprintf(&sb, " (renamed from %s)", f.OldPath)
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,172 @@
+package review
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidatePromptTemplate(t *testing.T) {
+	if err := ValidatePromptTemplate("Review this diff:\n" + DiffPlaceholder + "\n"); err != nil {
+		t.Errorf("unexpected error for a template with the placeholder: %v", err)
+	}
+	if err := ValidatePromptTemplate("Files:\n" + FileListPlaceholder + "\n" + FormattedDiffPlaceholder); err != nil {
+		t.Errorf("unexpected error for a template with only the formatted diff: %v", err)
+	}
+	err := ValidatePromptTemplate("Review these files:\n" + FileListPlaceholder + "\n")
+	if err == nil || !strings.Contains(err.Error(), DiffPlaceholder) {
+		t.Errorf("expected an error naming the missing placeholder, got %v", err)
+	}
+
+	// Custom placeholders, e.g. for prompts that also take file contents
+	if err := ValidatePromptTemplate("{DIFF_CONTENT} {FILE_CONTENTS}", "{DIFF_CONTENT}", "{FILE_CONTENTS}"); err != nil {
+		t.Errorf("unexpected error when all custom placeholders are present: %v", err)
+	}
+	err = ValidatePromptTemplate("{DIFF_CONTENT}", "{DIFF_CONTENT}", "{FILE_CONTENTS}")
+	if err == nil || !strings.Contains(err.Error(), "{FILE_CONTENTS}") || strings.Contains(err.Error(), "{DIFF_CONTENT},") {
+		t.Errorf("expected an error naming only {FILE_CONTENTS}, got %v", err)
+	}
+}
+
+func TestBuildPrompt(t *testing.T) {
+	got := BuildPrompt("Before\n"+DiffPlaceholder+"\nAfter "+DiffPlaceholder, "+added line")
+	if got != "Before\n+added line\nAfter +added line" {
+		t.Errorf("expected every placeholder to be replaced, got %q", got)
+	}
+}
+
+func TestBuildPrompt_MultiplePlaceholders(t *testing.T) {
+	diff := `diff --git a/main.go b/main.go
+--- a/main.go
++++ b/main.go
+@@ -1,2 +1,2 @@
+ package main
+-// {FILE_LIST}
++// {OTHER}
+diff --git a/new.go b/new.go
+new file mode 100644
+--- /dev/null
++++ b/new.go
+@@ -0,0 +1 @@
++package main
+`
+	template := "Files:\n{FILE_LIST}\nFormatted:\n{FORMATTED_DIFF}\nRaw:\n(DIFF_CONTENT_HERE)\nKeep {UNKNOWN}"
+	got := BuildPrompt(template, diff)
+
+	r := NewReview("", diff)
+	if err := r.ParseDiff(); err != nil {
+		t.Fatalf("ParseDiff failed: %v", err)
+	}
+	want := "Files:\n- main.go\n- new.go (added)\n\nFormatted:\n" + r.FormatDiffForLLM() + "\nRaw:\n" + diff + "\nKeep {UNKNOWN}"
+	if got != want {
+		t.Errorf("unexpected prompt:\n got: %q\nwant: %q", got, want)
+	}
+	// Placeholder-like text inside the diff must survive untouched
+	if !strings.Contains(got, "-// {FILE_LIST}") {
+		t.Errorf("expected placeholders inside the diff to be left alone, got %q", got)
+	}
+}
+
+func TestRenderPrompt_Template(t *testing.T) {
+	diff := "diff --git a/main.go b/main.go\n--- a/main.go\n+++ b/main.go\n@@ -1 +1 @@\n-a\n+b\n" +
+		"diff --git a/docs/new.md b/docs/new.md\nnew file mode 100644\n--- /dev/null\n+++ b/docs/new.md\n@@ -0,0 +1 @@\n+hello\n"
+	prompt := `PR: {{.PRTitle}}
+{{with .PRDescription}}Description: {{.}}
+{{end}}Files ({{len .Files}}):{{range .Files}} {{.}}{{end}}
+{{.FileList}}---
+{{.Diff}}`
+	if err := ValidatePromptTemplate(prompt); err != nil {
+		t.Fatalf("ValidatePromptTemplate failed: %v", err)
+	}
+	got, err := RenderPrompt(prompt, diff, PRInfo{Title: "Fix {{ braces }}", Description: "Swaps a for b."})
+	if err != nil {
+		t.Fatalf("RenderPrompt failed: %v", err)
+	}
+	want := "PR: Fix {{ braces }}\nDescription: Swaps a for b.\nFiles (2): main.go docs/new.md\n" +
+		"- main.go\n- docs/new.md (added)\n---\n" + diff
+	if got != want {
+		t.Errorf("unexpected prompt:\n%s\nwant:\n%s", got, want)
+	}
+
+	// Without a description the optional block is left out
+	got, err = RenderPrompt(prompt, diff, PRInfo{})
+	if err != nil || strings.Contains(got, "Description") {
+		t.Errorf("expected no description block, got %q (err %v)", got, err)
+	}
+
+	got, err = RenderPrompt("{{.FormattedDiff}}", diff, PRInfo{})
+	if err != nil || !strings.Contains(got, "main.go") || got == diff {
+		t.Errorf("expected the formatted diff, got %q (err %v)", got, err)
+	}
+}
+
+func TestRenderPrompt_Legacy(t *testing.T) {
+	got, err := RenderPrompt("Review:\n"+DiffPlaceholder, "+x", PRInfo{Title: "ignored"})
+	if err != nil || got != "Review:\n+x" {
+		t.Errorf("expected the legacy placeholder to be replaced, got %q (err %v)", got, err)
+	}
+}
+
+func TestRenderPromptParts(t *testing.T) {
+	prompt := "Review {PR_TITLE}.\nBe strict.\nDiff: " + DiffPlaceholder + "\nReply in sections."
+	instructions, content, err := RenderPromptParts(prompt, "+x", PRInfo{Title: "Fix"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if instructions != "Review Fix.\nBe strict.\n" || content != "Diff: +x\nReply in sections." {
+		t.Errorf("unexpected split: instructions %q, content %q", instructions, content)
+	}
+	whole, _ := RenderPrompt(prompt, "+x", PRInfo{Title: "Fix"})
+	if instructions+content != whole {
+		t.Errorf("expected the parts to add up to RenderPrompt's result %q", whole)
+	}
+
+	for _, unsplit := range []string{DiffPlaceholder + "\nReview this.", "Review:\n{{.Diff}}"} {
+		instructions, content, err := RenderPromptParts(unsplit, "+x", PRInfo{})
+		whole, _ := RenderPrompt(unsplit, "+x", PRInfo{})
+		if err != nil || instructions != "" || content != whole {
+			t.Errorf("expected %q not to be split, got %q, %q (err %v)", unsplit, instructions, content, err)
+		}
+	}
+}
+
+func TestValidatePromptTemplate_Template(t *testing.T) {
+	tests := []struct {
+		name    string
+		prompt  string
+		wantErr string
+	}{
+		{"syntax error", "{{.Diff", "invalid prompt template"},
+		{"unknown field", "{{.Diff}} {{.Author}}", "Author"},
+		{"no diff", "Title: {{.PRTitle}}", ".Diff"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidatePromptTemplate(tt.prompt)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+	if !IsTemplatePrompt("{{.Diff}}") || IsTemplatePrompt(DiffPlaceholder+" {FILE_LIST}") {
+		t.Error("IsTemplatePrompt misdetects template syntax")
+	}
+}
+
+func TestRenderPrompt_PRPlaceholders(t *testing.T) {
+	prompt := "Title: " + PRTitlePlaceholder + "\nDescription:\n" + PRDescPlaceholder + "\n\n" + DiffPlaceholder
+	// Placeholder-like text in the PR details is not substituted again
+	pr := PRInfo{Title: "Add retries", Description: "Retries 5xx responses. See " + DiffPlaceholder + "."}
+	got, err := RenderPrompt(prompt, "+retry()", pr)
+	if err != nil {
+		t.Fatalf("RenderPrompt failed: %v", err)
+	}
+	want := "Title: Add retries\nDescription:\nRetries 5xx responses. See (DIFF_CONTENT_HERE).\n\n+retry()"
+	if got != want {
+		t.Errorf("unexpected prompt:\n%q\nwant:\n%q", got, want)
+	}
+
+	// Without PR details the placeholders are emptied
+	if got := BuildPrompt(prompt, "+retry()"); got != "Title: \nDescription:\n\n\n+retry()" {
+		t.Errorf("expected empty PR placeholders, got %q", got)
+	}
+}
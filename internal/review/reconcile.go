@@ -0,0 +1,172 @@
+package review
+
+// ReconcileAction is the lifecycle decision for a comment on a re-run.
+type ReconcileAction int
+
+const (
+	// ActionPost posts a comment that has not been posted before.
+	ActionPost ReconcileAction = iota
+	// ActionKeep leaves an already-posted comment untouched.
+	ActionKeep
+	// ActionUpdate edits an already-posted comment in place because its text changed.
+	ActionUpdate
+	// ActionRepost posts the comment at its new line and resolves the old one, because the
+	// anchored code moved (e.g. after a force-push) and inline anchors cannot be edited.
+	ActionRepost
+	// ActionResolve resolves a previously posted comment whose anchored code no longer exists.
+	ActionResolve
+)
+
+func (a ReconcileAction) String() string {
+	switch a {
+	case ActionPost:
+		return "post"
+	case ActionKeep:
+		return "keep"
+	case ActionUpdate:
+		return "update"
+	case ActionRepost:
+		return "repost"
+	case ActionResolve:
+		return "resolve"
+	default:
+		return "unknown"
+	}
+}
+
+// PostedComment is a comment previously posted by pullreview, recovered from its marker.
+type PostedComment struct {
+	ID       string
+	Text     string // Visible text, without the marker
+	Marker   Marker
+	Resolved bool // Resolved threads are never reopened, updated, or resolved again
+}
+
+// ReconcileStep is a single decision produced by Reconcile.
+type ReconcileStep struct {
+	Action   ReconcileAction
+	Comment  Comment        // The new comment (zero for ActionResolve)
+	Marker   Marker         // Marker to embed when posting or updating
+	Existing *PostedComment // The posted comment acted upon (nil for ActionPost)
+}
+
+// Reconcile compares the comments produced by this run against those already posted and decides,
+// for each, whether to post, keep, update, repost, or resolve. Inline comments are identified by
+// path and content hash, so they survive line shifts and force-pushes; file-level comments by path
+// alone, so a reworded finding is updated rather than duplicated. Steps for new comments come
+// first, in input order, followed by resolutions in the order the posted comments were given.
+// Summary comments are not reconciled and are ignored.
+func Reconcile(comments []Comment, files []*DiffFile, commit string, posted []PostedComment) []ReconcileStep {
+	type key struct {
+		path, hash string
+		fileLevel  bool
+	}
+	keyOf := func(m Marker) key {
+		if m.isFileLevel() {
+			// Older runs hashed the text of file-level comments; match them on path too
+			return key{path: m.Path, fileLevel: true}
+		}
+		return key{path: m.Path, hash: m.Hash}
+	}
+	byKey := make(map[key][]*PostedComment)
+	for i := range posted {
+		p := &posted[i]
+		if p.Marker.Summary {
+			continue
+		}
+		byKey[keyOf(p.Marker)] = append(byKey[keyOf(p.Marker)], p)
+	}
+	claimed := make(map[*PostedComment]bool)
+
+	var steps []ReconcileStep
+	for _, c := range comments {
+		m := NewMarker(c, files, commit)
+		candidates := byKey[keyOf(m)]
+		var existing *PostedComment
+		// Prefer an unclaimed comment with the same text at the same line, then one at the same
+		// line, then any unclaimed one
+		for _, p := range candidates {
+			if !claimed[p] && p.Marker.Line == m.Line && p.Marker.OldLine == m.OldLine && p.Text == c.Text {
+				existing = p
+				break
+			}
+		}
+		if existing == nil {
+			for _, p := range candidates {
+				if !claimed[p] && p.Marker.Line == m.Line && p.Marker.OldLine == m.OldLine {
+					existing = p
+					break
+				}
+			}
+		}
+		if existing == nil {
+			for _, p := range candidates {
+				if !claimed[p] {
+					existing = p
+					break
+				}
+			}
+		}
+		if existing == nil {
+			steps = append(steps, ReconcileStep{Action: ActionPost, Comment: c, Marker: m})
+			continue
+		}
+		claimed[existing] = true
+		action := ActionKeep
+		switch {
+		case existing.Resolved:
+			// Someone already dealt with this finding; don't nag
+		case existing.Marker.Line != m.Line || existing.Marker.OldLine != m.OldLine:
+			action = ActionRepost
+		case existing.Text != c.Text:
+			action = ActionUpdate
+		}
+		steps = append(steps, ReconcileStep{Action: action, Comment: c, Marker: m, Existing: existing})
+	}
+
+	for i := range posted {
+		p := &posted[i]
+		if claimed[p] || p.Marker.Summary {
+			continue
+		}
+		action := ActionResolve
+		if p.Resolved || anchorStillExists(p.Marker, files) {
+			action = ActionKeep
+		}
+		steps = append(steps, ReconcileStep{Action: action, Existing: p})
+	}
+	return steps
+}
+
+// anchorStillExists reports whether the code a posted comment was anchored to is still part of
+// the diff. File-level comments stay valid while their file is in the diff.
+func anchorStillExists(m Marker, files []*DiffFile) bool {
+	for _, f := range files {
+		if f.Path() != m.Path {
+			continue
+		}
+		if m.isFileLevel() {
+			return true
+		}
+		for _, h := range f.Hunks {
+			for _, hl := range h.LineMapping {
+				// Deleted-line anchors only match deleted lines, and vice versa
+				if (hl.Type == DeletionLine) == (m.OldLine > 0) && ContentHash(diffLineText(hl.Content)) == m.Hash {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// FindSummary returns the most recently posted summary comment, or nil if there is none.
+// posted must be in posting order, as ListComments returns them.
+func FindSummary(posted []PostedComment) *PostedComment {
+	for i := len(posted) - 1; i >= 0; i-- {
+		if posted[i].Marker.Summary {
+			return &posted[i]
+		}
+	}
+	return nil
+}
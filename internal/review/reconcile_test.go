@@ -0,0 +1,123 @@
+package review
+
+import (
+	"strings"
+	"testing"
+)
+
+// reconcileFixture returns a diff in which "var x = 1" has moved from line 2 to line 3.
+func reconcileFixture(t *testing.T) []*DiffFile {
+	t.Helper()
+	files, err := ParseUnifiedDiff(strings.Join([]string{
+		"diff --git a/foo.go b/foo.go",
+		"@@ -1,2 +1,5 @@",
+		" package foo",
+		"+// moved down",
+		"+var x = 1",
+		"+var y = 2",
+		" func f() {}",
+	}, "\n"))
+	if err != nil {
+		t.Fatalf("failed to parse diff: %v", err)
+	}
+	return files
+}
+
+func posted(id, path string, line int, content, text string) PostedComment {
+	return PostedComment{
+		ID:     id,
+		Text:   text,
+		Marker: Marker{Commit: "old", Path: path, Line: line, Hash: ContentHash(content)},
+	}
+}
+
+func TestReconcile_Decisions(t *testing.T) {
+	files := reconcileFixture(t)
+	comments := []Comment{
+		{FilePath: "foo.go", Line: 3, Text: "x is unused"},           // same text, moved from line 2
+		{FilePath: "foo.go", Line: 4, Text: "y shadows a global"},    // same line, new wording
+		{FilePath: "foo.go", Line: 2, Text: "comment is misleading"}, // never posted
+		{FilePath: "foo.go", Text: "no tests", IsFileLevel: true},    // unchanged file-level
+	}
+	existing := []PostedComment{
+		posted("1", "foo.go", 2, "var x = 1", "x is unused"),
+		posted("2", "foo.go", 4, "var y = 2", "y is bad"),
+		posted("3", "foo.go", 0, "no tests", "no tests"),
+		posted("4", "foo.go", 7, "var z = 3", "z removed"),    // code gone
+		posted("5", "foo.go", 1, "package foo", "pkg name"),   // not re-raised, code still there
+		posted("6", "gone.go", 0, "whatever", "file dropped"), // file no longer in diff
+	}
+
+	steps := Reconcile(comments, files, "new", existing)
+	want := []struct {
+		action ReconcileAction
+		id     string
+	}{
+		{ActionRepost, "1"},
+		{ActionUpdate, "2"},
+		{ActionPost, ""},
+		{ActionKeep, "3"},
+		{ActionResolve, "4"},
+		{ActionKeep, "5"},
+		{ActionResolve, "6"},
+	}
+	if len(steps) != len(want) {
+		t.Fatalf("expected %d steps, got %d: %+v", len(want), len(steps), steps)
+	}
+	for i, w := range want {
+		s := steps[i]
+		if s.Action != w.action {
+			t.Errorf("step %d: expected %s, got %s", i, w.action, s.Action)
+		}
+		id := ""
+		if s.Existing != nil {
+			id = s.Existing.ID
+		}
+		if id != w.id {
+			t.Errorf("step %d: expected existing comment %q, got %q", i, w.id, id)
+		}
+	}
+	if steps[0].Marker.Commit != "new" || steps[0].Marker.Line != 3 {
+		t.Errorf("expected repost marker for new commit and line, got %+v", steps[0].Marker)
+	}
+}
+
+func TestReconcile_ResolvedCommentsLeftAlone(t *testing.T) {
+	files := reconcileFixture(t)
+	resolvedMoved := posted("1", "foo.go", 2, "var x = 1", "x is unused")
+	resolvedMoved.Resolved = true
+	resolvedGone := posted("2", "foo.go", 9, "var z = 3", "z removed")
+	resolvedGone.Resolved = true
+
+	steps := Reconcile([]Comment{{FilePath: "foo.go", Line: 3, Text: "x is unused"}}, files, "new",
+		[]PostedComment{resolvedMoved, resolvedGone})
+	for _, s := range steps {
+		if s.Action != ActionKeep {
+			t.Errorf("expected resolved comment %s to be kept, got %s", s.Existing.ID, s.Action)
+		}
+	}
+}
+
+func TestReconcile_FileLevelMatchedByPath(t *testing.T) {
+	files := reconcileFixture(t)
+	summary := PostedComment{ID: "9", Text: "Overall fine.", Marker: SummaryMarker("old")}
+	steps := Reconcile([]Comment{{FilePath: "foo.go", Text: "no tests at all", IsFileLevel: true}}, files, "new",
+		[]PostedComment{posted("1", "foo.go", 0, "no tests", "no tests"), summary})
+	if len(steps) != 1 {
+		t.Fatalf("expected one step (summary ignored), got %+v", steps)
+	}
+	if steps[0].Action != ActionUpdate || steps[0].Existing.ID != "1" {
+		t.Errorf("expected reworded file-level comment to update comment 1, got %s %+v", steps[0].Action, steps[0].Existing)
+	}
+	if got := FindSummary([]PostedComment{summary, posted("1", "foo.go", 0, "no tests", "no tests")}); got == nil || got.ID != "9" {
+		t.Errorf("expected FindSummary to find comment 9, got %+v", got)
+	}
+}
+
+func TestReconcile_NothingPosted(t *testing.T) {
+	files := reconcileFixture(t)
+	steps := Reconcile([]Comment{{FilePath: "foo.go", Line: 3, Text: "x"}}, files, "sha", nil)
+	if len(steps) != 1 || steps[0].Action != ActionPost {
+		t.Fatalf("expected a single post step, got %+v", steps)
+	}
+}
@@ -1,7 +1,9 @@
 package review
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"regexp"
 	"strconv"
@@ -15,7 +17,21 @@ type Review struct {
 	Comments []Comment
 	Summary  string
 
-	Files []*DiffFile // Parsed diff files
+	Files   []*DiffFile // Parsed diff files
+	Dropped []string    // Files left out because the diff was over the size limit (see Run)
+
+	Matched   []Comment // Comments placed on the diff (see MatchComments)
+	Unmatched []Comment // Comments that could not be placed on the diff
+
+	Tokens int // LLM tokens used across all requests, if the provider reports them
+}
+
+// Result is the outcome of a review, split the way it is presented and posted.
+type Result struct {
+	Inline    []Comment // Matched comments anchored to a line
+	FileLevel []Comment // Matched file-level comments
+	Unmatched []Comment // Comments that could not be placed on the diff
+	Summary   string    // The LLM summary followed by the unmatched comments as bullet points
 }
 
 // ParseLLMResponse parses the LLM response into inline comments and a summary.
@@ -23,19 +39,76 @@ func (r *Review) ParseLLMResponse(llmResp string) {
 	r.Comments, r.Summary = ParseLLMResponse(llmResp)
 }
 
+// MatchComments places r.Comments on the parsed diff, filling r.Matched and r.Unmatched. See
+// MatchCommentsToDiffWithTolerance for the meaning of tolerance.
+func (r *Review) MatchComments(tolerance int) {
+	r.Matched, r.Unmatched = MatchCommentsToDiffWithTolerance(r.Comments, r.Files, tolerance)
+}
+
+// Result splits r.Matched into inline and file-level comments and composes the summary with
+// r.Unmatched (see ComposeSummary). Comments keep their order.
+func (r *Review) Result() Result {
+	res := Result{
+		Unmatched: r.Unmatched,
+		Summary:   ComposeSummary(r.Summary, r.Unmatched),
+	}
+	for _, c := range r.Matched {
+		if c.IsFileLevel {
+			res.FileLevel = append(res.FileLevel, c)
+		} else {
+			res.Inline = append(res.Inline, c)
+		}
+	}
+	return res
+}
+
+// SummaryOnly returns the result reduced to its summary comment, for reviews that post no
+// inline or file-level comments. With fold, those comments are appended to the summary as
+// bullet points after the unmatched ones; otherwise they are dropped.
+func (res Result) SummaryOnly(fold bool) Result {
+	out := Result{Unmatched: res.Unmatched, Summary: res.Summary}
+	if fold {
+		out.Summary = ComposeSummary(strings.TrimRight(res.Summary, "\n"), res.Comments())
+	}
+	return out
+}
+
 // Comment represents an inline or file-level comment to be posted on a PR.
 type Comment struct {
 	FilePath    string
 	Line        int
+	OldLine     int // Old-file line for comments on deleted lines; used when Line is 0
 	Text        string
 	IsFileLevel bool
+	Category    string // Optional finding category (e.g. bug, security, perf, style)
+	Severity    string // Optional finding severity (critical, high, medium, low, info)
+	SnappedFrom int    // Line given by the LLM when Line was snapped to a nearby added line; 0 otherwise
+	Suggestion  string // Replacement code from a ```suggestion block in Text; empty if none
 }
 
 // DiffFile represents a file changed in the diff, with its hunks.
 type DiffFile struct {
-	OldPath string
-	NewPath string
-	Hunks   []*DiffHunk
+	OldPath   string // Empty for added files
+	NewPath   string // Empty for deleted files
+	Hunks     []*DiffHunk
+	IsRename  bool // OldPath was renamed to NewPath ("rename from"/"rename to")
+	IsCopy    bool // NewPath was copied from OldPath ("copy from"/"copy to")
+	IsNew     bool // The file was added ("new file mode" or "--- /dev/null")
+	IsDeleted bool // The file was deleted ("deleted file mode" or "+++ /dev/null")
+}
+
+// Path returns the path comments refer to: the new path, or the old path for deleted files.
+func (f *DiffFile) Path() string {
+	if f.NewPath != "" {
+		return f.NewPath
+	}
+	return f.OldPath
+}
+
+// hasChanges reports whether the file carries anything worth reviewing: content hunks, or a
+// rename, copy, addition, or deletion (which may have no hunks, e.g. for empty files).
+func (f *DiffFile) hasChanges() bool {
+	return len(f.Hunks) > 0 || f.IsRename || f.IsCopy || f.IsNew || f.IsDeleted
 }
 
 // DiffHunk represents a hunk in the diff (a contiguous block of changes).
@@ -66,16 +139,64 @@ const (
 	DeletionLine
 )
 
+// Location renders where the comment points: "file", "file:line", or "file:-line (deleted)"
+// for comments on deleted lines.
+func (c Comment) Location() string {
+	switch {
+	case c.IsFileLevel:
+		return c.FilePath
+	case c.Line <= 0 && c.OldLine > 0:
+		return fmt.Sprintf("%s:-%d (deleted)", c.FilePath, c.OldLine)
+	default:
+		return fmt.Sprintf("%s:%d", c.FilePath, c.Line)
+	}
+}
+
+// PlainSuggestionText returns the comment text with its ```suggestion block replaced by the
+// Suggestion under a "Suggested change" label, for code hosts that cannot apply suggestions
+// (Bitbucket renders the block as plain code with no hint of what it is). GitHub and GitLab
+// apply ```suggestion blocks natively and should be given Text as-is.
+func (c Comment) PlainSuggestionText() string {
+	if c.Suggestion == "" {
+		return c.Text
+	}
+	lines := strings.Split(c.Text, "\n")
+	for i, l := range lines {
+		if !strings.HasPrefix(strings.TrimSpace(l), "```suggestion") {
+			continue
+		}
+		end := len(lines)
+		for j := i + 1; j < len(lines); j++ {
+			if strings.HasPrefix(strings.TrimSpace(lines[j]), "```") {
+				end = j + 1
+				break
+			}
+		}
+		out := append([]string{}, lines[:i]...)
+		out = append(out, "**Suggested change:**", "```", c.Suggestion, "```")
+		return strings.Join(append(out, lines[end:]...), "\n")
+	}
+	return c.Text
+}
+
 // MatchCommentsToDiff checks each comment against the parsed diff files and returns two slices:
 // - matched: comments that correspond to a real file and (for inline) line in the diff
 // - unmatched: comments that do not match any file/line in the diff
 //
-// For inline comments, the file must exist and the line must be present as a new line in the diff.
-// For file-level comments, only the file must exist.
+// For inline comments, the file must exist and the line must be present as a new line in the diff
+// (or, for comments with OldLine set, as a deleted line). For file-level comments, only the file must exist.
 func MatchCommentsToDiff(comments []Comment, files []*DiffFile) (matched []Comment, unmatched []Comment) {
+	return MatchCommentsToDiffWithTolerance(comments, files, 0)
+}
+
+// MatchCommentsToDiffWithTolerance works like MatchCommentsToDiff, but an inline comment whose
+// line is not an added line is snapped to the nearest added line of the same file at most
+// tolerance lines away (the earlier line wins ties), recording the original line in
+// SnappedFrom. A tolerance of zero or less is strict matching.
+func MatchCommentsToDiffWithTolerance(comments []Comment, files []*DiffFile, tolerance int) (matched []Comment, unmatched []Comment) {
 	fileMap := make(map[string]*DiffFile)
 	for _, f := range files {
-		fileMap[f.NewPath] = f
+		fileMap[f.Path()] = f
 	}
 
 	for _, c := range comments {
@@ -88,11 +209,26 @@ func MatchCommentsToDiff(comments []Comment, files []*DiffFile) (matched []Comme
 			matched = append(matched, c)
 			continue
 		}
-		// Inline comment: check if line exists as a new line in the diff
+		// Inline comment: check if line exists as a new line (or deleted old line) in the diff,
+		// remembering the nearest added line in case the LLM's line number is slightly off
 		found := false
+		nearest, nearestDist := 0, tolerance+1
 		for _, h := range file.Hunks {
 			for _, hl := range h.LineMapping {
-				if hl.Type == AdditionLine && hl.NewLine == c.Line {
+				if c.Line > 0 && hl.Type == AdditionLine {
+					if hl.NewLine == c.Line {
+						found = true
+						break
+					}
+					dist := hl.NewLine - c.Line
+					if dist < 0 {
+						dist = -dist
+					}
+					if dist < nearestDist || (dist == nearestDist && hl.NewLine < nearest) {
+						nearest, nearestDist = hl.NewLine, dist
+					}
+				}
+				if c.Line <= 0 && c.OldLine > 0 && hl.Type == DeletionLine && hl.OldLine == c.OldLine {
 					found = true
 					break
 				}
@@ -101,15 +237,64 @@ func MatchCommentsToDiff(comments []Comment, files []*DiffFile) (matched []Comme
 				break
 			}
 		}
-		if found {
+		switch {
+		case found:
 			matched = append(matched, c)
-		} else {
+		case nearest > 0:
+			c.SnappedFrom, c.Line = c.Line, nearest
+			matched = append(matched, c)
+		default:
 			unmatched = append(unmatched, c)
 		}
 	}
 	return matched, unmatched
 }
 
+// ComposeSummary appends the given comments to the summary as bullet points (no heading),
+// grouped by category so related findings appear together.
+func ComposeSummary(summary string, extra []Comment) string {
+	if len(extra) == 0 {
+		return summary
+	}
+	var b strings.Builder
+	if summary != "" {
+		b.WriteString(summary)
+		b.WriteString("\n\n")
+	}
+	for _, group := range GroupByCategory(extra) {
+		for _, cmt := range group.Comments {
+			b.WriteString("- ")
+			if group.Category != "" {
+				b.WriteString(fmt.Sprintf("**%s** ", group.Category))
+			}
+			b.WriteString(fmt.Sprintf("[%s] %s\n", cmt.Location(), cmt.Text))
+		}
+	}
+	return b.String()
+}
+
+// ReadDiff reads a unified diff supplied by the user (a file or stdin) instead of fetched from
+// Bitbucket. CRLF line endings are normalized, and input that contains no file diffs is
+// rejected so a wrong file is not silently reviewed as empty.
+func ReadDiff(r io.Reader) (string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return "", fmt.Errorf("failed to read diff: %w", err)
+	}
+	diff := strings.ReplaceAll(string(data), "\r\n", "\n")
+	if strings.TrimSpace(diff) == "" {
+		return "", errors.New("diff is empty")
+	}
+	files, err := ParseUnifiedDiff(diff)
+	if err != nil {
+		return "", err
+	}
+	if len(files) == 0 {
+		return "", errors.New("input is not a unified diff (no file changes found)")
+	}
+	return diff, nil
+}
+
 // NewReview creates a new Review instance.
 func NewReview(prID, diff string) *Review {
 	return &Review{
@@ -135,12 +320,11 @@ func ParseUnifiedDiff(diff string) ([]*DiffFile, error) {
 	var currentHunk *DiffHunk
 
 	lines := strings.Split(diff, "\n")
-	fileHeaderRegex := regexp.MustCompile(`^diff --git a/(.+) b/(.+)$`)
 	hunkHeaderRegex := regexp.MustCompile(`^@@ -(\d+),?(\d*) \+(\d+),?(\d*) @@`)
 
 	for i := 0; i < len(lines); i++ {
 		line := lines[i]
-		if matches := fileHeaderRegex.FindStringSubmatch(line); matches != nil {
+		if strings.HasPrefix(line, "diff --git ") {
 			// Start of a new file diff
 			if currentFile != nil {
 				// Save previous file
@@ -148,16 +332,20 @@ func ParseUnifiedDiff(diff string) ([]*DiffFile, error) {
 					currentFile.Hunks = append(currentFile.Hunks, currentHunk)
 					currentHunk = nil
 				}
-				if len(currentFile.Hunks) > 0 {
+				if currentFile.hasChanges() {
 					files = append(files, currentFile)
 				}
 			}
+			oldPath, newPath := parseGitDiffHeader(strings.TrimPrefix(line, "diff --git "))
 			currentFile = &DiffFile{
-				OldPath: matches[1],
-				NewPath: matches[2],
+				OldPath: oldPath,
+				NewPath: newPath,
 			}
 			continue
 		}
+		if currentFile != nil && currentHunk == nil && parseExtendedHeader(currentFile, line) {
+			continue
+		}
 		if strings.HasPrefix(line, "@@ ") {
 			// Start of a new hunk
 			if currentHunk != nil && currentFile != nil {
@@ -234,13 +422,105 @@ func ParseUnifiedDiff(diff string) ([]*DiffFile, error) {
 		if currentHunk != nil {
 			currentFile.Hunks = append(currentFile.Hunks, currentHunk)
 		}
-		if len(currentFile.Hunks) > 0 {
+		if currentFile.hasChanges() {
 			files = append(files, currentFile)
 		}
 	}
 	return files, nil
 }
 
+// parseGitDiffHeader extracts the old and new paths from the part of a "diff --git" line after
+// the command. git quotes paths containing special characters ("a/caf\303\251.go") but not
+// paths that merely contain spaces, so an unquoted header is split where the two halves name
+// the same file; renames with spaces are resolved later from the ---/+++ or rename lines.
+func parseGitDiffHeader(rest string) (oldPath, newPath string) {
+	var a, b string
+	switch {
+	case strings.HasPrefix(rest, `"`):
+		end := closingQuote(rest)
+		a = rest[:end+1]
+		b = strings.TrimPrefix(rest[end+1:], " ")
+	case strings.HasSuffix(rest, `"`) && strings.LastIndex(rest, ` "b/`) > 0:
+		i := strings.LastIndex(rest, ` "b/`)
+		a, b = rest[:i], rest[i+1:]
+	default:
+		// Same path on both sides: "a/X b/X"
+		if n := len(rest); n%2 == 1 {
+			half := (n - 1) / 2
+			if rest[half] == ' ' && strings.HasPrefix(rest, "a/") && rest[half+1:half+3] == "b/" && rest[2:half] == rest[half+3:] {
+				return rest[2:half], rest[half+3:]
+			}
+		}
+		if i := strings.Index(rest, " b/"); i >= 0 {
+			a, b = rest[:i], rest[i+1:]
+		} else {
+			a, b = rest, rest
+		}
+	}
+	return strings.TrimPrefix(unquoteGitPath(a), "a/"), strings.TrimPrefix(unquoteGitPath(b), "b/")
+}
+
+// closingQuote returns the index of the quote that closes the quoted string starting at s[0],
+// or len(s)-1 if it is unterminated.
+func closingQuote(s string) int {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return len(s) - 1
+}
+
+// unquoteGitPath decodes a path git wrote in C-style quotes (with octal escapes for non-ASCII
+// bytes); unquoted paths are returned unchanged.
+func unquoteGitPath(s string) string {
+	if len(s) < 2 || s[0] != '"' || s[len(s)-1] != '"' {
+		return s
+	}
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		return unquoted
+	}
+	return s[1 : len(s)-1]
+}
+
+// parseExtendedHeader applies a git extended header line (rename/copy metadata, added or
+// deleted file markers) to f, and reports whether the line was one. The paths in rename and
+// copy lines are authoritative: unlike the "diff --git" line they are not ambiguous when paths
+// contain spaces.
+func parseExtendedHeader(f *DiffFile, line string) bool {
+	switch {
+	case strings.HasPrefix(line, "new file mode "), line == "--- /dev/null":
+		f.OldPath = ""
+		f.IsNew = true
+	case strings.HasPrefix(line, "deleted file mode "), line == "+++ /dev/null":
+		f.NewPath = ""
+		f.IsDeleted = true
+	case strings.HasPrefix(line, "--- "):
+		// git appends a tab to names containing spaces
+		f.OldPath = strings.TrimPrefix(unquoteGitPath(strings.TrimSuffix(line[4:], "\t")), "a/")
+	case strings.HasPrefix(line, "+++ "):
+		f.NewPath = strings.TrimPrefix(unquoteGitPath(strings.TrimSuffix(line[4:], "\t")), "b/")
+	case strings.HasPrefix(line, "rename from "):
+		f.OldPath = unquoteGitPath(strings.TrimPrefix(line, "rename from "))
+		f.IsRename = true
+	case strings.HasPrefix(line, "rename to "):
+		f.NewPath = unquoteGitPath(strings.TrimPrefix(line, "rename to "))
+		f.IsRename = true
+	case strings.HasPrefix(line, "copy from "):
+		f.OldPath = unquoteGitPath(strings.TrimPrefix(line, "copy from "))
+		f.IsCopy = true
+	case strings.HasPrefix(line, "copy to "):
+		f.NewPath = unquoteGitPath(strings.TrimPrefix(line, "copy to "))
+		f.IsCopy = true
+	default:
+		return false
+	}
+	return true
+}
+
 // FormatDiffForLLM returns a string representation of the parsed diff with clear file and hunk context for LLM input.
 func (r *Review) FormatDiffForLLM() string {
 	if len(r.Files) == 0 {
@@ -248,7 +528,7 @@ func (r *Review) FormatDiffForLLM() string {
 	}
 	var sb strings.Builder
 	for _, f := range r.Files {
-		sb.WriteString(fmt.Sprintf("File: %s\n", f.NewPath))
+		sb.WriteString(fmt.Sprintf("File: %s\n", f.Path()))
 		for _, h := range f.Hunks {
 			sb.WriteString(fmt.Sprintf("  %s\n", h.Header))
 			for _, hl := range h.LineMapping {
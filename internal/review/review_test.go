@@ -26,6 +26,21 @@ index 1234567..89abcde 100644
  }
 `
 
+func TestReadDiff(t *testing.T) {
+	got, err := ReadDiff(strings.NewReader(strings.ReplaceAll(sampleDiff, "\n", "\r\n")))
+	if err != nil {
+		t.Fatalf("ReadDiff failed: %v", err)
+	}
+	if got != sampleDiff {
+		t.Errorf("expected CRLF normalized to the sample diff, got:\n%q", got)
+	}
+	for _, input := range []string{"", "  \n", "just some notes\n"} {
+		if _, err := ReadDiff(strings.NewReader(input)); err == nil {
+			t.Errorf("ReadDiff(%q) expected error, got nil", input)
+		}
+	}
+}
+
 func TestParseUnifiedDiff_Simple(t *testing.T) {
 	files, err := ParseUnifiedDiff(sampleDiff)
 	if err != nil {
@@ -287,3 +302,465 @@ index 1234567..89abcde 100644
 		}
 	}
 }
+
+func TestMatchCommentsToDiffWithTolerance(t *testing.T) {
+	diff := `diff --git a/foo.go b/foo.go
+index 1234567..89abcde 100644
+--- a/foo.go
++++ b/foo.go
+@@ -1,6 +1,7 @@
+ package main
+
+-func hello() {
+-    println("Hello, world!")
++func hello(name string) {
++    println("Hello,", name)
+ }
++
+@@ -10,7 +11,8 @@
+ func bye() {
+-    println("Bye!")
++    println("Goodbye!")
++    println("See you soon!")
+ }
+`
+	files, err := ParseUnifiedDiff(diff)
+	if err != nil {
+		t.Fatalf("ParseUnifiedDiff failed: %v", err)
+	}
+	// Added lines are 3, 4, 6, 12 and 13
+	comments := []Comment{
+		{FilePath: "foo.go", Line: 4, Text: "exact"},
+		{FilePath: "foo.go", Line: 2, Text: "off by one"},
+		{FilePath: "foo.go", Line: 5, Text: "tie"},
+		{FilePath: "foo.go", Line: 9, Text: "too far"},
+		{FilePath: "foo.go", OldLine: 3, Text: "deleted line"},
+	}
+
+	matched, unmatched := MatchCommentsToDiffWithTolerance(comments, files, 2)
+	want := map[string][2]int{
+		"exact":        {4, 0},
+		"off by one":   {3, 2},
+		"tie":          {4, 5},
+		"deleted line": {0, 0},
+	}
+	if len(matched) != len(want) {
+		t.Fatalf("expected %d matched comments, got %+v", len(want), matched)
+	}
+	for _, c := range matched {
+		w, ok := want[c.Text]
+		if !ok {
+			t.Errorf("unexpected matched comment %q", c.Text)
+			continue
+		}
+		if c.Line != w[0] || c.SnappedFrom != w[1] {
+			t.Errorf("%q: got line %d (snapped from %d), want line %d (snapped from %d)", c.Text, c.Line, c.SnappedFrom, w[0], w[1])
+		}
+	}
+	if len(unmatched) != 1 || unmatched[0].Text != "too far" || unmatched[0].Line != 9 || unmatched[0].SnappedFrom != 0 {
+		t.Errorf("expected only the too-far comment to be unmatched and unchanged, got %+v", unmatched)
+	}
+
+	// Strict matching leaves off-by-one comments unmatched
+	matched, unmatched = MatchCommentsToDiffWithTolerance(comments, files, 0)
+	if len(matched) != 2 || len(unmatched) != 3 {
+		t.Errorf("expected 2 matched and 3 unmatched comments in strict mode, got %d and %d", len(matched), len(unmatched))
+	}
+}
+
+func TestMatchCommentsToDiff_DeletedLines(t *testing.T) {
+	diff := `diff --git a/foo.go b/foo.go
+--- a/foo.go
++++ b/foo.go
+@@ -1,5 +1,4 @@
+ func load(p string) {
+-	if p == "" {
+-		return
+-	}
++	read(p)
+ }
+`
+	files, err := ParseUnifiedDiff(diff)
+	if err != nil {
+		t.Fatalf("ParseUnifiedDiff failed: %v", err)
+	}
+	comments := []Comment{
+		{FilePath: "foo.go", OldLine: 2, Text: "You deleted the empty-path check"}, // deleted line
+		{FilePath: "foo.go", Line: 2, Text: "Unchecked read"},                      // added line
+		{FilePath: "foo.go", OldLine: 1, Text: "Context, not deleted"},             // context line
+		{FilePath: "foo.go", OldLine: 9, Text: "Out of range"},
+	}
+	matched, unmatched := MatchCommentsToDiff(comments, files)
+	if len(matched) != 2 || matched[0].OldLine != 2 || matched[1].Line != 2 {
+		t.Errorf("expected the deleted-line and added-line comments to match, got %+v", matched)
+	}
+	if len(unmatched) != 2 {
+		t.Errorf("expected 2 unmatched comments, got %+v", unmatched)
+	}
+	if got := matched[0].Location(); got != "foo.go:-2 (deleted)" {
+		t.Errorf("unexpected location %q", got)
+	}
+}
+
+func TestComposeSummary_GroupsByCategory(t *testing.T) {
+	extra := []Comment{
+		{FilePath: "a.go", Line: 3, Text: "style nit", Category: "style"},
+		{FilePath: "b.go", Text: "file issue", IsFileLevel: true},
+		{FilePath: "c.go", Line: 9, Text: "leak", Category: "security"},
+	}
+	got := ComposeSummary("Overall summary.", extra)
+	want := "Overall summary.\n\n" +
+		"- **security** [c.go:9] leak\n" +
+		"- **style** [a.go:3] style nit\n" +
+		"- [b.go] file issue\n"
+	if got != want {
+		t.Errorf("unexpected summary:\n%s\nwant:\n%s", got, want)
+	}
+	if ComposeSummary("only", nil) != "only" {
+		t.Errorf("expected summary unchanged with no extra comments")
+	}
+}
+
+func TestResult_SummaryOnly(t *testing.T) {
+	r := &Review{
+		Summary: "Looks fine.",
+		Matched: []Comment{
+			{FilePath: "a.go", Line: 3, Text: "nil check", Category: "bug"},
+			{FilePath: "b.go", Text: "split this file", IsFileLevel: true},
+		},
+		Unmatched: []Comment{{FilePath: "c.go", Line: 99, Text: "stale line"}},
+	}
+	res := r.Result()
+
+	got := res.SummaryOnly(false)
+	if len(got.Inline) != 0 || len(got.FileLevel) != 0 {
+		t.Errorf("SummaryOnly left comments to post: %+v", got)
+	}
+	if got.Summary != res.Summary || len(got.Unmatched) != 1 {
+		t.Errorf("SummaryOnly(false) changed the summary:\n%s", got.Summary)
+	}
+
+	got = res.SummaryOnly(true)
+	if len(got.Inline) != 0 || len(got.FileLevel) != 0 {
+		t.Errorf("SummaryOnly(true) left comments to post: %+v", got)
+	}
+	want := "Looks fine.\n\n" +
+		"- [c.go:99] stale line\n\n" +
+		"- **bug** [a.go:3] nil check\n" +
+		"- [b.go] split this file\n"
+	if got.Summary != want {
+		t.Errorf("SummaryOnly(true) summary:\n%s\nwant:\n%s", got.Summary, want)
+	}
+
+	if got := (Result{}).SummaryOnly(true); got.Summary != "" {
+		t.Errorf("empty result folded into %q", got.Summary)
+	}
+}
+
+func TestParseUnifiedDiff_RenameOnly(t *testing.T) {
+	diff := `diff --git a/pkg/old_name.go b/pkg/new_name.go
+similarity index 100%
+rename from pkg/old_name.go
+rename to pkg/new_name.go
+diff --git a/main.go b/main.go
+index 1..2 100644
+--- a/main.go
++++ b/main.go
+@@ -1 +1 @@
+-package old
++package main
+`
+	files, err := ParseUnifiedDiff(diff)
+	if err != nil {
+		t.Fatalf("ParseUnifiedDiff failed: %v", err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("expected 2 files (rename-only file included), got %d", len(files))
+	}
+	f := files[0]
+	if !f.IsRename || f.OldPath != "pkg/old_name.go" || f.NewPath != "pkg/new_name.go" {
+		t.Errorf("unexpected rename file %+v", f)
+	}
+	if len(f.Hunks) != 0 {
+		t.Errorf("expected no hunks for a pure rename, got %d", len(f.Hunks))
+	}
+	if files[1].NewPath != "main.go" || len(files[1].Hunks) != 1 {
+		t.Errorf("file after the rename was not parsed correctly: %+v", files[1])
+	}
+
+	matched, unmatched := MatchCommentsToDiff([]Comment{
+		{FilePath: "pkg/new_name.go", Text: "Rename breaks importers.", IsFileLevel: true},
+		{FilePath: "pkg/new_name.go", Line: 1, Text: "No changed lines here."},
+	}, files)
+	if len(matched) != 1 || !matched[0].IsFileLevel {
+		t.Errorf("expected the file-level comment on the renamed file to match, got %+v", matched)
+	}
+	if len(unmatched) != 1 {
+		t.Errorf("expected the inline comment on a hunkless rename to be unmatched, got %+v", unmatched)
+	}
+}
+
+func TestParseUnifiedDiff_RenameWithChangesAndCopy(t *testing.T) {
+	diff := `diff --git a/util/a.go b/util/b.go
+similarity index 90%
+rename from util/a.go
+rename to util/b.go
+index 1..2 100644
+--- a/util/a.go
++++ b/util/b.go
+@@ -1,2 +1,2 @@
+ package util
+-const A = 1
++const B = 1
+diff --git a/tmpl/base.html b/tmpl/page.html
+similarity index 95%
+copy from tmpl/base.html
+copy to tmpl/page.html
+index 3..4 100644
+--- a/tmpl/base.html
++++ b/tmpl/page.html
+@@ -1 +1 @@
+-<h1>Base</h1>
++<h1>Page</h1>
+`
+	files, err := ParseUnifiedDiff(diff)
+	if err != nil {
+		t.Fatalf("ParseUnifiedDiff failed: %v", err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("expected 2 files, got %d", len(files))
+	}
+	if !files[0].IsRename || files[0].OldPath != "util/a.go" || files[0].NewPath != "util/b.go" || len(files[0].Hunks) != 1 {
+		t.Errorf("unexpected renamed file %+v", files[0])
+	}
+	if !files[1].IsCopy || files[1].OldPath != "tmpl/base.html" || files[1].NewPath != "tmpl/page.html" {
+		t.Errorf("unexpected copied file %+v", files[1])
+	}
+	matched, _ := MatchCommentsToDiff([]Comment{{FilePath: "util/b.go", Line: 2, Text: "Exported name changed."}}, files)
+	if len(matched) != 1 {
+		t.Errorf("expected inline comment on renamed file to match, got %+v", matched)
+	}
+}
+
+func TestParseUnifiedDiff_AddedFile(t *testing.T) {
+	diff := `diff --git a/cmd/new.go b/cmd/new.go
+new file mode 100644
+index 0000000..1234567
+--- /dev/null
++++ b/cmd/new.go
+@@ -0,0 +1,3 @@
++package cmd
++
++func New() {}
+`
+	files, err := ParseUnifiedDiff(diff)
+	if err != nil {
+		t.Fatalf("ParseUnifiedDiff failed: %v", err)
+	}
+	if len(files) != 1 {
+		t.Fatalf("expected 1 file, got %d", len(files))
+	}
+	f := files[0]
+	if !f.IsNew || f.IsDeleted || f.OldPath != "" || f.NewPath != "cmd/new.go" || f.Path() != "cmd/new.go" {
+		t.Errorf("unexpected added file %+v", f)
+	}
+
+	matched, unmatched := MatchCommentsToDiff([]Comment{
+		{FilePath: "cmd/new.go", Text: "New package lacks tests.", IsFileLevel: true},
+		{FilePath: "cmd/new.go", Line: 3, Text: "Exported func without doc comment."},
+	}, files)
+	if len(matched) != 2 || len(unmatched) != 0 {
+		t.Errorf("expected both comments on the new file to match, got %d matched, %d unmatched", len(matched), len(unmatched))
+	}
+}
+
+func TestParseUnifiedDiff_DeletedFile(t *testing.T) {
+	diff := `diff --git a/legacy/old.go b/legacy/old.go
+deleted file mode 100644
+index 1234567..0000000
+--- a/legacy/old.go
++++ /dev/null
+@@ -1,2 +0,0 @@
+-package legacy
+-func Old() {}
+diff --git a/empty.txt b/empty.txt
+new file mode 100644
+index 0000000..e69de29
+`
+	files, err := ParseUnifiedDiff(diff)
+	if err != nil {
+		t.Fatalf("ParseUnifiedDiff failed: %v", err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("expected the deleted file and the empty new file, got %d files", len(files))
+	}
+	f := files[0]
+	if !f.IsDeleted || f.IsNew || f.NewPath != "" || f.OldPath != "legacy/old.go" || f.Path() != "legacy/old.go" {
+		t.Errorf("unexpected deleted file %+v", f)
+	}
+	if !files[1].IsNew || files[1].Path() != "empty.txt" || len(files[1].Hunks) != 0 {
+		t.Errorf("unexpected empty new file %+v", files[1])
+	}
+
+	matched, unmatched := MatchCommentsToDiff([]Comment{
+		{FilePath: "legacy/old.go", Text: "Callers of Old still exist.", IsFileLevel: true},
+		{FilePath: "legacy/old.go", OldLine: 2, Text: "Removing Old breaks the public API."},
+	}, files)
+	if len(matched) != 2 || len(unmatched) != 0 {
+		t.Errorf("expected both comments on the deleted file to match, got %d matched, %d unmatched", len(matched), len(unmatched))
+	}
+}
+
+func TestParseUnifiedDiff_PathsWithSpaces(t *testing.T) {
+	diff := "diff --git a/docs/user guide.md b/docs/user guide.md\n" +
+		"index 1..2 100644\n" +
+		"--- a/docs/user guide.md\t\n" +
+		"+++ b/docs/user guide.md\t\n" +
+		"@@ -1 +1 @@\n" +
+		"-Old title\n" +
+		"+New title\n" +
+		"diff --git a/my dir/a b.go b/my dir/c d.go\n" +
+		"similarity index 90%\n" +
+		"rename from my dir/a b.go\n" +
+		"rename to my dir/c d.go\n" +
+		"--- a/my dir/a b.go\t\n" +
+		"+++ b/my dir/c d.go\t\n" +
+		"@@ -1 +1 @@\n" +
+		"-package a\n" +
+		"+package c\n"
+	files, err := ParseUnifiedDiff(diff)
+	if err != nil {
+		t.Fatalf("ParseUnifiedDiff failed: %v", err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("expected 2 files, got %d", len(files))
+	}
+	if files[0].OldPath != "docs/user guide.md" || files[0].NewPath != "docs/user guide.md" || len(files[0].Hunks) != 1 {
+		t.Errorf("unexpected paths for file with spaces: %q -> %q", files[0].OldPath, files[0].NewPath)
+	}
+	if files[1].OldPath != "my dir/a b.go" || files[1].NewPath != "my dir/c d.go" || !files[1].IsRename {
+		t.Errorf("unexpected paths for renamed file with spaces: %q -> %q", files[1].OldPath, files[1].NewPath)
+	}
+}
+
+func TestParseUnifiedDiff_QuotedPaths(t *testing.T) {
+	diff := `diff --git "a/caf\303\251/men\303\274.go" "b/caf\303\251/men\303\274.go"
+index 1..2 100644
+--- "a/caf\303\251/men\303\274.go"
++++ "b/caf\303\251/men\303\274.go"
+@@ -1 +1 @@
+-package old
++package menu
+diff --git "a/quote\"d name.go" "b/quote\"d name.go"
+new file mode 100644
+--- /dev/null
++++ "b/quote\"d name.go"
+@@ -0,0 +1 @@
++package quoted
+`
+	files, err := ParseUnifiedDiff(diff)
+	if err != nil {
+		t.Fatalf("ParseUnifiedDiff failed: %v", err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("expected 2 files, got %d", len(files))
+	}
+	if files[0].NewPath != "café/menü.go" || files[0].OldPath != "café/menü.go" || len(files[0].Hunks) != 1 {
+		t.Errorf("unexpected quoted UTF-8 path: %q -> %q", files[0].OldPath, files[0].NewPath)
+	}
+	if files[1].NewPath != `quote"d name.go` || !files[1].IsNew || len(files[1].Hunks) != 1 {
+		t.Errorf("unexpected quoted path with escaped quote: %+v", files[1])
+	}
+}
+
+func TestParseGitDiffHeader(t *testing.T) {
+	tests := []struct {
+		header, oldPath, newPath string
+	}{
+		{"a/foo.go b/foo.go", "foo.go", "foo.go"},
+		{"a/old.go b/new.go", "old.go", "new.go"},
+		{"a/dir b/x.go b/dir b/x.go", "dir b/x.go", "dir b/x.go"},
+		{`"a/sp ace\t.go" "b/sp ace\t.go"`, "sp ace\t.go", "sp ace\t.go"},
+		{`a/plain.go "b/quot\"ed.go"`, "plain.go", `quot"ed.go`},
+	}
+	for _, tt := range tests {
+		oldPath, newPath := parseGitDiffHeader(tt.header)
+		if oldPath != tt.oldPath || newPath != tt.newPath {
+			t.Errorf("parseGitDiffHeader(%q) = %q, %q; want %q, %q", tt.header, oldPath, newPath, tt.oldPath, tt.newPath)
+		}
+	}
+}
+
+func TestReviewResult(t *testing.T) {
+	diff := `diff --git a/foo.go b/foo.go
+index 1234567..89abcde 100644
+--- a/foo.go
++++ b/foo.go
+@@ -1,3 +1,4 @@
+ package main
+-func hello() {}
++func hello(name string) {}
++func bye() {}
+`
+	raw := `******************** SECTION: FILE-LEVEL COMMENTS ********************
+
+FILE: foo.go
+COMMENT: Needs tests.
+
+FILE: missing.go
+COMMENT: Not in the diff.
+
+******************** SECTION: INLINE COMMENTS ********************
+
+FILE: foo.go
+LINE: 2
+COMMENT: Name is unused.
+
+FILE: foo.go
+LINE: 40
+COMMENT: Line not in the diff.
+
+******************** SECTION: SUMMARY ********************
+
+Looks fine overall.
+`
+	r := NewReview("1", diff)
+	if err := r.ParseDiff(); err != nil {
+		t.Fatalf("ParseDiff failed: %v", err)
+	}
+	r.ParseLLMResponse(raw)
+	r.MatchComments(0)
+	res := r.Result()
+
+	if len(res.Inline) != 1 || res.Inline[0].Text != "Name is unused." {
+		t.Errorf("unexpected inline comments: %+v", res.Inline)
+	}
+	if len(res.FileLevel) != 1 || res.FileLevel[0].Text != "Needs tests." {
+		t.Errorf("unexpected file-level comments: %+v", res.FileLevel)
+	}
+	if len(res.Unmatched) != 2 {
+		t.Errorf("expected 2 unmatched comments, got %+v", res.Unmatched)
+	}
+	want := "Looks fine overall.\n\n" +
+		"- [foo.go:40] Line not in the diff.\n" +
+		"- [missing.go] Not in the diff.\n"
+	if res.Summary != want {
+		t.Errorf("unexpected summary:\n%s\nwant:\n%s", res.Summary, want)
+	}
+}
+
+func TestComment_PlainSuggestionText(t *testing.T) {
+	c := Comment{
+		Text:       "Use the constant.\n```suggestion\n\treturn maxRetries\n```\nIt is defined in config.go.",
+		Suggestion: "\treturn maxRetries",
+	}
+	want := "Use the constant.\n**Suggested change:**\n```\n\treturn maxRetries\n```\nIt is defined in config.go."
+	if got := c.PlainSuggestionText(); got != want {
+		t.Errorf("PlainSuggestionText() = %q, want %q", got, want)
+	}
+
+	plain := Comment{Text: "No suggestion here."}
+	if got := plain.PlainSuggestionText(); got != plain.Text {
+		t.Errorf("expected text without a suggestion unchanged, got %q", got)
+	}
+}
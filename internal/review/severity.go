@@ -0,0 +1,137 @@
+package review
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Known finding severities, from most to least severe.
+const (
+	SeverityCritical = "critical"
+	SeverityHigh     = "high"
+	SeverityMedium   = "medium"
+	SeverityLow      = "low"
+	SeverityInfo     = "info"
+)
+
+var severityOrder = []string{SeverityCritical, SeverityHigh, SeverityMedium, SeverityLow, SeverityInfo}
+
+// severityAliases maps common LLM spellings onto the known severities.
+var severityAliases = map[string]string{
+	"blocker":       SeverityCritical,
+	"major":         SeverityHigh,
+	"moderate":      SeverityMedium,
+	"med":           SeverityMedium,
+	"minor":         SeverityLow,
+	"trivial":       SeverityInfo,
+	"informational": SeverityInfo,
+	"nit":           SeverityInfo,
+}
+
+// NormalizeSeverity lowercases and trims a severity value and maps known aliases
+// (e.g. "Major" -> "high"). Unknown severities are kept as-is (lowercased).
+func NormalizeSeverity(severity string) string {
+	s := strings.ToLower(strings.TrimSpace(severity))
+	if alias, ok := severityAliases[s]; ok {
+		return alias
+	}
+	return s
+}
+
+// severityRank returns the position of a normalized severity in severityOrder (0 is most
+// severe), or len(severityOrder) for empty or unknown severities.
+func severityRank(severity string) int {
+	for i, s := range severityOrder {
+		if s == severity {
+			return i
+		}
+	}
+	return len(severityOrder)
+}
+
+// ValidateSeverity returns an error if severity is neither empty nor a known severity
+// (aliases are accepted).
+func ValidateSeverity(severity string) error {
+	s := NormalizeSeverity(severity)
+	if s == "" || severityRank(s) < len(severityOrder) {
+		return nil
+	}
+	return fmt.Errorf("invalid severity %q (must be one of %s)", severity, strings.Join(severityOrder, ", "))
+}
+
+// FilterBySeverity returns only the comments at or above the given minimum severity.
+// An empty minimum disables filtering. Comments without a known severity are kept, since
+// the LLM does not always rate its findings.
+func FilterBySeverity(comments []Comment, minSeverity string) []Comment {
+	min := NormalizeSeverity(minSeverity)
+	if min == "" {
+		return comments
+	}
+	minRank := severityRank(min)
+	var filtered []Comment
+	for _, c := range comments {
+		rank := severityRank(c.Severity)
+		if rank <= minRank || rank == len(severityOrder) {
+			filtered = append(filtered, c)
+		}
+	}
+	return filtered
+}
+
+// SortBySeverity sorts comments from most to least severe, with unrated comments last.
+// The relative order of comments with the same severity is preserved.
+func SortBySeverity(comments []Comment) {
+	sort.SliceStable(comments, func(i, j int) bool {
+		return severityRank(comments[i].Severity) < severityRank(comments[j].Severity)
+	})
+}
+
+// CapInlineComments limits the number of inline comments to max, keeping the most severe
+// ones (earlier comments win ties) and returning the rest as overflow, e.g. to be rolled into
+// the summary with ComposeSummary. File-level comments are never capped. A max of zero or
+// less disables the cap. Kept comments stay in their original order.
+func CapInlineComments(comments []Comment, max int) (kept []Comment, overflow []Comment) {
+	var inline []int
+	for i, c := range comments {
+		if !c.IsFileLevel {
+			inline = append(inline, i)
+		}
+	}
+	if max <= 0 || len(inline) <= max {
+		return comments, nil
+	}
+	sort.SliceStable(inline, func(i, j int) bool {
+		return severityRank(comments[inline[i]].Severity) < severityRank(comments[inline[j]].Severity)
+	})
+	dropped := make(map[int]bool)
+	for _, i := range inline[max:] {
+		dropped[i] = true
+	}
+	for i, c := range comments {
+		if dropped[i] {
+			overflow = append(overflow, c)
+		} else {
+			kept = append(kept, c)
+		}
+	}
+	return kept, overflow
+}
+
+// CountIssues returns how many comments count as issues for a minimum severity: all of them if
+// minSeverity is empty, otherwise only those rated at or above it (unrated comments do not
+// count, since they are not known to reach the threshold).
+func CountIssues(comments []Comment, minSeverity string) int {
+	min := NormalizeSeverity(minSeverity)
+	if min == "" {
+		return len(comments)
+	}
+	minRank := severityRank(min)
+	n := 0
+	for _, c := range comments {
+		if severityRank(c.Severity) <= minRank {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,134 @@
+package review
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalizeSeverity(t *testing.T) {
+	tests := map[string]string{
+		"High":     "high",
+		" MEDIUM ": "medium",
+		"Major":    "high",
+		"minor":    "low",
+		"nit":      "info",
+		"urgent":   "urgent",
+		"":         "",
+	}
+	for in, want := range tests {
+		if got := NormalizeSeverity(in); got != want {
+			t.Errorf("NormalizeSeverity(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestValidateSeverity(t *testing.T) {
+	for _, s := range []string{"", "critical", "Medium", "minor", "info"} {
+		if err := ValidateSeverity(s); err != nil {
+			t.Errorf("ValidateSeverity(%q) returned error: %v", s, err)
+		}
+	}
+	if err := ValidateSeverity("urgent"); err == nil {
+		t.Error("expected an error for an unknown severity")
+	}
+}
+
+func TestFilterBySeverity(t *testing.T) {
+	comments := []Comment{
+		{Text: "1", Severity: "critical"},
+		{Text: "2", Severity: "low"},
+		{Text: "3", Severity: "medium"},
+		{Text: "4"},
+		{Text: "5", Severity: "info"},
+	}
+
+	if got := FilterBySeverity(comments, ""); len(got) != len(comments) {
+		t.Errorf("expected no filtering with empty minimum, got %d comments", len(got))
+	}
+
+	got := FilterBySeverity(comments, "Medium")
+	var texts []string
+	for _, c := range got {
+		texts = append(texts, c.Text)
+	}
+	if len(texts) != 3 || texts[0] != "1" || texts[1] != "3" || texts[2] != "4" {
+		t.Errorf("expected critical, medium and unrated comments, got %v", texts)
+	}
+}
+
+func TestSortBySeverity(t *testing.T) {
+	comments := []Comment{
+		{Text: "1", Severity: "low"},
+		{Text: "2"},
+		{Text: "3", Severity: "critical"},
+		{Text: "4", Severity: "low"},
+		{Text: "5", Severity: "high"},
+	}
+	SortBySeverity(comments)
+	want := []string{"3", "5", "1", "4", "2"}
+	for i, c := range comments {
+		if c.Text != want[i] {
+			t.Fatalf("unexpected order at %d: got %q, want %q (%+v)", i, c.Text, want[i], comments)
+		}
+	}
+}
+
+func TestCapInlineComments(t *testing.T) {
+	comments := []Comment{
+		{FilePath: "a.go", Line: 1, Text: "low", Severity: "low"},
+		{FilePath: "a.go", Text: "file", IsFileLevel: true, Severity: "info"},
+		{FilePath: "a.go", Line: 2, Text: "critical", Severity: "critical"},
+		{FilePath: "b.go", Line: 3, Text: "unrated"},
+		{FilePath: "b.go", Line: 4, Text: "high", Severity: "high"},
+	}
+
+	kept, overflow := CapInlineComments(comments, 0)
+	if len(kept) != len(comments) || overflow != nil {
+		t.Errorf("expected no cap with max 0, got %d kept, %d overflow", len(kept), len(overflow))
+	}
+
+	kept, overflow = CapInlineComments(comments, 2)
+	var keptTexts, overflowTexts []string
+	for _, c := range kept {
+		keptTexts = append(keptTexts, c.Text)
+	}
+	for _, c := range overflow {
+		overflowTexts = append(overflowTexts, c.Text)
+	}
+	wantKept := []string{"file", "critical", "high"}
+	wantOverflow := []string{"low", "unrated"}
+	if strings.Join(keptTexts, ",") != strings.Join(wantKept, ",") {
+		t.Errorf("kept = %v, want %v", keptTexts, wantKept)
+	}
+	if strings.Join(overflowTexts, ",") != strings.Join(wantOverflow, ",") {
+		t.Errorf("overflow = %v, want %v", overflowTexts, wantOverflow)
+	}
+
+	summary := ComposeSummary("Summary.", overflow)
+	if !strings.Contains(summary, "- [a.go:1] low\n") || !strings.Contains(summary, "- [b.go:3] unrated\n") {
+		t.Errorf("expected overflow comments as summary bullets, got:\n%s", summary)
+	}
+}
+
+func TestCountIssues(t *testing.T) {
+	comments := []Comment{
+		{Text: "1", Severity: "critical"},
+		{Text: "2", Severity: "low"},
+		{Text: "3"},
+		{Text: "4", Severity: "high"},
+	}
+	tests := map[string]int{
+		"":         4,
+		"high":     2,
+		"Critical": 1,
+		"info":     3,
+	}
+	for threshold, want := range tests {
+		if got := CountIssues(comments, threshold); got != want {
+			t.Errorf("CountIssues(%q) = %d, want %d", threshold, got, want)
+		}
+	}
+	if got := CountIssues(nil, ""); got != 0 {
+		t.Errorf("expected no issues for a clean review, got %d", got)
+	}
+}
@@ -0,0 +1,83 @@
+package review
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Policies for diffs larger than the configured size limit.
+const (
+	OversizedSkip     = "skip"     // Don't review the diff (default)
+	OversizedChunk    = "chunk"    // Review it in per-file chunks of at most the limit
+	OversizedTruncate = "truncate" // Review only the files that fit within the limit
+)
+
+// ValidateOversizedPolicy returns an error if policy is not one of the supported values.
+// An empty policy is treated as OversizedSkip.
+func ValidateOversizedPolicy(policy string) error {
+	switch strings.ToLower(policy) {
+	case "", OversizedSkip, OversizedChunk, OversizedTruncate:
+		return nil
+	default:
+		return fmt.Errorf("invalid oversized-diff policy %q (must be %s, %s, or %s)", policy, OversizedSkip, OversizedChunk, OversizedTruncate)
+	}
+}
+
+// diffLineBytes is the assumed average size of a changed line in a unified diff, including its
+// "+" or "-" prefix and newline.
+const diffLineBytes = 32
+
+// SkipByEstimate reports whether a diff changing linesChanged lines (added plus removed, as
+// counted by a diffstat) would be skipped as over maxBytes, so it need not be fetched at all.
+// The estimate leaves out context lines and file headers, so it is usually below the real size
+// and a diff near the limit is still fetched and measured. Only the skip policy qualifies:
+// chunking and truncation need the diff itself.
+func SkipByEstimate(linesChanged, maxBytes int, policy string) (estimate int, skip bool) {
+	estimate = linesChanged * diffLineBytes
+	switch strings.ToLower(policy) {
+	case "", OversizedSkip:
+		return estimate, maxBytes > 0 && estimate > maxBytes
+	default:
+		return estimate, false
+	}
+}
+
+// SizeDecision is what to do with a diff, as decided by DecideDiffSize.
+type SizeDecision struct {
+	Action  string      // "" if the diff is within the limit, otherwise the Oversized policy to apply
+	Files   []*DiffFile // With OversizedTruncate, the files that fit within the limit
+	Diff    string      // With OversizedTruncate, the unified diff of Files
+	Dropped []string    // With OversizedTruncate, the paths of the files left out
+}
+
+// DecideDiffSize decides how to handle a diff of diffBytes bytes, parsed into files, given a
+// limit of maxBytes (0 means no limit) and policy. Truncation keeps files in diff order and
+// leaves out any file that would take the total over the limit, so smaller files later in the
+// diff still get reviewed. Chunking and truncation need the parsed files; an unparsed diff
+// over the limit is skipped whatever the policy.
+func DecideDiffSize(files []*DiffFile, diffBytes, maxBytes int, policy string) SizeDecision {
+	if maxBytes <= 0 || diffBytes <= maxBytes {
+		return SizeDecision{}
+	}
+	policy = strings.ToLower(policy)
+	if len(files) == 0 || (policy != OversizedChunk && policy != OversizedTruncate) {
+		return SizeDecision{Action: OversizedSkip}
+	}
+	if policy == OversizedChunk {
+		return SizeDecision{Action: OversizedChunk}
+	}
+
+	d := SizeDecision{Action: OversizedTruncate}
+	var diff strings.Builder
+	for _, f := range files {
+		text := formatUnifiedDiff(f)
+		if diff.Len()+len(text) > maxBytes {
+			d.Dropped = append(d.Dropped, f.Path())
+			continue
+		}
+		d.Files = append(d.Files, f)
+		diff.WriteString(text)
+	}
+	d.Diff = diff.String()
+	return d
+}
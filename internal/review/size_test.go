@@ -0,0 +1,121 @@
+package review
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDecideDiffSize(t *testing.T) {
+	files, err := ParseUnifiedDiff(multiFileDiff(3))
+	if err != nil {
+		t.Fatalf("ParseUnifiedDiff failed: %v", err)
+	}
+	single := len(formatUnifiedDiff(files[0]))
+	total := 3 * single
+
+	tests := []struct {
+		name       string
+		files      []*DiffFile
+		maxBytes   int
+		policy     string
+		wantAction string
+	}{
+		{"no limit", files, 0, OversizedSkip, ""},
+		{"within limit", files, total, OversizedSkip, ""},
+		{"default policy skips", files, single, "", OversizedSkip},
+		{"skip", files, single, OversizedSkip, OversizedSkip},
+		{"chunk", files, single, "CHUNK", OversizedChunk},
+		{"truncate", files, single, OversizedTruncate, OversizedTruncate},
+		{"unparsed diff is skipped", nil, single, OversizedChunk, OversizedSkip},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := DecideDiffSize(tt.files, total, tt.maxBytes, tt.policy)
+			if d.Action != tt.wantAction {
+				t.Errorf("Action = %q, want %q", d.Action, tt.wantAction)
+			}
+			if tt.wantAction != OversizedTruncate && (d.Files != nil || d.Dropped != nil) {
+				t.Errorf("expected no files outside truncation, got %+v", d)
+			}
+		})
+	}
+}
+
+func TestDecideDiffSize_TruncateKeepsFilesThatFit(t *testing.T) {
+	// The middle file is far larger than the others
+	var big strings.Builder
+	big.WriteString("diff --git a/big.go b/big.go\n--- a/big.go\n+++ b/big.go\n@@ -1,1 +1,40 @@\n package big\n")
+	for i := 0; i < 39; i++ {
+		big.WriteString("+var x = \"a long generated line of code\"\n")
+	}
+	diff := multiFileDiff(1) + big.String() + strings.ReplaceAll(multiFileDiff(2), "file1", "file0")
+	files, err := ParseUnifiedDiff(diff)
+	if err != nil {
+		t.Fatalf("ParseUnifiedDiff failed: %v", err)
+	}
+	if len(files) != 4 {
+		t.Fatalf("expected 4 files, got %d", len(files))
+	}
+	limit := 3 * len(formatUnifiedDiff(files[0]))
+
+	d := DecideDiffSize(files, len(diff), limit, OversizedTruncate)
+	if d.Action != OversizedTruncate {
+		t.Fatalf("Action = %q, want %q", d.Action, OversizedTruncate)
+	}
+	if len(d.Dropped) != 1 || d.Dropped[0] != "big.go" {
+		t.Errorf("expected only big.go dropped, got %v", d.Dropped)
+	}
+	var kept []string
+	for _, f := range d.Files {
+		kept = append(kept, f.Path())
+	}
+	if strings.Join(kept, ",") != "pkg/file1.go,pkg/file0.go,pkg/file2.go" {
+		t.Errorf("unexpected kept files %v", kept)
+	}
+	if len(d.Diff) > limit {
+		t.Errorf("truncated diff is %d bytes, over the %d limit", len(d.Diff), limit)
+	}
+	reparsed, err := ParseUnifiedDiff(d.Diff)
+	if err != nil || len(reparsed) != 3 {
+		t.Errorf("truncated diff should parse into 3 files, got %d (err %v)", len(reparsed), err)
+	}
+}
+
+func TestValidateOversizedPolicy(t *testing.T) {
+	for _, p := range []string{"", "skip", "chunk", "Truncate"} {
+		if err := ValidateOversizedPolicy(p); err != nil {
+			t.Errorf("ValidateOversizedPolicy(%q) = %v", p, err)
+		}
+	}
+	if err := ValidateOversizedPolicy("split"); err == nil {
+		t.Error("expected error for unknown policy")
+	}
+}
+
+func TestSkipByEstimate(t *testing.T) {
+	tests := []struct {
+		name         string
+		linesChanged int
+		maxBytes     int
+		policy       string
+		want         bool
+	}{
+		{"no limit", 1000, 0, OversizedSkip, false},
+		{"within limit", 10, 10 * diffLineBytes, "", false},
+		{"over limit", 11, 10 * diffLineBytes, "", true},
+		{"over limit, explicit skip", 11, 10 * diffLineBytes, "SKIP", true},
+		{"chunk needs the diff", 11, 10 * diffLineBytes, OversizedChunk, false},
+		{"truncate needs the diff", 11, 10 * diffLineBytes, OversizedTruncate, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			estimate, skip := SkipByEstimate(tt.linesChanged, tt.maxBytes, tt.policy)
+			if skip != tt.want {
+				t.Errorf("skip = %v, want %v", skip, tt.want)
+			}
+			if estimate != tt.linesChanged*diffLineBytes {
+				t.Errorf("estimate = %d, want %d", estimate, tt.linesChanged*diffLineBytes)
+			}
+		})
+	}
+}
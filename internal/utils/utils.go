@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"bytes"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path"
@@ -27,6 +28,39 @@ func GetCurrentGitBranch(repoPath string) (string, error) {
 	return branch, nil
 }
 
+// GetGitRepoRoot returns the top-level directory of the git repository containing repoPath.
+func GetGitRepoRoot(repoPath string) (string, error) {
+	cmd := exec.Command("git", "rev-parse", "--show-toplevel")
+	cmd.Dir = repoPath
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("failed to determine git repository root: %w", err)
+	}
+	return strings.TrimSpace(out.String()), nil
+}
+
+// GetGitDiff returns the unified diff of the commits on HEAD since it diverged from base
+// ("git diff base...HEAD"), in the same git-style format Bitbucket returns for a PR. Only
+// committed changes are included.
+func GetGitDiff(repoPath, base string) (string, error) {
+	if strings.HasPrefix(base, "-") {
+		return "", fmt.Errorf("invalid base revision %q", base)
+	}
+	// Pin the options that change the diff format regardless of the user's git config
+	cmd := exec.Command("git", "-c", "diff.noprefix=false", "-c", "diff.mnemonicPrefix=false",
+		"diff", "--no-color", "--no-ext-diff", "-M", base+"...HEAD")
+	cmd.Dir = repoPath
+	var out, stderr bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git diff %s...HEAD failed: %w: %s", base, err, strings.TrimSpace(stderr.String()))
+	}
+	return out.String(), nil
+}
+
 // GetRepoSlugFromGitRemote returns the Bitbucket repo slug by parsing the 'origin' remote URL.
 // It supports both HTTPS and SSH remote formats.
 // Returns the repo slug (e.g., "bdirect-notifications") or an error if it cannot be determined.
@@ -67,7 +101,12 @@ func GetRepoSlugFromGitRemote(repoPath string) (string, error) {
 // PromptYesNo prompts the user with a yes/no question and returns true if yes, false otherwise.
 // The defaultAnswer parameter determines what happens on empty input ("y" or "n").
 func PromptYesNo(question string, defaultAnswer string) (bool, error) {
-	reader := bufio.NewReader(os.Stdin)
+	return PromptYesNoFrom(bufio.NewReader(os.Stdin), os.Stdout, question, defaultAnswer)
+}
+
+// PromptYesNoFrom is PromptYesNo reading the answer from reader and writing the prompt to w.
+// Reuse the same reader across prompts so that input buffered by one is not lost to the next.
+func PromptYesNoFrom(reader *bufio.Reader, w io.Writer, question string, defaultAnswer string) (bool, error) {
 	defaultAnswer = strings.ToLower(defaultAnswer)
 
 	// Display prompt with default indicator
@@ -77,7 +116,7 @@ func PromptYesNo(question string, defaultAnswer string) (bool, error) {
 	} else {
 		prompt += " [y/N]: "
 	}
-	fmt.Print(prompt)
+	fmt.Fprint(w, prompt)
 
 	// Read user input
 	input, err := reader.ReadString('\n')
@@ -4,6 +4,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -76,6 +77,28 @@ func TestGetCurrentGitBranch(t *testing.T) {
 	}
 }
 
+func TestGetGitRepoRoot(t *testing.T) {
+	repoDir := setupTestRepo(t, "main", "")
+	subDir := filepath.Join(repoDir, "nested", "dir")
+	if err := os.MkdirAll(subDir, 0755); err != nil {
+		t.Fatalf("failed to create subdir: %v", err)
+	}
+
+	got, err := GetGitRepoRoot(subDir)
+	if err != nil {
+		t.Fatalf("GetGitRepoRoot failed: %v", err)
+	}
+	want, _ := filepath.EvalSymlinks(repoDir)
+	gotResolved, _ := filepath.EvalSymlinks(got)
+	if gotResolved != want {
+		t.Errorf("expected repo root %q, got %q", want, got)
+	}
+
+	if _, err := GetGitRepoRoot(t.TempDir()); err == nil {
+		t.Error("expected error for non-git directory, got nil")
+	}
+}
+
 func TestGetRepoSlugFromGitRemote_HTTPS(t *testing.T) {
 	repoSlug := "my-repo"
 	remoteURL := "https://bitbucket.org/myteam/" + repoSlug + ".git"
@@ -127,6 +150,68 @@ func TestGetRepoSlugFromGitRemote_WeirdURL(t *testing.T) {
 	}
 }
 
+// runGit runs a git command in dir and fails the test on error.
+func runGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("failed to run git %v: %v\n%s", args, err, out)
+	}
+}
+
+func TestGetGitDiff(t *testing.T) {
+	repoDir := setupTestRepo(t, "main", "")
+	runGit(t, repoDir, "branch", "-M", "main")
+	runGit(t, repoDir, "checkout", "-b", "feature")
+	if err := os.WriteFile(filepath.Join(repoDir, "main.go"), []byte("package main\n"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	runGit(t, repoDir, "add", "main.go")
+	runGit(t, repoDir, "commit", "-m", "add main.go")
+
+	// Changes on main after the branch point, and uncommitted changes, are not included
+	runGit(t, repoDir, "checkout", "main")
+	if err := os.WriteFile(filepath.Join(repoDir, "other.go"), []byte("package other\n"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	runGit(t, repoDir, "add", "other.go")
+	runGit(t, repoDir, "commit", "-m", "add other.go")
+	runGit(t, repoDir, "checkout", "feature")
+	if err := os.WriteFile(filepath.Join(repoDir, "README.md"), []byte("# changed\n"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	diff, err := GetGitDiff(repoDir, "main")
+	if err != nil {
+		t.Fatalf("GetGitDiff failed: %v", err)
+	}
+	if !strings.Contains(diff, "diff --git a/main.go b/main.go") || !strings.Contains(diff, "+package main") {
+		t.Errorf("expected the feature commit in the diff, got:\n%s", diff)
+	}
+	if strings.Contains(diff, "other.go") || strings.Contains(diff, "README.md") {
+		t.Errorf("diff includes changes outside base...HEAD:\n%s", diff)
+	}
+
+	diff, err = GetGitDiff(repoDir, "feature")
+	if err != nil || diff != "" {
+		t.Errorf("expected an empty diff against HEAD itself, got %q, %v", diff, err)
+	}
+}
+
+func TestGetGitDiff_Errors(t *testing.T) {
+	repoDir := setupTestRepo(t, "main", "")
+	if _, err := GetGitDiff(repoDir, "no-such-branch"); err == nil {
+		t.Error("expected error for unknown base, got nil")
+	}
+	if _, err := GetGitDiff(repoDir, "--output=/tmp/x"); err == nil {
+		t.Error("expected error for option-like base, got nil")
+	}
+	if _, err := GetGitDiff(t.TempDir(), "main"); err == nil {
+		t.Error("expected error for non-git directory, got nil")
+	}
+}
+
 // Clean up any temp dirs created by tests (optional, since t.TempDir handles it)
 func TestMain(m *testing.M) {
 	code := m.Run()
@@ -0,0 +1,109 @@
+// Package webhook receives Bitbucket pull request webhooks so reviews can be triggered by
+// events instead of running the CLI once per PR.
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// maxPayloadBytes bounds the webhook body; PR event payloads are far smaller.
+const maxPayloadBytes = 5 << 20
+
+// reviewEvents are the event keys (X-Event-Key) that trigger a review: PR created/updated on
+// Bitbucket Cloud, and PR opened/source branch updated on Bitbucket Server / Data Center.
+var reviewEvents = map[string]bool{
+	"pullrequest:created": true,
+	"pullrequest:updated": true,
+	"pr:opened":           true,
+	"pr:from_ref_updated": true,
+}
+
+// Handler is an http.Handler for Bitbucket webhooks. Requests must be POSTs signed with Secret
+// (X-Hub-Signature: sha256=<hex HMAC of the body>). For PR created/updated events it calls
+// Review with the PR ID and answers 202 Accepted; Review should start the review in the
+// background and return quickly. Other events are acknowledged and ignored.
+type Handler struct {
+	Secret string
+	Review func(prID string)
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxPayloadBytes))
+	if err != nil {
+		http.Error(w, "could not read request body", http.StatusBadRequest)
+		return
+	}
+	if !ValidSignature(h.Secret, body, r.Header.Get("X-Hub-Signature")) {
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+	event := r.Header.Get("X-Event-Key")
+	if !reviewEvents[event] {
+		// Diagnostics pings and unrelated events need no action
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	prID, err := ParsePullRequestID(body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	h.Review(prID)
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// ValidSignature reports whether header ("sha256=<hex>") is the HMAC-SHA256 of body keyed
+// with secret. An empty secret never validates.
+func ValidSignature(secret string, body []byte, header string) bool {
+	if secret == "" {
+		return false
+	}
+	sig, ok := strings.CutPrefix(header, "sha256=")
+	if !ok {
+		return false
+	}
+	got, err := hex.DecodeString(sig)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(got, mac.Sum(nil))
+}
+
+// ParsePullRequestID extracts the PR ID from a pull request event payload, accepting both the
+// Bitbucket Cloud ("pullrequest") and Bitbucket Server ("pullRequest") shapes.
+func ParsePullRequestID(body []byte) (string, error) {
+	var payload struct {
+		Cloud *struct {
+			ID int `json:"id"`
+		} `json:"pullrequest"`
+		Server *struct {
+			ID int `json:"id"`
+		} `json:"pullRequest"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return "", fmt.Errorf("invalid webhook payload: %w", err)
+	}
+	switch {
+	case payload.Cloud != nil && payload.Cloud.ID > 0:
+		return strconv.Itoa(payload.Cloud.ID), nil
+	case payload.Server != nil && payload.Server.ID > 0:
+		return strconv.Itoa(payload.Server.ID), nil
+	}
+	return "", errors.New("webhook payload has no pull request ID")
+}
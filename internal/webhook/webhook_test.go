@@ -0,0 +1,112 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const testSecret = "s3cret"
+
+// cloudPayload is a trimmed Bitbucket Cloud pullrequest:created payload.
+const cloudPayload = `{
+  "actor": {"display_name": "Dev"},
+  "pullrequest": {
+    "id": 42,
+    "title": "Add login",
+    "state": "OPEN",
+    "source": {"branch": {"name": "feature/login"}, "commit": {"hash": "abc123"}}
+  },
+  "repository": {"full_name": "ws/repo"}
+}`
+
+// serverPayload is a trimmed Bitbucket Server pr:opened payload.
+const serverPayload = `{
+  "eventKey": "pr:opened",
+  "pullRequest": {"id": 7, "title": "Fix bug", "fromRef": {"displayId": "bugfix"}}
+}`
+
+func sign(body string) string {
+	mac := hmac.New(sha256.New, []byte(testSecret))
+	mac.Write([]byte(body))
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func send(h http.Handler, method, event, body, signature string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/webhook", strings.NewReader(body))
+	req.Header.Set("X-Event-Key", event)
+	if signature != "" {
+		req.Header.Set("X-Hub-Signature", signature)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestHandler_TriggersReview(t *testing.T) {
+	tests := []struct {
+		event, body, wantID string
+	}{
+		{"pullrequest:created", cloudPayload, "42"},
+		{"pullrequest:updated", cloudPayload, "42"},
+		{"pr:opened", serverPayload, "7"},
+	}
+	for _, tt := range tests {
+		var got []string
+		h := &Handler{Secret: testSecret, Review: func(id string) { got = append(got, id) }}
+		rec := send(h, http.MethodPost, tt.event, tt.body, sign(tt.body))
+		if rec.Code != http.StatusAccepted {
+			t.Errorf("%s: expected 202, got %d (%s)", tt.event, rec.Code, rec.Body.String())
+		}
+		if len(got) != 1 || got[0] != tt.wantID {
+			t.Errorf("%s: expected review of PR %s, got %v", tt.event, tt.wantID, got)
+		}
+	}
+}
+
+func TestHandler_RejectsBadSignature(t *testing.T) {
+	called := false
+	h := &Handler{Secret: testSecret, Review: func(string) { called = true }}
+	for _, sig := range []string{"", "sha256=deadbeef", "sha1=" + strings.TrimPrefix(sign(cloudPayload), "sha256="), sign(cloudPayload + " ")} {
+		rec := send(h, http.MethodPost, "pullrequest:created", cloudPayload, sig)
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("signature %q: expected 401, got %d", sig, rec.Code)
+		}
+	}
+	if called {
+		t.Error("review must not run for unsigned requests")
+	}
+}
+
+func TestHandler_IgnoresOtherEvents(t *testing.T) {
+	called := false
+	h := &Handler{Secret: testSecret, Review: func(string) { called = true }}
+	body := `{"pullrequest": {"id": 42}}`
+	rec := send(h, http.MethodPost, "pullrequest:fulfilled", body, sign(body))
+	if rec.Code != http.StatusNoContent || called {
+		t.Errorf("expected merged-PR event to be ignored, got %d (review called: %v)", rec.Code, called)
+	}
+}
+
+func TestHandler_BadRequests(t *testing.T) {
+	h := &Handler{Secret: testSecret, Review: func(string) { t.Error("review must not run") }}
+	if rec := send(h, http.MethodGet, "pullrequest:created", "", ""); rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected 405 for GET, got %d", rec.Code)
+	}
+	body := `{"repository": {"full_name": "ws/repo"}}`
+	if rec := send(h, http.MethodPost, "pullrequest:created", body, sign(body)); rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for payload without a PR, got %d", rec.Code)
+	}
+}
+
+func TestValidSignature_EmptySecret(t *testing.T) {
+	mac := hmac.New(sha256.New, nil)
+	mac.Write([]byte("{}"))
+	if ValidSignature("", []byte("{}"), "sha256="+hex.EncodeToString(mac.Sum(nil))) {
+		t.Error("an empty secret must never validate")
+	}
+}
@@ -0,0 +1,28 @@
+package pullreview_test
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"pullreview/pkg/pullreview"
+)
+
+func ExampleReviewer_Review() {
+	cfg, err := pullreview.LoadConfig("pullreview.yaml")
+	if err != nil {
+		log.Fatal(err)
+	}
+	reviewer, err := pullreview.NewReviewer(cfg, "")
+	if err != nil {
+		log.Fatal(err)
+	}
+	res, err := reviewer.Review(context.Background(), "42")
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, c := range res.Inline {
+		fmt.Printf("%s: %s\n", c.Location(), c.Text)
+	}
+	fmt.Println(res.Summary)
+}
@@ -0,0 +1,211 @@
+// Package pullreview exposes pullreview's review flow as a library, so other tools can review
+// Bitbucket pull requests without shelling out to the CLI. A Reviewer fetches a PR's diff,
+// sends it to the configured LLM, and returns the parsed comments placed on the diff; posting
+// them back to Bitbucket is left to the caller (or the pullreview CLI). ReviewDiff reviews a
+// diff the caller already has, without contacting Bitbucket.
+package pullreview
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"pullreview/internal/bitbucket"
+	"pullreview/internal/config"
+	"pullreview/internal/llm"
+	"pullreview/internal/review"
+)
+
+// Config is the pullreview configuration, as read from pullreview.yaml.
+type Config = config.Config
+
+// Comment is a review finding: inline (anchored to a line) or file-level.
+type Comment = review.Comment
+
+// LoadConfig loads the configuration the way the CLI does: from path, or if path is empty from
+// the first of ./pullreview.yaml, $XDG_CONFIG_HOME/pullreview/config.yaml, and pullreview.yaml
+// next to the executable, with environment variable overrides applied.
+func LoadConfig(path string) (*Config, error) {
+	return config.LoadConfigWithOverrides(config.FindConfigFile(path), "", "", "")
+}
+
+// ReviewResult is the outcome of reviewing one PR.
+type ReviewResult struct {
+	PRID         string
+	Title        string
+	SourceCommit string    // Commit the diff was reviewed at
+	Inline       []Comment // Comments anchored to a line of the diff
+	FileLevel    []Comment // Comments on a whole file in the diff
+	Unmatched    []Comment // Comments that could not be placed on the diff (already part of Summary)
+	Summary      string    // The LLM summary followed by the unmatched comments as bullet points
+	Truncated    bool      // Bitbucket truncated the diff, so only part of the PR was reviewed
+	Skipped      bool      // The diff was over oversized_diff_bytes, so it was not reviewed (see oversized_diff)
+	Dropped      []string  // Files left out because the diff was over oversized_diff_bytes (oversized_diff: truncate)
+}
+
+// Reviewer reviews Bitbucket pull requests with an LLM. It is safe for concurrent use.
+type Reviewer struct {
+	cfg       *Config
+	prompt    string
+	bitbucket *bitbucket.Client
+	llm       *llm.Client
+}
+
+// NewReviewer creates a Reviewer from a loaded configuration. prompt is the review prompt
+// template; if empty, it is read from cfg.PromptFile. The template must contain the
+// (DIFF_CONTENT_HERE) or {FORMATTED_DIFF} placeholder.
+func NewReviewer(cfg *Config, prompt string) (*Reviewer, error) {
+	if cfg == nil {
+		return nil, errors.New("config is required")
+	}
+	if prompt == "" {
+		data, err := os.ReadFile(cfg.PromptFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read prompt file %q: %w", cfg.PromptFile, err)
+		}
+		prompt = string(data)
+	}
+	if err := review.ValidatePromptTemplate(prompt); err != nil {
+		return nil, err
+	}
+
+	bbClient := bitbucket.NewClient(cfg.Bitbucket.Email, cfg.Bitbucket.APIToken, cfg.Bitbucket.Workspace,
+		cfg.Bitbucket.RepoSlug, cfg.Bitbucket.BaseURL)
+	bbClient.Kind = cfg.Bitbucket.Kind
+	bbClient.AccessToken = cfg.Bitbucket.AccessToken
+	if err := bbClient.Validate(); err != nil {
+		return nil, err
+	}
+
+	llmClient := newLLMClient(cfg.LLM.Provider, cfg.LLM.APIKey, cfg.LLM.Endpoint, cfg.LLM.Model, cfg.LLM.Deployment, cfg.LLM.APIVersion)
+	llmClient.Timeout = cfg.LLM.Timeout
+	if cfg.LLM.CacheDir != "" {
+		llmClient.Cache = llm.NewCache(cfg.LLM.CacheDir, cfg.LLM.CacheTTL)
+	}
+	for _, p := range cfg.LLM.Providers {
+		fb := newLLMClient(p.Provider, p.APIKey, p.Endpoint, p.Model, p.Deployment, p.APIVersion)
+		fb.Timeout = llmClient.Timeout
+		fb.Cache = llmClient.Cache
+		llmClient.Fallbacks = append(llmClient.Fallbacks, fb)
+	}
+	return &Reviewer{cfg: cfg, prompt: prompt, bitbucket: bbClient, llm: llmClient}, nil
+}
+
+func newLLMClient(provider, apiKey, endpoint, model, deployment, apiVersion string) *llm.Client {
+	c := llm.NewClient(provider, apiKey, endpoint)
+	c.Model = model
+	c.Deployment = deployment
+	c.APIVersion = apiVersion
+	return c
+}
+
+// Review fetches the PR's diff, has the LLM review it, and places the comments on the diff,
+// applying the configured min_severity, line_tolerance, max_inline_comments, and
+// oversized_diff_bytes, and the .pullreviewignore at the PR's source commit. Nothing is posted
+// to Bitbucket.
+func (rv *Reviewer) Review(ctx context.Context, prID string) (*ReviewResult, error) {
+	pr, err := rv.bitbucket.GetPullRequest(ctx, prID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch PR metadata: %w", err)
+	}
+	diff, err := rv.bitbucket.GetPRDiff(ctx, prID)
+	truncated := errors.Is(err, bitbucket.ErrDiffTruncated)
+	if err != nil && !truncated {
+		return nil, fmt.Errorf("failed to fetch PR diff: %w", err)
+	}
+	if strings.TrimSpace(diff) == "" {
+		return nil, fmt.Errorf("PR #%s has an empty diff", prID)
+	}
+	ignore, err := rv.ignoreRules(ctx, pr.SourceCommit)
+	if err != nil {
+		return nil, err
+	}
+	res, err := rv.reviewDiff(ctx, prID, diff, review.PRInfo{Title: pr.Title, Description: pr.Description}, ignore)
+	if err != nil {
+		return nil, err
+	}
+	res.Title = pr.Title
+	res.SourceCommit = pr.SourceCommit
+	res.Truncated = truncated
+	return res, nil
+}
+
+// ReadDiff reads a unified diff from r, such as a file or stdin, for ReviewDiff. CRLF line
+// endings are normalized, and input without any file diffs is rejected.
+func ReadDiff(r io.Reader) (string, error) {
+	return review.ReadDiff(r)
+}
+
+// ReviewDiff reviews a unified diff supplied by the caller without contacting Bitbucket. The
+// result has no PR details, and no .pullreviewignore is applied.
+func (rv *Reviewer) ReviewDiff(ctx context.Context, diff string) (*ReviewResult, error) {
+	if strings.TrimSpace(diff) == "" {
+		return nil, errors.New("diff is empty")
+	}
+	return rv.reviewDiff(ctx, "", diff, review.PRInfo{}, nil)
+}
+
+// ignoreRules reads the .pullreviewignore at the root of the repository as of commit. A PR
+// without one, or without a known source commit, ignores nothing.
+func (rv *Reviewer) ignoreRules(ctx context.Context, commit string) (*review.IgnoreRules, error) {
+	if commit == "" {
+		return nil, nil
+	}
+	data, err := rv.bitbucket.GetFileContent(ctx, commit, review.IgnoreFileName)
+	if errors.Is(err, bitbucket.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", review.IgnoreFileName, err)
+	}
+	rules, err := review.ParseIgnore(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s at %s: %w", review.IgnoreFileName, commit, err)
+	}
+	return rules, nil
+}
+
+// reviewDiff has the LLM review diff with review.Run and places the comments on it; pr is
+// available to template prompts. Files matched by ignore are not reviewed.
+func (rv *Reviewer) reviewDiff(ctx context.Context, prID, diff string, pr review.PRInfo, ignore *review.IgnoreRules) (*ReviewResult, error) {
+	opts := review.Options{
+		Paths:              review.PathFilter{Ignore: ignore},
+		MinSeverity:        rv.cfg.MinSeverity,
+		LineTolerance:      rv.cfg.LineTolerance,
+		MaxInlineComments:  rv.cfg.MaxInlineComments,
+		MaxDiffBytes:       rv.cfg.LLM.MaxDiffBytes,
+		OversizedDiffBytes: rv.cfg.OversizedDiffBytes,
+		OversizedDiff:      rv.cfg.OversizedDiff,
+	}
+	r, err := review.Run(prID, diff, opts, func(chunk string) (string, error) {
+		instructions, prompt, err := review.RenderPromptParts(rv.prompt, chunk, pr)
+		if err != nil {
+			return "", err
+		}
+		resp, err := rv.llm.SendReviewWithSystem(ctx, instructions, prompt)
+		if err != nil {
+			return "", err
+		}
+		return resp.Content, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if r == nil {
+		return &ReviewResult{PRID: prID, Skipped: true}, nil
+	}
+
+	res := r.Result()
+	return &ReviewResult{
+		PRID:      prID,
+		Inline:    res.Inline,
+		FileLevel: res.FileLevel,
+		Unmatched: res.Unmatched,
+		Summary:   res.Summary,
+		Dropped:   r.Dropped,
+	}, nil
+}
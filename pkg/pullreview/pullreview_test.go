@@ -0,0 +1,227 @@
+package pullreview
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// routeRoundTripper answers requests from a fixed map of URL to response body.
+type routeRoundTripper struct {
+	routes map[string]string
+}
+
+func (m *routeRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, ok := m.routes[req.URL.String()]
+	code := http.StatusOK
+	if !ok {
+		code = http.StatusNotFound
+		body = `{"error": {"message": "not found"}}`
+	}
+	return &http.Response{
+		StatusCode: code,
+		Body:       io.NopCloser(bytes.NewBufferString(body)),
+		Header:     make(http.Header),
+	}, nil
+}
+
+func testConfig() *Config {
+	cfg := &Config{}
+	cfg.Bitbucket.Email = "user@example.com"
+	cfg.Bitbucket.APIToken = "token"
+	cfg.Bitbucket.Workspace = "ws"
+	cfg.Bitbucket.RepoSlug = "repo"
+	cfg.Bitbucket.BaseURL = "https://api.bitbucket.org/2.0"
+	cfg.LLM.Provider = "openai"
+	cfg.LLM.APIKey = "key"
+	cfg.LLM.Endpoint = "https://llm.example.com/v1/chat/completions"
+	return cfg
+}
+
+func TestReviewer_Review(t *testing.T) {
+	diff := "diff --git a/main.go b/main.go\n--- a/main.go\n+++ b/main.go\n@@ -1,2 +1,3 @@\n package main\n+var debug = true\n func main() {}\n"
+	llmOutput := "******************** SECTION: FILE-LEVEL COMMENTS ********************\n" +
+		"******************** SECTION: INLINE COMMENTS ********************\n" +
+		"FILE: main.go\nLINE: 2\nSEVERITY: high\nCOMMENT: Debug flag left enabled.\n\n" +
+		"FILE: other.go\nLINE: 9\nCOMMENT: Not part of this PR.\n\n" +
+		"******************** SECTION: SUMMARY ********************\nAdds a debug flag.\n" +
+		"******************** END ********************\n"
+	llmBody, _ := json.Marshal(map[string]interface{}{
+		"choices": []map[string]interface{}{{"message": map[string]string{"content": llmOutput}}},
+	})
+	prURL := "https://api.bitbucket.org/2.0/repositories/ws/repo/pullrequests/7"
+	origTransport := http.DefaultClient.Transport
+	http.DefaultClient.Transport = &routeRoundTripper{routes: map[string]string{
+		prURL:           `{"id": 7, "title": "Add debug flag", "source": {"commit": {"hash": "abc123"}}}`,
+		prURL + "/diff": diff,
+		"https://llm.example.com/v1/chat/completions": string(llmBody),
+	}}
+	defer func() { http.DefaultClient.Transport = origTransport }()
+
+	reviewer, err := NewReviewer(testConfig(), "Review this:\n(DIFF_CONTENT_HERE)")
+	if err != nil {
+		t.Fatalf("NewReviewer failed: %v", err)
+	}
+	res, err := reviewer.Review(context.Background(), "7")
+	if err != nil {
+		t.Fatalf("Review failed: %v", err)
+	}
+	if res.PRID != "7" || res.Title != "Add debug flag" || res.SourceCommit != "abc123" || res.Truncated {
+		t.Errorf("unexpected PR details: %+v", res)
+	}
+	if len(res.Inline) != 1 || res.Inline[0].FilePath != "main.go" || res.Inline[0].Line != 2 || res.Inline[0].Severity != "high" {
+		t.Errorf("expected one inline comment on main.go:2, got %+v", res.Inline)
+	}
+	if len(res.Unmatched) != 1 || !strings.Contains(res.Summary, "Adds a debug flag.") || !strings.Contains(res.Summary, "Not part of this PR.") {
+		t.Errorf("expected the unmatched comment in the summary, got %+v and %q", res.Unmatched, res.Summary)
+	}
+
+	if _, err := reviewer.Review(context.Background(), "8"); err == nil {
+		t.Error("expected an error for an unknown PR")
+	}
+}
+
+func TestNewReviewer_RejectsPromptWithoutPlaceholder(t *testing.T) {
+	if _, err := NewReviewer(testConfig(), "Review this PR."); err == nil {
+		t.Error("expected an error for a prompt without the diff placeholder")
+	}
+	if _, err := NewReviewer(nil, "(DIFF_CONTENT_HERE)"); err == nil {
+		t.Error("expected an error for a nil config")
+	}
+}
+
+func TestReviewer_ReviewDiff(t *testing.T) {
+	input := "diff --git a/foo.go b/foo.go\r\n--- a/foo.go\r\n+++ b/foo.go\r\n@@ -1,3 +1,4 @@\r\n package main\r\n \r\n-func hello() {}\r\n+func hello(name string) {}\r\n+var unused = 1\r\n"
+	llmOutput := "******************** SECTION: INLINE COMMENTS ********************\n" +
+		"FILE: foo.go\nLINE: 4\nCOMMENT: unused is never read.\n\n" +
+		"******************** SECTION: SUMMARY ********************\nChanges hello's signature.\n" +
+		"******************** END ********************\n"
+	llmBody, _ := json.Marshal(map[string]interface{}{
+		"choices": []map[string]interface{}{{"message": map[string]string{"content": llmOutput}}},
+	})
+	// Only the LLM is reachable: any Bitbucket request would get a 404
+	origTransport := http.DefaultClient.Transport
+	http.DefaultClient.Transport = &routeRoundTripper{routes: map[string]string{
+		"https://llm.example.com/v1/chat/completions": string(llmBody),
+	}}
+	defer func() { http.DefaultClient.Transport = origTransport }()
+
+	diff, err := ReadDiff(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ReadDiff failed: %v", err)
+	}
+	reviewer, err := NewReviewer(testConfig(), "Review this:\n(DIFF_CONTENT_HERE)")
+	if err != nil {
+		t.Fatalf("NewReviewer failed: %v", err)
+	}
+	res, err := reviewer.ReviewDiff(context.Background(), diff)
+	if err != nil {
+		t.Fatalf("ReviewDiff failed: %v", err)
+	}
+	if len(res.Inline) != 1 || res.Inline[0].FilePath != "foo.go" || res.Inline[0].Line != 4 {
+		t.Errorf("expected one inline comment on foo.go:4, got %+v", res.Inline)
+	}
+	if res.PRID != "" || !strings.Contains(res.Summary, "Changes hello's signature.") {
+		t.Errorf("unexpected result: %+v", res)
+	}
+
+	if _, err := reviewer.ReviewDiff(context.Background(), " \n"); err == nil {
+		t.Error("expected error for an empty diff, got nil")
+	}
+}
+
+func TestReviewer_ReviewOversizedDiff(t *testing.T) {
+	diff := "diff --git a/small.go b/small.go\n--- a/small.go\n+++ b/small.go\n@@ -1 +1,2 @@\n package main\n+var a = 1\n" +
+		"diff --git a/big.go b/big.go\n--- a/big.go\n+++ b/big.go\n@@ -1 +1,2 @@\n package main\n+var b = \"" + strings.Repeat("x", 200) + "\"\n"
+	llmOutput := "******************** SECTION: INLINE COMMENTS ********************\n" +
+		"FILE: small.go\nLINE: 2\nCOMMENT: a is never read.\n\n" +
+		"******************** SECTION: SUMMARY ********************\nAdds variables.\n" +
+		"******************** END ********************\n"
+	llmBody, _ := json.Marshal(map[string]interface{}{
+		"choices": []map[string]interface{}{{"message": map[string]string{"content": llmOutput}}},
+	})
+	origTransport := http.DefaultClient.Transport
+	http.DefaultClient.Transport = &routeRoundTripper{routes: map[string]string{
+		"https://llm.example.com/v1/chat/completions": string(llmBody),
+	}}
+	defer func() { http.DefaultClient.Transport = origTransport }()
+
+	cfg := testConfig()
+	cfg.OversizedDiffBytes = 150
+	reviewer, err := NewReviewer(cfg, "Review this:\n(DIFF_CONTENT_HERE)")
+	if err != nil {
+		t.Fatalf("NewReviewer failed: %v", err)
+	}
+	res, err := reviewer.ReviewDiff(context.Background(), diff)
+	if err != nil {
+		t.Fatalf("ReviewDiff failed: %v", err)
+	}
+	if !res.Skipped || len(res.Inline) != 0 {
+		t.Errorf("expected the oversized diff to be skipped, got %+v", res)
+	}
+
+	cfg.OversizedDiff = "truncate"
+	res, err = reviewer.ReviewDiff(context.Background(), diff)
+	if err != nil {
+		t.Fatalf("ReviewDiff failed: %v", err)
+	}
+	if res.Skipped || len(res.Dropped) != 1 || res.Dropped[0] != "big.go" {
+		t.Errorf("expected big.go to be left out, got %+v", res)
+	}
+	if len(res.Inline) != 1 || res.Inline[0].FilePath != "small.go" {
+		t.Errorf("expected one inline comment on small.go, got %+v", res.Inline)
+	}
+}
+
+func TestReviewer_ReviewAppliesPullReviewIgnore(t *testing.T) {
+	diff := "diff --git a/main.go b/main.go\n--- a/main.go\n+++ b/main.go\n@@ -1 +1,2 @@\n package main\n+var debug = true\n" +
+		"diff --git a/gen/api.go b/gen/api.go\n--- a/gen/api.go\n+++ b/gen/api.go\n@@ -1 +1,2 @@\n package gen\n+var generated = true\n"
+	llmOutput := "******************** SECTION: INLINE COMMENTS ********************\n" +
+		"FILE: main.go\nLINE: 2\nCOMMENT: Debug flag left enabled.\n\n" +
+		"FILE: gen/api.go\nLINE: 2\nCOMMENT: Generated code.\n\n" +
+		"******************** SECTION: SUMMARY ********************\nAdds flags.\n" +
+		"******************** END ********************\n"
+	var prompts []string
+	prURL := "https://api.bitbucket.org/2.0/repositories/ws/repo/pullrequests/7"
+	bb := &routeRoundTripper{routes: map[string]string{
+		prURL:           `{"id": 7, "title": "Add flags", "source": {"commit": {"hash": "abc123"}}}`,
+		prURL + "/diff": diff,
+		"https://api.bitbucket.org/2.0/repositories/ws/repo/src/abc123/.pullreviewignore": "gen/\n",
+	}}
+	origTransport := http.DefaultClient.Transport
+	http.DefaultClient.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if req.URL.Host != "llm.example.com" {
+			return bb.RoundTrip(req)
+		}
+		body, _ := io.ReadAll(req.Body)
+		prompts = append(prompts, string(body))
+		resp, _ := json.Marshal(map[string]interface{}{
+			"choices": []map[string]interface{}{{"message": map[string]string{"content": llmOutput}}},
+		})
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader(resp)), Header: make(http.Header)}, nil
+	})
+	defer func() { http.DefaultClient.Transport = origTransport }()
+
+	reviewer, err := NewReviewer(testConfig(), "Review this:\n(DIFF_CONTENT_HERE)")
+	if err != nil {
+		t.Fatalf("NewReviewer failed: %v", err)
+	}
+	res, err := reviewer.Review(context.Background(), "7")
+	if err != nil {
+		t.Fatalf("Review failed: %v", err)
+	}
+	if len(prompts) != 1 || strings.Contains(prompts[0], "gen/api.go") {
+		t.Errorf("expected the ignored file to be left out of the prompt, got %q", prompts)
+	}
+	if len(res.Inline) != 1 || res.Inline[0].FilePath != "main.go" || strings.Contains(res.Summary, "Generated code.") {
+		t.Errorf("expected only the comment on main.go, got %+v and %q", res.Inline, res.Summary)
+	}
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }